@@ -2,9 +2,14 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	gotoken "go/token"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/semetekare/rust2go/internal/ast"
 	"github.com/semetekare/rust2go/internal/backend"
@@ -12,81 +17,352 @@ import (
 	"github.com/semetekare/rust2go/internal/lexer"
 	"github.com/semetekare/rust2go/internal/parser"
 	"github.com/semetekare/rust2go/internal/sema"
+	"github.com/semetekare/rust2go/internal/token"
 )
 
+// Version — версия rust2go; задаётся при сборке:
+// go build -ldflags "-X main.Version=v1.2.3" ./cmd
+var Version = "dev"
+
 // main — точка входа для полного pipeline компиляции.
-// CLI: go run ./cmd/main.go example/example.rs
+// CLI: go run ./cmd/main.go [--panic-locations] [--camel-case] [--generated-header] [--package name] [-o out.go] [--emit=stage] example/example.rs [more.rs ...]
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: rust2go <file.rs>")
-		os.Exit(1)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run разбирает аргументы командной строки, выполняет pipeline и возвращает код завершения.
+// Исходный код читается из файлов (все они компилируются в один пакет) или из stdin,
+// если файл равен `-` или не указан. Сгенерированный код записывается в файл -o
+// (по умолчанию output/<имя первого файла>.go, а для stdin — stdout)
+// или в stdout при `-o -`; в последнем случае сообщения о ходе компиляции выводятся в stderr.
+// С флагом --emit в stdout выводится только результат указанного этапа.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("rust2go", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	panicLocations := flags.Bool("panic-locations", false, "include source locations in unwrap/expect panic messages")
+	camelCase := flags.Bool("camel-case", true, "convert snake_case function, method and field names to camelCase (foo_bar -> FooBar); --camel-case=false keeps underscores (Foo_bar)")
+	generatedHeader := flags.Bool("generated-header", false, "prepend the \"Code generated ... DO NOT EDIT.\" comment")
+	output := flags.String("o", "", "output file (\"-\" for stdout, default output/<name>.go)")
+	version := flags.Bool("version", false, "print the version and exit")
+	bin := flags.Bool("bin", false, "require fn main: the crate is compiled into an executable")
+	pkg := flags.String("package", "", "name of the generated Go package (default main, or lib for a crate without fn main)")
+	emit := flags.String("emit", "", "print only the given stage and exit: tokens, ast, json, ir or go")
+	if err := flags.Parse(args); err != nil {
+		return 2
 	}
-	inputFile := os.Args[1]
-	b, err := os.ReadFile(inputFile)
-	if err != nil {
-		fmt.Printf("read error: %v\n", err)
-		os.Exit(1)
+	if *version {
+		fmt.Fprintf(stdout, "rust2go %s\n", Version)
+		return 0
+	}
+	switch *emit {
+	case "", "tokens", "ast", "json", "ir", "go":
+	default:
+		fmt.Fprintf(stderr, "unknown --emit stage %q (want tokens, ast, json, ir or go)\n", *emit)
+		return 2
+	}
+	if *pkg != "" && !gotoken.IsIdentifier(*pkg) {
+		fmt.Fprintf(stderr, "invalid --package name %q\n", *pkg)
+		return 2
+	}
+
+	inputFiles := flags.Args()
+	if len(inputFiles) == 0 {
+		if isTerminal(stdin) {
+			fmt.Fprintln(stdout, "Usage: rust2go [--panic-locations] [--camel-case] [--generated-header] [--bin] [--package <name>] [-o <file>] [--emit=<stage>] <file.rs | -> ...")
+			return 1
+		}
+		inputFiles = []string{"-"}
+	}
+	outputFile := *output
+	switch {
+	case outputFile != "":
+	case inputFiles[0] == "-":
+		outputFile = "-"
+	default:
+		outputFile = defaultOutputFile(inputFiles[0])
 	}
-	lx := lexer.NewLexer()
-	toks, err := lx.Lex(string(b))
+	// log — ход компиляции, errOut — ошибки и предупреждения
+	log, errOut := stdout, stdout
+	switch {
+	case *emit != "":
+		log, errOut = io.Discard, stderr
+	case outputFile == "-":
+		log, errOut = stderr, stderr
+	}
+
+	srcs := []source{}
+	for _, inputFile := range inputFiles {
+		src, err := readSource(inputFile, stdin)
+		if err != nil {
+			fmt.Fprintf(errOut, "read error: %v\n", err)
+			return 1
+		}
+		srcs = append(srcs, src)
+	}
+
+	gen := backend.NewGenerator()
+	gen.PanicLocations = *panicLocations
+	gen.SourceFile = srcs[0].name
+	gen.CamelCase = *camelCase
+	gen.GeneratedHeader = *generatedHeader
+	stage := *emit
+	switch stage {
+	case "":
+		stage = "go"
+	case "json":
+		stage = "ast"
+	}
+	checker := sema.NewChecker()
+	checker.Binary = *bin
+	res, err := compile(srcs, stage, *pkg, checker, gen)
 	if err != nil {
-		fmt.Printf("lex error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(errOut, "lex error: %v\n", err)
+		return 1
 	}
-	p := parser.NewParser(toks)
-	fileAST, errs := p.ParseFile()
-	if len(errs) > 0 {
-		for _, e := range errs {
-			fmt.Println(e)
+	if *emit == "tokens" {
+		fmt.Fprint(stdout, formatTokens(res.tokens))
+		return 0
+	}
+
+	if len(res.parseErrs) > 0 {
+		fmt.Fprintf(errOut, "✗ Found %d parse error(s):\n", len(res.parseErrs))
+		for _, e := range res.parseErrs {
+			msg := e.String()
+			if len(srcs) > 1 {
+				msg = e.src.name + ": " + msg
+			}
+			fmt.Fprintln(errOut, formatDiagnostic(e.src.text, e.Pos, msg))
 		}
-	} else {
-		fmt.Println("✓ Parsing succeeded")
-		fmt.Println("AST:", ast.PrettyPrint(fileAST))
-
-		// Семантический анализ
-		fmt.Println("\n=== Semantic Analysis ===")
-		checker := sema.NewChecker()
-		semErrs := checker.Check(fileAST)
-		if len(semErrs) > 0 {
-			fmt.Printf("✗ Found %d semantic error(s):\n", len(semErrs))
-			for _, e := range semErrs {
-				fmt.Println("  ", e)
+		return 1
+	}
+	switch *emit {
+	case "ast":
+		fmt.Fprint(stdout, ast.PrettyPrint(res.crate))
+		return 0
+	case "json":
+		data, err := ast.MarshalJSON(res.crate)
+		if err != nil {
+			fmt.Fprintf(errOut, "json error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(data))
+		return 0
+	}
+	fmt.Fprintln(log, "✓ Parsing succeeded")
+	fmt.Fprintln(log, "AST:", ast.PrettyPrint(res.crate))
+
+	// Семантический анализ
+	fmt.Fprintln(log, "\n=== Semantic Analysis ===")
+	if len(res.semErrs) > 0 {
+		fmt.Fprintf(errOut, "✗ Found %d semantic error(s):\n", len(res.semErrs))
+		for _, e := range res.semErrs {
+			msg := e.Error()
+			if len(srcs) > 1 {
+				msg = e.src.name + ": " + msg
 			}
-			os.Exit(1)
+			fmt.Fprintln(errOut, formatDiagnostic(e.src.text, e.Pos, msg))
+		}
+		return 1
+	}
+	fmt.Fprintln(log, "✓ Semantic analysis passed")
+	if *emit == "ir" {
+		fmt.Fprint(stdout, ir.Dump(res.module))
+		return 0
+	}
+
+	// Трансформация в IR и генерация кода
+	fmt.Fprintln(log, "\n=== Code Generation ===")
+	// Неформатируемый код всё равно выводится, чтобы его можно было изучить,
+	// но трансляция считается неудачной
+	status := 0
+	if res.genErr != nil {
+		fmt.Fprintf(errOut, "✗ generated code is not valid Go: %v\n", res.genErr)
+		status = 1
+	}
+
+	if *emit == "go" || outputFile == "-" {
+		fmt.Fprint(stdout, res.goCode)
+		return status
+	}
+
+	fmt.Fprintln(log, "Generated Go code:")
+	fmt.Fprintln(log, "---")
+	fmt.Fprintln(log, res.goCode)
+	fmt.Fprintln(log, "---")
+
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		fmt.Fprintf(errOut, "Warning: could not create output directory: %v\n", err)
+	}
+	if err := os.WriteFile(outputFile, []byte(res.goCode), 0644); err != nil {
+		fmt.Fprintf(errOut, "Warning: could not write %s: %v\n", outputFile, err)
+	} else {
+		fmt.Fprintf(log, "\n✓ Code written to %s\n", outputFile)
+	}
+	return status
+}
+
+// defaultOutputFile возвращает путь выходного файла по умолчанию:
+// output/<имя входного файла без расширения>.go.
+func defaultOutputFile(inputFile string) string {
+	baseName := filepath.Base(inputFile)
+	ext := filepath.Ext(baseName)
+	return filepath.Join("output", baseName[:len(baseName)-len(ext)]+".go")
+}
+
+// source — исходный файл: имя для сообщений и текст программы.
+type source struct {
+	name string
+	text string
+}
+
+// readSource читает исходный файл path; путь `-` означает stdin.
+func readSource(path string, stdin io.Reader) (source, error) {
+	if path == "-" {
+		b, err := io.ReadAll(stdin)
+		return source{name: "<stdin>", text: string(b)}, err
+	}
+	b, err := os.ReadFile(path)
+	return source{name: path, text: string(b)}, err
+}
+
+// isTerminal сообщает, подключён ли r к терминалу, то есть данных в нём ждать не стоит.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// pipeline содержит результаты этапов компиляции. Поля этапов,
+// до которых pipeline не дошёл, остаются пустыми.
+type pipeline struct {
+	tokens    []token.Token
+	crate     *ast.Crate
+	parseErrs []parseError
+	semErrs   []semanticError
+	module    *ir.Module
+	goCode    string
+	genErr    error // Ошибка форматирования; goCode при этом содержит неформатированный код
+}
+
+// parseError — ошибка синтаксического анализа вместе с файлом, в котором она найдена.
+type parseError struct {
+	parser.ParseError
+	src source
+}
+
+// semanticError — семантическая ошибка вместе с файлом, в котором она найдена.
+type semanticError struct {
+	sema.SemanticError
+	src source
+}
+
+// compile выполняет pipeline над исходными файлами srcs вплоть до этапа stage
+// ("tokens", "ast", "ir" или "go") и останавливается на первом этапе с ошибками.
+// pkg — имя пакета Go генерируемого модуля; пустое имя выбирается по наличию функции main.
+// Файлы разбираются по отдельности, а их элементы объединяются в один crate,
+// поэтому повторные объявления в разных файлах находит семантический анализ.
+// Ошибка возвращается, только если исходный текст не удалось разбить на токены.
+func compile(srcs []source, stage, pkg string, checker *sema.Checker, gen *backend.Generator) (*pipeline, error) {
+	res := &pipeline{}
+	files := [][]token.Token{}
+	for _, src := range srcs {
+		toks, err := lexer.NewLexer().Lex(src.text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src.name, err)
 		}
-		fmt.Println("✓ Semantic analysis passed")
-
-		// Трансформация в IR
-		fmt.Println("\n=== IR Transformation ===")
-		transformer := ir.NewTransformer()
-		irModule := transformer.Transform(fileAST)
-		fmt.Printf("✓ Transformed to IR: %d functions, %d structs\n",
-			len(irModule.Functions), len(irModule.Structs))
-
-		// Генерация кода
-		fmt.Println("\n=== Code Generation ===")
-		gen := backend.NewGenerator()
-		goCode := gen.Generate(irModule)
-
-		fmt.Println("Generated Go code:")
-		fmt.Println("---")
-		fmt.Println(goCode)
-		fmt.Println("---")
-
-		// Сохраняем сгенерированный код в output/
-		outputDir := "output"
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			fmt.Printf("Warning: could not create output directory: %v\n", err)
+		res.tokens = append(res.tokens, toks...)
+		files = append(files, toks)
+	}
+	if stage == "tokens" {
+		return res, nil
+	}
+
+	items := []ast.Item{}
+	itemSrcs := map[ast.Item]source{}
+	for i, toks := range files {
+		crate, errs := parser.NewParser(toks).ParseFile()
+		if res.crate == nil {
+			res.crate = crate
 		}
+		for _, e := range errs {
+			res.parseErrs = append(res.parseErrs, parseError{ParseError: e, src: srcs[i]})
+		}
+		for _, item := range crate.Items {
+			itemSrcs[item] = srcs[i]
+		}
+		items = append(items, crate.Items...)
+	}
+	res.crate = ast.NewCrate(res.crate.Pos(), items)
+	if stage == "ast" || len(res.parseErrs) > 0 {
+		return res, nil
+	}
+
+	// Ошибки уровня крейта относятся к первому файлу: с него начинается crate
+	for _, e := range checker.Check(res.crate) {
+		src, ok := itemSrcs[e.Item]
+		if !ok {
+			src = srcs[0]
+		}
+		res.semErrs = append(res.semErrs, semanticError{SemanticError: e, src: src})
+	}
+	if len(res.semErrs) > 0 {
+		return res, nil
+	}
 
-		// Имя выходного файла на основе входного
-		baseName := filepath.Base(inputFile)
-		ext := filepath.Ext(baseName)
-		outputFile := filepath.Join(outputDir, baseName[:len(baseName)-len(ext)]+".go")
-		if err := os.WriteFile(outputFile, []byte(goCode), 0644); err != nil {
-			fmt.Printf("Warning: could not write %s: %v\n", outputFile, err)
+	transformer := ir.NewTransformer()
+	if pkg != "" {
+		transformer.SetPackageName(pkg)
+	}
+	res.module = transformer.Transform(res.crate)
+	ir.Fold(res.module)
+	if stage == "ir" {
+		return res, nil
+	}
+
+	res.goCode, res.genErr = gen.GenerateFormatted(res.module)
+	return res, nil
+}
+
+// formatDiagnostic дополняет сообщение об ошибке строкой исходного кода src,
+// на которую указывает pos, и знаком ^ под колонкой ошибки:
+//
+//	Semantic error at 2:13: undefined variable: y
+//	  2 |     let x = y + 1;
+//	    |             ^
+//
+// Если строки pos в src нет, возвращается только сообщение.
+func formatDiagnostic(src string, pos token.Position, msg string) string {
+	lines := strings.Split(src, "\n")
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return msg
+	}
+	line := strings.TrimRight(lines[pos.Line-1], "\r")
+
+	// Колонки считаются в рунах; табуляции сохраняются, чтобы ^ совпал с колонкой
+	var pad strings.Builder
+	for i, r := range []rune(line) {
+		if i >= pos.Col-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
 		} else {
-			fmt.Printf("\n✓ Code written to %s\n", outputFile)
+			pad.WriteRune(' ')
 		}
 	}
+	num := strconv.Itoa(pos.Line)
+	gutter := strings.Repeat(" ", len(num))
+	return fmt.Sprintf("%s\n  %s | %s\n  %s | %s^", msg, num, line, gutter, pad.String())
+}
+
+// formatTokens выводит токены по одному в строке: позиция, тип и исходный текст.
+func formatTokens(toks []token.Token) string {
+	var sb strings.Builder
+	for _, tok := range toks {
+		fmt.Fprintf(&sb, "%d:%d\t%s\t%s\n", tok.Line, tok.Col, tok, tok.Literal)
+	}
+	return sb.String()
 }
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/semetekare/rust2go/internal/backend"
+	"github.com/semetekare/rust2go/internal/sema"
+	"github.com/semetekare/rust2go/internal/token"
+)
+
+// update перезаписывает эталонный вывод вместо сравнения с ним:
+// go test ./cmd -run TestExampleGolden -update
+var update = flag.Bool("update", false, "update golden files")
+
+// TestExampleGolden прогоняет example/example.rs через весь pipeline
+// и сравнивает результат с закоммиченным output/example.go.
+func TestExampleGolden(t *testing.T) {
+	const (
+		inputFile  = "../example/example.rs"
+		goldenFile = "../output/example.go"
+	)
+
+	src, err := os.ReadFile(inputFile)
+	if err != nil {
+		t.Fatalf("read %s: %v", inputFile, err)
+	}
+	res, err := compile([]source{{name: inputFile, text: string(src)}}, "go", "main", sema.NewChecker(), backend.NewGenerator())
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+	if len(res.parseErrs) > 0 {
+		t.Fatalf("Parse errors: %v", res.parseErrs)
+	}
+	if len(res.semErrs) > 0 {
+		t.Fatalf("Semantic errors: %v", res.semErrs)
+	}
+	if res.genErr != nil {
+		t.Fatalf("generate: %v", res.genErr)
+	}
+	got := res.goCode
+	if *update {
+		if err := os.WriteFile(goldenFile, []byte(got), 0644); err != nil {
+			t.Fatalf("write %s: %v", goldenFile, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatalf("read %s: %v", goldenFile, err)
+	}
+	if got != string(want) {
+		t.Errorf("Generated code differs from %s (run with -update to refresh):\n--- got ---\n%s\n--- want ---\n%s", goldenFile, got, want)
+	}
+}
+
+// TestExampleGoldenVet проверяет эталонный вывод go vet: эталон не должен
+// закреплять код, который компилируется, но неверен (например, аргументы
+// fmt.Sprintf без глаголов формата).
+func TestExampleGoldenVet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("go vet is slow in short mode")
+	}
+	// Эталон читается тестом, а не только go vet, чтобы кэш go test учитывал его изменения
+	code, err := os.ReadFile("../output/example.go")
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "example.go")
+	if err := os.WriteFile(file, code, 0644); err != nil {
+		t.Fatalf("write %s: %v", file, err)
+	}
+	out, err := exec.Command("go", "vet", file).CombinedOutput()
+	if err != nil {
+		t.Errorf("go vet ../output/example.go: %v\n%s", err, out)
+	}
+}
+
+func TestRunOutputFlag(t *testing.T) {
+	want, err := os.ReadFile("../output/example.go")
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+
+	outFile := filepath.Join(t.TempDir(), "gen", "example.go")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-o", outFile, "../example/example.rs"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	got, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("Expected output at %s: %v", outFile, err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Output file differs from golden:\n%s", got)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"-o", "-", "../example/example.rs"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if stdout.String() != string(want) {
+		t.Errorf("Expected only generated code on stdout, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunEmitTokens(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "main.rs")
+	if err := os.WriteFile(src, []byte("fn main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--emit=tokens", src}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	want := "1:1\tKEYWORD\tfn\n" +
+		"1:4\tIDENT\tmain\n"
+	if !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("Expected tokens to start with %q, got:\n%s", want, stdout.String())
+	}
+	if strings.Contains(stdout.String(), "Parsing succeeded") {
+		t.Errorf("Expected only tokens on stdout, got:\n%s", stdout.String())
+	}
+
+	if code := run([]string{"--emit=bogus", src}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for unknown stage, got %d", code)
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	src := "fn add(a: i32, b: i32) -> i32 {\n    a + b\n}\n"
+	for _, args := range [][]string{{"-"}, {}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, strings.NewReader(src), &stdout, &stderr); code != 0 {
+			t.Fatalf("run(%v): expected exit code 0, got %d: %s", args, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "func add(a int, b int) int {\n\treturn a + b\n}") {
+			t.Errorf("run(%v): expected generated Go on stdout, got:\n%s", args, stdout.String())
+		}
+	}
+}
+
+func TestRunMultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"math.rs": "fn add(a: i32, b: i32) -> i32 {\n    a + b\n}\n",
+		"main.rs": "fn main() {\n    let x = add(1, 2);\n}\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-o", "-", filepath.Join(dir, "main.rs"), filepath.Join(dir, "math.rs")}
+	if code := run(args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	for _, want := range []string{"func main() {\n\tx := add(1, 2)", "func add(a int, b int) int {"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	// Повторное объявление в другом файле находит семантический анализ
+	dup := filepath.Join(dir, "dup.rs")
+	if err := os.WriteFile(dup, []byte(files["math.rs"]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	stderr.Reset()
+	args = append(args, dup)
+	if code := run(args, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 for duplicate function, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "duplicate function declaration: add") {
+		t.Errorf("Expected duplicate declaration error, got:\n%s", stderr.String())
+	}
+}
+
+func TestRunEmitJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--emit=json"}, strings.NewReader("fn main(){}"), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	var crate map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &crate); err != nil {
+		t.Fatalf("Expected valid JSON: %v\n%s", err, stdout.String())
+	}
+	for _, want := range []string{`"kind": "Crate"`, `"kind": "Function"`, `"name": "main"`} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected JSON to contain %s, got:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestFormatDiagnostic(t *testing.T) {
+	src := "fn main() {\n    let x = y + 1;\n}\n"
+	got := formatDiagnostic(src, token.Position{Line: 2, Col: 13}, "undefined variable: y")
+	want := "undefined variable: y\n" +
+		"  2 |     let x = y + 1;\n" +
+		"    |             ^"
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	if got := formatDiagnostic(src, token.Position{Line: 10, Col: 1}, "msg"); got != "msg" {
+		t.Errorf("Expected bare message for missing line, got %q", got)
+	}
+}
+
+func TestRunReportsErrorSnippet(t *testing.T) {
+	src := "fn main() {\n    let x: i32 = y;\n}\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--emit=go"}, strings.NewReader(src), &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	want := "  2 |     let x: i32 = y;\n    |                  ^"
+	if !strings.Contains(stderr.String(), want) {
+		t.Errorf("Expected snippet %q, got:\n%s", want, stderr.String())
+	}
+}
+
+func TestRunReportsErrorSnippetPerFile(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "main.rs")
+	mathPath := filepath.Join(dir, "math.rs")
+	files := map[string]string{
+		mainPath: "fn main() {\n    let x = add(1, 2);\n}\n",
+		mathPath: "fn add(a: i32, b: i32) -> i32 {\n    a + c\n}\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(name, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--emit=go", mainPath, mathPath}, nil, &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	for _, want := range []string{mathPath + ": Semantic error at 2:9", "  2 |     a + c\n    |         ^"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("Expected %q, got:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestRunRejectsEnumVariantsWithData(t *testing.T) {
+	src := "enum Shape {\n    Circle(f64),\n    Empty,\n}\n\nfn main() {\n    let s = Shape::Circle(1.0);\n}\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--emit=go"}, strings.NewReader(src), &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	if want := "enum variants with data are not supported: Shape::Circle"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("Expected %q, got:\n%s", want, stderr.String())
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("Expected no generated code, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunPackageFlag(t *testing.T) {
+	src := "pub fn add(a: i32, b: i32) -> i32 {\n    a + b\n}\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--package", "mylib", "-"}, strings.NewReader(src), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "package mylib\n") {
+		t.Errorf("Expected package mylib, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"--package", "lib", "-"}, strings.NewReader(src), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "package lib\n") || strings.Contains(stdout.String(), "func main") {
+		t.Errorf("Expected package lib without a main function, got:\n%s", stdout.String())
+	}
+
+	// Без --package библиотечный crate получает пакет lib, а пакет main — заглушку main
+	stdout.Reset()
+	if code := run([]string{"-"}, strings.NewReader(src), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "package lib\n") {
+		t.Errorf("Expected package lib by default for a library crate, got:\n%s", stdout.String())
+	}
+	stdout.Reset()
+	if code := run([]string{"--package", "main", "-"}, strings.NewReader(src), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "func main() {\n}\n") {
+		t.Errorf("Expected a main stub in package main, got:\n%s", stdout.String())
+	}
+
+	if code := run([]string{"--package", "my-lib", "-"}, strings.NewReader(src), &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for invalid package name, got %d", code)
+	}
+}
+
+func TestRunBinFlag(t *testing.T) {
+	src := "pub fn add(a: i32, b: i32) -> i32 {\n    a + b\n}\n"
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--bin", "--emit=go"}, strings.NewReader(src), &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1 for a crate without main, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "`main` function not found in crate") {
+		t.Errorf("Expected missing main error, got:\n%s", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"--bin", "--emit=go"}, strings.NewReader("fn main() {}\n"), &stdout, &stderr); code != 0 {
+		t.Errorf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestRunFailsOnLexAndParseErrors(t *testing.T) {
+	const bad = "fn main() {\n    let x = 1 +;\n}\n"
+	res, err := compile([]source{{name: "bad.rs", text: bad}}, "go", "main", sema.NewChecker(), backend.NewGenerator())
+	if err != nil {
+		t.Fatalf("Unexpected lex error: %v", err)
+	}
+	if len(res.parseErrs) == 0 {
+		t.Fatal("Expected parse errors")
+	}
+	if res.goCode != "" {
+		t.Errorf("Expected no code generation after parse errors, got:\n%s", res.goCode)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-"}, strings.NewReader(bad), &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 on parse errors, got %d", code)
+	}
+	if want := fmt.Sprintf("Found %d parse error(s)", len(res.parseErrs)); !strings.Contains(stderr.String(), want) {
+		t.Errorf("Expected %q in output, got:\n%s", want, stderr.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-"}, strings.NewReader(`fn main() { let s = "unterminated; }`), &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 on lex error, got %d", code)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no generated code, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	defer func(v string) { Version = v }(Version)
+	Version = "v1.2.3"
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--version"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "rust2go v1.2.3\n" {
+		t.Errorf("Expected version output, got %q", got)
+	}
+}
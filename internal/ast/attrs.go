@@ -0,0 +1,23 @@
+package ast
+
+import "strings"
+
+// Derives возвращает имена трейтов из атрибутов `#[derive(...)]` в порядке объявления:
+// для `#[derive(Debug, Clone)]` это ["Debug", "Clone"]. Остальные атрибуты пропускаются.
+func Derives(attrs []string) []string {
+	var derives []string
+	for _, attr := range attrs {
+		body := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(attr, "#["), "]"))
+		args, ok := strings.CutPrefix(body, "derive")
+		args = strings.TrimSpace(args)
+		if !ok || !strings.HasPrefix(args, "(") || !strings.HasSuffix(args, ")") {
+			continue
+		}
+		for _, name := range strings.Split(args[1:len(args)-1], ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				derives = append(derives, name)
+			}
+		}
+	}
+	return derives
+}
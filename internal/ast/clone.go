@@ -0,0 +1,63 @@
+package ast
+
+import "reflect"
+
+// Clone возвращает глубокую копию поддерева с корнем n: копируются все узлы,
+// списки (параметры, поля, операторы, аргументы), карты (ограничения обобщённых
+// параметров) и дочерние узлы, хранящиеся в полях интерфейсных типов. Изменение копии не затрагивает исходное дерево.
+func Clone(n Node) Node {
+	if n == nil {
+		return nil
+	}
+	return cloneValue(reflect.ValueOf(n)).Interface().(Node)
+}
+
+// cloneValue рекурсивно копирует значение из дерева AST.
+// Неэкспортируемые поля (позиции) копируются вместе со структурой.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				c.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,77 @@
+package ast
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// MarshalJSON сериализует дерево с корнем n в JSON для внешних инструментов.
+// Каждый узел записывается объектом с именем типа узла ("kind"), позицией ("pos")
+// и всеми экспортируемыми полями; дочерние узлы и списки сериализуются рекурсивно,
+// отсутствующие (nil) узлы — как null.
+func MarshalJSON(n Node) ([]byte, error) {
+	return json.MarshalIndent(toJSON(reflect.ValueOf(n)), "", "  ")
+}
+
+// toJSON преобразует значение из дерева AST в структуру из map, срезов и скаляров.
+func toJSON(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if n, ok := v.Interface().(Node); ok && v.Kind() == reflect.Pointer {
+			return nodeJSON(n, v.Elem())
+		}
+		return toJSON(v.Elem())
+	case reflect.Struct:
+		// Узлы, хранящиеся по значению (Param, Field, MatchArm), реализуют Node через указатель
+		if v.CanAddr() {
+			if n, ok := v.Addr().Interface().(Node); ok {
+				return nodeJSON(n, v)
+			}
+		}
+		return fieldsJSON(v, map[string]any{})
+	case reflect.Slice:
+		elems := make([]any, v.Len())
+		for i := range elems {
+			elems[i] = toJSON(v.Index(i))
+		}
+		return elems
+	default:
+		return v.Interface()
+	}
+}
+
+// nodeJSON сериализует узел n, значение структуры которого — v.
+func nodeJSON(n Node, v reflect.Value) map[string]any {
+	pos := n.Pos()
+	return fieldsJSON(v, map[string]any{
+		"kind": v.Type().Name(),
+		"pos":  map[string]int{"line": pos.Line, "col": pos.Col},
+	})
+}
+
+// renamedFields задаёт JSON-имена полей, совпадающие со служебными ключами узла:
+// Kind литералов ("INT", "STRING", ...) записывается как literalKind.
+var renamedFields = map[string]string{
+	"Kind": "literalKind",
+}
+
+// fieldsJSON добавляет в out экспортируемые поля структуры v
+// под именами со строчной первой буквой.
+func fieldsJSON(v reflect.Value, out map[string]any) map[string]any {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := renamedFields[field.Name]
+		if !ok {
+			name = strings.ToLower(field.Name[:1]) + field.Name[1:]
+		}
+		out[name] = toJSON(v.Field(i))
+	}
+	return out
+}
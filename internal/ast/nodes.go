@@ -6,6 +6,7 @@ package ast
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/semetekare/rust2go/internal/token"
 )
@@ -18,6 +19,9 @@ type Position = token.Position
 type Node interface {
 	// Pos возвращает позицию узла в исходном коде.
 	Pos() Position
+	// SetPos задаёт позицию узла; используется при построении синтетических узлов
+	// (например, при рассахаривании), чтобы диагностика указывала на исходный код.
+	SetPos(Position)
 	// String возвращает человекочитаемое строковое представление узла (в основном для отладки).
 	String() string
 }
@@ -32,6 +36,9 @@ type Crate struct {
 // Pos возвращает позицию начала crate.
 func (c *Crate) Pos() Position { return c.pos }
 
+// SetPos задаёт позицию начала crate.
+func (c *Crate) SetPos(pos Position) { c.pos = pos }
+
 // String возвращает строковое представление crate.
 func (c *Crate) String() string { return fmt.Sprintf("Crate{Items: %d}", len(c.Items)) }
 
@@ -53,14 +60,24 @@ type Item interface {
 type Function struct {
 	pos        Position // Позиция ключевого слова "fn".
 	Name       string   // Имя функции.
+	TypeParams []string // Имена обобщённых параметров типа (lifetime'ы не сохраняются).
+	Receiver   *Param   // Параметр self для методов (nil для свободных функций).
 	Params     []Param  // Список параметров.
 	ReturnType Type     // Возвращаемый тип (может быть nil для unit).
 	Body       *Block   // Тело функции.
+	Public     bool     // Функция объявлена с модификатором pub.
+
+	// Bounds — ограничения-трейты параметров типа из `<T: A + B>` и из where: T -> [A, B].
+	// Трейты записываются строками в синтаксисе Rust (`Into<String>`); nil, если ограничений нет.
+	Bounds map[string][]string
 }
 
 // Pos возвращает позицию начала функции.
 func (f *Function) Pos() Position { return f.pos }
 
+// SetPos задаёт позицию начала функции.
+func (f *Function) SetPos(pos Position) { f.pos = pos }
+
 // String возвращает строковое представление функции.
 func (f *Function) String() string { return fmt.Sprintf("Function{Name: %s}", f.Name) }
 
@@ -72,17 +89,36 @@ func NewFunction(pos Position, name string, params []Param, returnType Type, bod
 	return &Function{pos: pos, Name: name, Params: params, ReturnType: returnType, Body: body}
 }
 
+// StructKind — форма определения структуры.
+type StructKind int
+
+const (
+	StructNamed StructKind = iota // `struct P { x: i32 }` — поля с именами
+	StructTuple                   // `struct Meters(f64);` — поля без имён, доступ `m.0`
+	StructUnit                    // `struct Marker;` — без полей
+)
+
 // Struct представляет определение структуры.
-// Соответствует грамматике: Struct ::= "struct" IDENTIFIER "{" Field* "}"
+// Соответствует грамматике:
+//
+//	Struct ::= "struct" IDENTIFIER ( "{" Field* "}" | "(" TupleField* ")" ";" | ";" )
+//
+// Поля кортежной структуры получают имена по номеру позиции: "0", "1", ...
 type Struct struct {
-	pos    Position // Позиция ключевого слова "struct".
-	Name   string   // Имя структуры.
-	Fields []Field  // Список полей структуры.
+	pos    Position   // Позиция ключевого слова "struct".
+	Name   string     // Имя структуры.
+	Kind   StructKind // Форма определения: с именованными полями, кортежная или единичная.
+	Fields []Field    // Список полей структуры.
+	Public bool       // Структура объявлена с модификатором pub.
+	Attrs  []string   // Внешние атрибуты в записи исходного кода, например "#[derive(Debug)]".
 }
 
 // Pos возвращает позицию начала структуры.
 func (s *Struct) Pos() Position { return s.pos }
 
+// SetPos задаёт позицию начала структуры.
+func (s *Struct) SetPos(pos Position) { s.pos = pos }
+
 // String возвращает строковое представление структуры.
 func (s *Struct) String() string { return fmt.Sprintf("Struct{Name: %s}", s.Name) }
 
@@ -94,17 +130,98 @@ func NewStruct(pos Position, name string, fields []Field) *Struct {
 	return &Struct{pos: pos, Name: name, Fields: fields}
 }
 
+// Enum представляет определение перечисления.
+// Соответствует грамматике: Enum ::= "enum" IDENTIFIER "{" Variant ("," Variant)* [","] "}"
+type Enum struct {
+	pos      Position  // Позиция ключевого слова "enum".
+	Name     string    // Имя перечисления.
+	Variants []Variant // Варианты в порядке объявления.
+	Public   bool      // Перечисление объявлено с модификатором pub.
+	Attrs    []string  // Внешние атрибуты в записи исходного кода.
+}
+
+// Pos возвращает позицию начала перечисления.
+func (e *Enum) Pos() Position { return e.pos }
+
+// SetPos задаёт позицию начала перечисления.
+func (e *Enum) SetPos(pos Position) { e.pos = pos }
+
+// String возвращает строковое представление перечисления.
+func (e *Enum) String() string { return fmt.Sprintf("Enum{Name: %s}", e.Name) }
+
+// itemString реализует интерфейс Item.
+func (e *Enum) itemString() string { return e.String() }
+
+// NewEnum создаёт новый узел Enum.
+func NewEnum(pos Position, name string, variants []Variant) *Enum {
+	return &Enum{pos: pos, Name: name, Variants: variants}
+}
+
+// Variant представляет вариант перечисления: единичный (`Red`), кортежный (`Some(T)`)
+// или структурный (`Move { x: i32 }`).
+// Соответствует грамматике: Variant ::= IDENTIFIER [ "(" Type ("," Type)* ")" | "{" Field* "}" ]
+type Variant struct {
+	pos    Position // Позиция имени варианта.
+	Name   string   // Имя варианта.
+	Types  []Type   // Типы полей кортежного варианта.
+	Fields []Field  // Поля структурного варианта.
+}
+
+// Pos возвращает позицию начала варианта.
+func (v *Variant) Pos() Position { return v.pos }
+
+// SetPos задаёт позицию начала варианта.
+func (v *Variant) SetPos(pos Position) { v.pos = pos }
+
+// String возвращает строковое представление варианта.
+func (v *Variant) String() string { return fmt.Sprintf("Variant{Name: %s}", v.Name) }
+
+// NewVariant создаёт новый узел Variant с полями кортежного (types)
+// или структурного (fields) варианта; для единичного варианта оба списка пусты.
+func NewVariant(pos Position, name string, types []Type, fields []Field) *Variant {
+	return &Variant{pos: pos, Name: name, Types: types, Fields: fields}
+}
+
+// Impl представляет блок реализации методов типа.
+// Соответствует грамматике: Impl ::= "impl" IDENTIFIER "{" Function* "}"
+type Impl struct {
+	pos     Position    // Позиция ключевого слова "impl".
+	Type    string      // Имя типа, для которого реализуются методы.
+	Methods []*Function // Методы и ассоциированные функции.
+}
+
+// Pos возвращает позицию начала блока impl.
+func (i *Impl) Pos() Position { return i.pos }
+
+// SetPos задаёт позицию начала блока impl.
+func (i *Impl) SetPos(pos Position) { i.pos = pos }
+
+// String возвращает строковое представление блока impl.
+func (i *Impl) String() string { return fmt.Sprintf("Impl{Type: %s}", i.Type) }
+
+// itemString реализует интерфейс Item.
+func (i *Impl) itemString() string { return i.String() }
+
+// NewImpl создаёт новый узел Impl.
+func NewImpl(pos Position, typ string, methods []*Function) *Impl {
+	return &Impl{pos: pos, Type: typ, Methods: methods}
+}
+
 // Field представляет поле структуры.
 // Соответствует грамматике: Field ::= IDENTIFIER ":" Type
 type Field struct {
-	pos  Position // Позиция имени поля.
-	Name string   // Имя поля.
-	Type Type     // Тип поля.
+	pos    Position // Позиция имени поля.
+	Name   string   // Имя поля.
+	Type   Type     // Тип поля.
+	Public bool     // Поле объявлено с модификатором pub.
 }
 
 // Pos возвращает позицию начала поля.
 func (f *Field) Pos() Position { return f.pos }
 
+// SetPos задаёт позицию начала поля.
+func (f *Field) SetPos(pos Position) { f.pos = pos }
+
 // String возвращает строковое представление поля.
 func (f *Field) String() string { return fmt.Sprintf("Field{Name: %s}", f.Name) }
 
@@ -121,18 +238,24 @@ type Stmt interface {
 }
 
 // LetStmt представляет оператор объявления переменной.
-// Соответствует грамматике: "let" IDENTIFIER [":" Type] "=" Expr ";"
-// В текущей реализации шаблон (Pattern) упрощён до идентификатора.
+// Соответствует грамматике: "let" ["mut"] Pattern [":" Type] "=" Expr ";"
+// Объявление одной переменной хранит её имя в Name; деструктурирующее объявление
+// (`let (a, b) = pair;`) хранит образец в Pattern, а Name остаётся пустым.
 type LetStmt struct {
-	pos  Position // Позиция ключевого слова "let".
-	Name string   // Имя переменной.
-	Type Type     // Тип переменной (может быть nil для вывода типа).
-	Init Expr     // Выражение инициализации.
+	pos     Position // Позиция ключевого слова "let".
+	Name    string   // Имя переменной.
+	Pattern Pattern  // Образец деструктуризации (nil, если объявлена одна переменная).
+	Type    Type     // Тип переменной (может быть nil для вывода типа).
+	Init    Expr     // Выражение инициализации.
+	Mutable bool     // Объявлена ли переменная как `mut`.
 }
 
 // Pos возвращает позицию начала оператора let.
 func (ls *LetStmt) Pos() Position { return ls.pos }
 
+// SetPos задаёт позицию начала оператора let.
+func (ls *LetStmt) SetPos(pos Position) { ls.pos = pos }
+
 // String возвращает строковое представление оператора let.
 func (ls *LetStmt) String() string { return fmt.Sprintf("LetStmt{Name: %s}", ls.Name) }
 
@@ -148,11 +271,15 @@ func NewLetStmt(pos Position, name string, typ Type, init Expr) *LetStmt {
 type ExprStmt struct {
 	pos  Position // Позиция выражения.
 	Expr Expr     // Выражение.
+	Semi bool     // Выражение завершено ';' и не является значением блока.
 }
 
 // Pos возвращает позицию выражения-оператора.
 func (es *ExprStmt) Pos() Position { return es.pos }
 
+// SetPos задаёт позицию выражения-оператора.
+func (es *ExprStmt) SetPos(pos Position) { es.pos = pos }
+
 // String возвращает строковое представление выражения-оператора.
 func (es *ExprStmt) String() string { return "ExprStmt" }
 
@@ -164,6 +291,57 @@ func NewExprStmt(pos Position, expr Expr) *ExprStmt {
 	return &ExprStmt{pos: pos, Expr: expr}
 }
 
+// AssignStmt представляет оператор присваивания.
+// Соответствует грамматике: AssignStmt ::= Expr "=" Expr ";"
+// Левая часть — идентификатор, доступ к полю или индексирование.
+type AssignStmt struct {
+	pos    Position // Позиция левой части.
+	Target Expr     // Левая часть присваивания.
+	Value  Expr     // Присваиваемое значение.
+}
+
+// Pos возвращает позицию оператора присваивания.
+func (as *AssignStmt) Pos() Position { return as.pos }
+
+// SetPos задаёт позицию оператора присваивания.
+func (as *AssignStmt) SetPos(pos Position) { as.pos = pos }
+
+// String возвращает строковое представление оператора присваивания.
+func (as *AssignStmt) String() string { return "AssignStmt" }
+
+// stmtString реализует интерфейс Stmt.
+func (as *AssignStmt) stmtString() string { return as.String() }
+
+// NewAssignStmt создаёт новый узел AssignStmt.
+func NewAssignStmt(pos Position, target Expr, value Expr) *AssignStmt {
+	return &AssignStmt{pos: pos, Target: target, Value: value}
+}
+
+// FnStmt представляет вложенное объявление функции внутри блока.
+// Соответствует грамматике: FnStmt ::= Function
+// Вложенная функция не захватывает локальные переменные объемлющей функции.
+type FnStmt struct {
+	pos  Position  // Позиция ключевого слова "fn".
+	Func *Function // Объявленная функция.
+}
+
+// Pos возвращает позицию вложенного объявления функции.
+func (fs *FnStmt) Pos() Position { return fs.pos }
+
+// SetPos задаёт позицию вложенного объявления функции.
+func (fs *FnStmt) SetPos(pos Position) { fs.pos = pos }
+
+// String возвращает строковое представление вложенного объявления функции.
+func (fs *FnStmt) String() string { return fmt.Sprintf("FnStmt{Name: %s}", fs.Func.Name) }
+
+// stmtString реализует интерфейс Stmt.
+func (fs *FnStmt) stmtString() string { return fs.String() }
+
+// NewFnStmt создаёт новый узел FnStmt.
+func NewFnStmt(pos Position, fn *Function) *FnStmt {
+	return &FnStmt{pos: pos, Func: fn}
+}
+
 // Block представляет блок кода, ограниченный фигурными скобками.
 // Соответствует грамматике: Block ::= "{" Stmt* "}"
 type Block struct {
@@ -174,6 +352,9 @@ type Block struct {
 // Pos возвращает позицию начала блока.
 func (b *Block) Pos() Position { return b.pos }
 
+// SetPos задаёт позицию начала блока.
+func (b *Block) SetPos(pos Position) { b.pos = pos }
+
 // String возвращает строковое представление блока.
 func (b *Block) String() string { return fmt.Sprintf("Block{Stmts: %d}", len(b.Stmts)) }
 
@@ -205,6 +386,9 @@ type UnaryExpr struct {
 // Pos возвращает позицию унарного оператора.
 func (ue *UnaryExpr) Pos() Position { return ue.pos }
 
+// SetPos задаёт позицию унарного оператора.
+func (ue *UnaryExpr) SetPos(pos Position) { ue.pos = pos }
+
 // String возвращает строковое представление унарного выражения.
 func (ue *UnaryExpr) String() string { return fmt.Sprintf("UnaryExpr{%s}", ue.Op) }
 
@@ -216,6 +400,62 @@ func NewUnaryExpr(pos Position, op string, expr Expr) *UnaryExpr {
 	return &UnaryExpr{pos: pos, Op: op, Expr: expr}
 }
 
+// CastExpr представляет приведение типа `expr as Type`.
+// Соответствует грамматике: CastExpr ::= Expr "as" Type
+type CastExpr struct {
+	pos  Position // Позиция приводимого выражения.
+	Expr Expr     // Приводимое выражение.
+	Type Type     // Целевой тип.
+}
+
+// Pos возвращает позицию приведения.
+func (ce *CastExpr) Pos() Position { return ce.pos }
+
+// SetPos задаёт позицию приведения.
+func (ce *CastExpr) SetPos(pos Position) { ce.pos = pos }
+
+// String возвращает строковое представление приведения.
+func (ce *CastExpr) String() string { return "CastExpr" }
+
+// exprString реализует интерфейс Expr.
+func (ce *CastExpr) exprString() string { return ce.String() }
+
+// NewCastExpr создаёт новый узел CastExpr.
+func NewCastExpr(pos Position, expr Expr, typ Type) *CastExpr {
+	return &CastExpr{pos: pos, Expr: expr, Type: typ}
+}
+
+// RangeExpr представляет диапазон `start..end` или, если Inclusive, `start..=end`.
+// Соответствует грамматике: RangeExpr ::= Expr (".." | "..=") Expr
+type RangeExpr struct {
+	pos       Position // Позиция начала диапазона.
+	Start     Expr     // Начало диапазона (включительно).
+	End       Expr     // Конец диапазона.
+	Inclusive bool     // Конец входит в диапазон (`..=`).
+}
+
+// Pos возвращает позицию диапазона.
+func (re *RangeExpr) Pos() Position { return re.pos }
+
+// SetPos задаёт позицию диапазона.
+func (re *RangeExpr) SetPos(pos Position) { re.pos = pos }
+
+// String возвращает строковое представление диапазона.
+func (re *RangeExpr) String() string {
+	if re.Inclusive {
+		return "RangeExpr{..=}"
+	}
+	return "RangeExpr{..}"
+}
+
+// exprString реализует интерфейс Expr.
+func (re *RangeExpr) exprString() string { return re.String() }
+
+// NewRangeExpr создаёт новый узел RangeExpr.
+func NewRangeExpr(pos Position, start, end Expr, inclusive bool) *RangeExpr {
+	return &RangeExpr{pos: pos, Start: start, End: end, Inclusive: inclusive}
+}
+
 // BinaryExpr представляет бинарное выражение (например, `a + b`, `x == y`).
 type BinaryExpr struct {
 	pos   Position // Позиция оператора.
@@ -227,6 +467,9 @@ type BinaryExpr struct {
 // Pos возвращает позицию бинарного оператора.
 func (be *BinaryExpr) Pos() Position { return be.pos }
 
+// SetPos задаёт позицию бинарного оператора.
+func (be *BinaryExpr) SetPos(pos Position) { be.pos = pos }
+
 // String возвращает строковое представление бинарного выражения.
 func (be *BinaryExpr) String() string { return fmt.Sprintf("BinaryExpr{%s}", be.Op) }
 
@@ -248,6 +491,9 @@ type Literal struct {
 // Pos возвращает позицию литерала.
 func (l *Literal) Pos() Position { return l.pos }
 
+// SetPos задаёт позицию литерала.
+func (l *Literal) SetPos(pos Position) { l.pos = pos }
+
 // String возвращает строковое представление литерала.
 func (l *Literal) String() string { return fmt.Sprintf("Literal{%s: %s}", l.Kind, l.Val) }
 
@@ -259,6 +505,22 @@ func NewLiteral(pos Position, kind string, val string) *Literal {
 	return &Literal{pos: pos, Kind: kind, Val: val}
 }
 
+// numberSuffixes — суффиксы типов числовых литералов; длинные проверяются раньше коротких.
+var numberSuffixes = []string{"isize", "usize", "i16", "i32", "i64", "u16", "u32", "u64", "f32", "f64", "i8", "u8"}
+
+// NumberSuffix отделяет от числового литерала суффикс типа: "300u8" -> ("300", "u8"),
+// "2.5f32" -> ("2.5", "f32"), "1_000_i64" -> ("1_000_", "i64"). Разделители `_`
+// в цифрах сохраняются. В шестнадцатеричном литерале `f32` — цифры, а не суффикс.
+func NumberSuffix(lit string) (digits, suffix string) {
+	hex := strings.HasPrefix(lit, "0x") || strings.HasPrefix(lit, "0X")
+	for _, name := range numberSuffixes {
+		if strings.HasSuffix(lit, name) && len(lit) > len(name) && !(hex && name[0] == 'f') {
+			return lit[:len(lit)-len(name)], name
+		}
+	}
+	return lit, ""
+}
+
 // CallExpr представляет вызов функции или метода.
 // Соответствует грамматике: CallExpr ::= Expr "(" [Expr ("," Expr)*] ")"
 type CallExpr struct {
@@ -270,6 +532,9 @@ type CallExpr struct {
 // Pos возвращает позицию вызова функции.
 func (ce *CallExpr) Pos() Position { return ce.pos }
 
+// SetPos задаёт позицию вызова функции.
+func (ce *CallExpr) SetPos(pos Position) { ce.pos = pos }
+
 // String возвращает строковое представление вызова функции.
 func (ce *CallExpr) String() string { return fmt.Sprintf("CallExpr{Args: %d}", len(ce.Args)) }
 
@@ -281,6 +546,131 @@ func NewCallExpr(pos Position, fn Expr, args []Expr) *CallExpr {
 	return &CallExpr{pos: pos, Func: fn, Args: args}
 }
 
+// MethodCall представляет вызов метода.
+// Соответствует грамматике: MethodCall ::= Expr "." IDENTIFIER "(" [Expr ("," Expr)*] ")"
+type MethodCall struct {
+	pos      Position // Позиция имени метода.
+	Receiver Expr     // Выражение-получатель.
+	Method   string   // Имя метода.
+	Args     []Expr   // Аргументы вызова (без получателя).
+}
+
+// Pos возвращает позицию вызова метода.
+func (mc *MethodCall) Pos() Position { return mc.pos }
+
+// SetPos задаёт позицию вызова метода.
+func (mc *MethodCall) SetPos(pos Position) { mc.pos = pos }
+
+// String возвращает строковое представление вызова метода.
+func (mc *MethodCall) String() string {
+	return fmt.Sprintf("MethodCall{%s, Args: %d}", mc.Method, len(mc.Args))
+}
+
+// exprString реализует интерфейс Expr.
+func (mc *MethodCall) exprString() string { return mc.String() }
+
+// NewMethodCall создаёт новый узел MethodCall.
+func NewMethodCall(pos Position, receiver Expr, method string, args []Expr) *MethodCall {
+	return &MethodCall{pos: pos, Receiver: receiver, Method: method, Args: args}
+}
+
+// TryExpr представляет оператор распространения ошибки `expr?`.
+type TryExpr struct {
+	pos  Position // Позиция символа "?".
+	Expr Expr     // Выражение типа Result или Option.
+}
+
+// Pos возвращает позицию оператора "?".
+func (te *TryExpr) Pos() Position { return te.pos }
+
+// SetPos задаёт позицию оператора "?".
+func (te *TryExpr) SetPos(pos Position) { te.pos = pos }
+
+// String возвращает строковое представление оператора "?".
+func (te *TryExpr) String() string { return "TryExpr" }
+
+// exprString реализует интерфейс Expr.
+func (te *TryExpr) exprString() string { return te.String() }
+
+// NewTryExpr создаёт новый узел TryExpr.
+func NewTryExpr(pos Position, expr Expr) *TryExpr {
+	return &TryExpr{pos: pos, Expr: expr}
+}
+
+// FieldExpr представляет доступ к полю структуры (например, `p.x`).
+type FieldExpr struct {
+	pos      Position // Позиция имени поля.
+	Receiver Expr     // Выражение, к полю которого выполняется доступ.
+	Field    string   // Имя поля.
+}
+
+// Pos возвращает позицию доступа к полю.
+func (fe *FieldExpr) Pos() Position { return fe.pos }
+
+// SetPos задаёт позицию доступа к полю.
+func (fe *FieldExpr) SetPos(pos Position) { fe.pos = pos }
+
+// String возвращает строковое представление доступа к полю.
+func (fe *FieldExpr) String() string { return fmt.Sprintf("FieldExpr{%s}", fe.Field) }
+
+// exprString реализует интерфейс Expr.
+func (fe *FieldExpr) exprString() string { return fe.String() }
+
+// NewFieldExpr создаёт новый узел FieldExpr.
+func NewFieldExpr(pos Position, receiver Expr, field string) *FieldExpr {
+	return &FieldExpr{pos: pos, Receiver: receiver, Field: field}
+}
+
+// PathExpr представляет путь из сегментов, разделённых "::" (например, `Color::Red`).
+// Соответствует грамматике: PathExpr ::= IDENTIFIER ("::" IDENTIFIER)+
+type PathExpr struct {
+	pos      Position // Позиция первого сегмента.
+	Segments []string // Сегменты пути в порядке записи.
+}
+
+// Pos возвращает позицию пути.
+func (pe *PathExpr) Pos() Position { return pe.pos }
+
+// SetPos задаёт позицию пути.
+func (pe *PathExpr) SetPos(pos Position) { pe.pos = pos }
+
+// String возвращает строковое представление пути.
+func (pe *PathExpr) String() string {
+	return fmt.Sprintf("PathExpr{%s}", strings.Join(pe.Segments, "::"))
+}
+
+// exprString реализует интерфейс Expr.
+func (pe *PathExpr) exprString() string { return pe.String() }
+
+// NewPathExpr создаёт новый узел PathExpr.
+func NewPathExpr(pos Position, segments []string) *PathExpr {
+	return &PathExpr{pos: pos, Segments: segments}
+}
+
+// IndexExpr представляет индексирование (например, `v[i]`).
+type IndexExpr struct {
+	pos   Position // Позиция открывающей скобки "[".
+	Expr  Expr     // Индексируемое выражение.
+	Index Expr     // Индекс.
+}
+
+// Pos возвращает позицию индексирования.
+func (ie *IndexExpr) Pos() Position { return ie.pos }
+
+// SetPos задаёт позицию индексирования.
+func (ie *IndexExpr) SetPos(pos Position) { ie.pos = pos }
+
+// String возвращает строковое представление индексирования.
+func (ie *IndexExpr) String() string { return "IndexExpr" }
+
+// exprString реализует интерфейс Expr.
+func (ie *IndexExpr) exprString() string { return ie.String() }
+
+// NewIndexExpr создаёт новый узел IndexExpr.
+func NewIndexExpr(pos Position, expr Expr, index Expr) *IndexExpr {
+	return &IndexExpr{pos: pos, Expr: expr, Index: index}
+}
+
 // Type — интерфейс для всех типов в языке.
 type Type interface {
 	Node
@@ -297,6 +687,9 @@ type PathType struct {
 // Pos возвращает позицию типа.
 func (pt *PathType) Pos() Position { return pt.pos }
 
+// SetPos задаёт позицию типа.
+func (pt *PathType) SetPos(pos Position) { pt.pos = pos }
+
 // String возвращает строковое представление типа.
 func (pt *PathType) String() string { return fmt.Sprintf("Type{%s}", pt.Path) }
 
@@ -308,18 +701,106 @@ func NewPathType(pos Position, path string) *PathType {
 	return &PathType{pos: pos, Path: path}
 }
 
+// RefType представляет ссылочный тип (например, `&T`, `&mut T`).
+type RefType struct {
+	pos     Position // Позиция символа "&".
+	Mutable bool     // Является ли ссылка изменяемой (`&mut T`).
+	Elem    Type     // Тип, на который указывает ссылка.
+}
+
+// Pos возвращает позицию ссылочного типа.
+func (rt *RefType) Pos() Position { return rt.pos }
+
+// SetPos задаёт позицию ссылочного типа.
+func (rt *RefType) SetPos(pos Position) { rt.pos = pos }
+
+// String возвращает строковое представление ссылочного типа.
+func (rt *RefType) String() string {
+	if rt.Mutable {
+		return fmt.Sprintf("RefType{&mut %s}", rt.Elem)
+	}
+	return fmt.Sprintf("RefType{&%s}", rt.Elem)
+}
+
+// typeString реализует интерфейс Type.
+func (rt *RefType) typeString() string { return rt.String() }
+
+// NewRefType создаёт новый узел RefType.
+func NewRefType(pos Position, mutable bool, elem Type) *RefType {
+	return &RefType{pos: pos, Mutable: mutable, Elem: elem}
+}
+
+// ArrayType представляет тип массива `[T; N]` или среза `[T]` (Len == nil).
+type ArrayType struct {
+	pos  Position // Позиция открывающей скобки "[".
+	Elem Type     // Тип элементов.
+	Len  Expr     // Длина массива (nil для среза).
+}
+
+// Pos возвращает позицию типа массива.
+func (at *ArrayType) Pos() Position { return at.pos }
+
+// SetPos задаёт позицию типа массива.
+func (at *ArrayType) SetPos(pos Position) { at.pos = pos }
+
+// String возвращает строковое представление типа массива.
+func (at *ArrayType) String() string {
+	if at.Len == nil {
+		return fmt.Sprintf("ArrayType{[%s]}", at.Elem)
+	}
+	return fmt.Sprintf("ArrayType{[%s; %s]}", at.Elem, at.Len)
+}
+
+// typeString реализует интерфейс Type.
+func (at *ArrayType) typeString() string { return at.String() }
+
+// NewArrayType создаёт новый узел ArrayType.
+func NewArrayType(pos Position, elem Type, length Expr) *ArrayType {
+	return &ArrayType{pos: pos, Elem: elem, Len: length}
+}
+
+// GenericType представляет обобщённый тип с аргументами (например, `Vec<i32>`, `HashMap<String, i32>`).
+type GenericType struct {
+	pos  Position // Позиция имени типа.
+	Path string   // Имя базового типа (например, "Vec").
+	Args []Type   // Аргументы типа в порядке объявления.
+}
+
+// Pos возвращает позицию обобщённого типа.
+func (gt *GenericType) Pos() Position { return gt.pos }
+
+// SetPos задаёт позицию обобщённого типа.
+func (gt *GenericType) SetPos(pos Position) { gt.pos = pos }
+
+// String возвращает строковое представление обобщённого типа.
+func (gt *GenericType) String() string {
+	return fmt.Sprintf("GenericType{%s, Args: %d}", gt.Path, len(gt.Args))
+}
+
+// typeString реализует интерфейс Type.
+func (gt *GenericType) typeString() string { return gt.String() }
+
+// NewGenericType создаёт новый узел GenericType.
+func NewGenericType(pos Position, path string, args []Type) *GenericType {
+	return &GenericType{pos: pos, Path: path, Args: args}
+}
+
 // Param представляет параметр функции.
-// Соответствует грамматике: Param ::= IDENTIFIER ":" Type
+// Соответствует грамматике: Param ::= ["mut"] IDENTIFIER ":" Type
 // В текущей реализации шаблон (Pattern) упрощён до идентификатора.
 type Param struct {
-	pos  Position // Позиция имени параметра.
-	Name string   // Имя параметра.
-	Type Type     // Тип параметра.
+	pos     Position // Позиция имени параметра.
+	Name    string   // Имя параметра.
+	Type    Type     // Тип параметра.
+	Mutable bool     // Объявлен ли параметр как `mut`.
 }
 
 // Pos возвращает позицию параметра.
 func (p *Param) Pos() Position { return p.pos }
 
+// SetPos задаёт позицию параметра.
+func (p *Param) SetPos(pos Position) { p.pos = pos }
+
 // String возвращает строковое представление параметра.
 func (p *Param) String() string { return fmt.Sprintf("Param{Name: %s}", p.Name) }
 
@@ -337,6 +818,9 @@ type BlockExpr struct {
 // Pos возвращает позицию блочного выражения.
 func (be *BlockExpr) Pos() Position { return be.pos }
 
+// SetPos задаёт позицию блочного выражения.
+func (be *BlockExpr) SetPos(pos Position) { be.pos = pos }
+
 // String возвращает строковое представление блочного выражения.
 func (be *BlockExpr) String() string { return "BlockExpr" }
 
@@ -347,3 +831,519 @@ func (be *BlockExpr) exprString() string { return be.String() }
 func NewBlockExpr(pos Position, block *Block) *BlockExpr {
 	return &BlockExpr{pos: pos, Block: block}
 }
+
+// TupleExpr представляет кортежное выражение (например, `(1, 2)`).
+type TupleExpr struct {
+	pos   Position // Позиция открывающей скобки "(".
+	Elems []Expr   // Элементы кортежа.
+}
+
+// Pos возвращает позицию кортежного выражения.
+func (te *TupleExpr) Pos() Position { return te.pos }
+
+// SetPos задаёт позицию кортежного выражения.
+func (te *TupleExpr) SetPos(pos Position) { te.pos = pos }
+
+// String возвращает строковое представление кортежного выражения.
+func (te *TupleExpr) String() string { return fmt.Sprintf("TupleExpr{Elems: %d}", len(te.Elems)) }
+
+// exprString реализует интерфейс Expr.
+func (te *TupleExpr) exprString() string { return te.String() }
+
+// NewTupleExpr создаёт новый узел TupleExpr.
+func NewTupleExpr(pos Position, elems []Expr) *TupleExpr {
+	return &TupleExpr{pos: pos, Elems: elems}
+}
+
+// ArrayExpr представляет литерал массива (например, `[1, 2, 3]`).
+type ArrayExpr struct {
+	pos   Position // Позиция открывающей скобки "[".
+	Elems []Expr   // Элементы массива.
+}
+
+// Pos возвращает позицию литерала массива.
+func (ae *ArrayExpr) Pos() Position { return ae.pos }
+
+// SetPos задаёт позицию литерала массива.
+func (ae *ArrayExpr) SetPos(pos Position) { ae.pos = pos }
+
+// String возвращает строковое представление литерала массива.
+func (ae *ArrayExpr) String() string { return fmt.Sprintf("ArrayExpr{Elems: %d}", len(ae.Elems)) }
+
+// exprString реализует интерфейс Expr.
+func (ae *ArrayExpr) exprString() string { return ae.String() }
+
+// NewArrayExpr создаёт новый узел ArrayExpr.
+func NewArrayExpr(pos Position, elems []Expr) *ArrayExpr {
+	return &ArrayExpr{pos: pos, Elems: elems}
+}
+
+// ArrayRepeatExpr представляет массив из повторяющегося значения (например, `[0; 5]`).
+type ArrayRepeatExpr struct {
+	pos   Position // Позиция открывающей скобки "[".
+	Value Expr     // Повторяемое значение.
+	Len   Expr     // Число элементов.
+}
+
+// Pos возвращает позицию выражения.
+func (ar *ArrayRepeatExpr) Pos() Position { return ar.pos }
+
+// SetPos задаёт позицию выражения.
+func (ar *ArrayRepeatExpr) SetPos(pos Position) { ar.pos = pos }
+
+// String возвращает строковое представление выражения.
+func (ar *ArrayRepeatExpr) String() string { return "ArrayRepeatExpr" }
+
+// exprString реализует интерфейс Expr.
+func (ar *ArrayRepeatExpr) exprString() string { return ar.String() }
+
+// NewArrayRepeatExpr создаёт новый узел ArrayRepeatExpr.
+func NewArrayRepeatExpr(pos Position, value, length Expr) *ArrayRepeatExpr {
+	return &ArrayRepeatExpr{pos: pos, Value: value, Len: length}
+}
+
+// StructLit представляет литерал структуры (например, `Point { x: 1, y: 2 }`).
+// Сокращённая запись `Point { x }` хранится как поле x со значением-идентификатором x.
+type StructLit struct {
+	pos    Position    // Позиция имени структуры.
+	Name   string      // Имя структуры.
+	Fields []FieldInit // Инициализаторы полей в порядке записи.
+}
+
+// FieldInit представляет инициализатор поля в литерале структуры.
+type FieldInit struct {
+	Name  string // Имя поля.
+	Value Expr   // Значение поля.
+}
+
+// Pos возвращает позицию литерала структуры.
+func (sl *StructLit) Pos() Position { return sl.pos }
+
+// SetPos задаёт позицию литерала структуры.
+func (sl *StructLit) SetPos(pos Position) { sl.pos = pos }
+
+// String возвращает строковое представление литерала структуры.
+func (sl *StructLit) String() string {
+	return fmt.Sprintf("StructLit{Name: %s, Fields: %d}", sl.Name, len(sl.Fields))
+}
+
+// exprString реализует интерфейс Expr.
+func (sl *StructLit) exprString() string { return sl.String() }
+
+// NewStructLit создаёт новый узел StructLit.
+func NewStructLit(pos Position, name string, fields []FieldInit) *StructLit {
+	return &StructLit{pos: pos, Name: name, Fields: fields}
+}
+
+// TupleType представляет кортежный тип (например, `(i32, i32)`).
+type TupleType struct {
+	pos   Position // Позиция открывающей скобки "(".
+	Elems []Type   // Типы элементов кортежа.
+}
+
+// Pos возвращает позицию кортежного типа.
+func (tt *TupleType) Pos() Position { return tt.pos }
+
+// SetPos задаёт позицию кортежного типа.
+func (tt *TupleType) SetPos(pos Position) { tt.pos = pos }
+
+// String возвращает строковое представление кортежного типа.
+func (tt *TupleType) String() string { return fmt.Sprintf("TupleType{Elems: %d}", len(tt.Elems)) }
+
+// typeString реализует интерфейс Type.
+func (tt *TupleType) typeString() string { return tt.String() }
+
+// NewTupleType создаёт новый узел TupleType.
+func NewTupleType(pos Position, elems []Type) *TupleType {
+	return &TupleType{pos: pos, Elems: elems}
+}
+
+// IfExpr представляет условное выражение.
+// Соответствует грамматике: IfExpr ::= "if" Expr Block ["else" (Block | IfExpr)]
+type IfExpr struct {
+	pos  Position // Позиция ключевого слова "if".
+	Cond Expr     // Условие.
+	Then *Block   // Ветвь, выполняемая при истинном условии.
+	Else Expr     // Ветвь else: *BlockExpr, *IfExpr (для `else if`) или nil.
+}
+
+// Pos возвращает позицию выражения if.
+func (ie *IfExpr) Pos() Position { return ie.pos }
+
+// SetPos задаёт позицию выражения if.
+func (ie *IfExpr) SetPos(pos Position) { ie.pos = pos }
+
+// String возвращает строковое представление выражения if.
+func (ie *IfExpr) String() string { return fmt.Sprintf("IfExpr{HasElse: %t}", ie.Else != nil) }
+
+// exprString реализует интерфейс Expr.
+func (ie *IfExpr) exprString() string { return ie.String() }
+
+// NewIfExpr создаёт новый узел IfExpr.
+func NewIfExpr(pos Position, cond Expr, then *Block, els Expr) *IfExpr {
+	return &IfExpr{pos: pos, Cond: cond, Then: then, Else: els}
+}
+
+// WhileExpr представляет цикл с условием.
+// Соответствует грамматике: WhileExpr ::= [LIFETIME ":"] "while" Expr Block
+type WhileExpr struct {
+	pos   Position // Позиция ключевого слова "while".
+	Cond  Expr     // Условие продолжения цикла.
+	Body  *Block   // Тело цикла.
+	Label string   // Метка цикла (`'outer`) или "".
+}
+
+// Pos возвращает позицию цикла while.
+func (we *WhileExpr) Pos() Position { return we.pos }
+
+// SetPos задаёт позицию цикла while.
+func (we *WhileExpr) SetPos(pos Position) { we.pos = pos }
+
+// String возвращает строковое представление цикла while.
+func (we *WhileExpr) String() string { return "WhileExpr" }
+
+// exprString реализует интерфейс Expr.
+func (we *WhileExpr) exprString() string { return we.String() }
+
+// NewWhileExpr создаёт новый узел WhileExpr.
+func NewWhileExpr(pos Position, cond Expr, body *Block) *WhileExpr {
+	return &WhileExpr{pos: pos, Cond: cond, Body: body}
+}
+
+// LoopExpr представляет бесконечный цикл; его значение задаётся `break value`.
+// Соответствует грамматике: LoopExpr ::= [LIFETIME ":"] "loop" Block
+type LoopExpr struct {
+	pos   Position // Позиция ключевого слова "loop".
+	Body  *Block   // Тело цикла.
+	Label string   // Метка цикла (`'outer`) или "".
+}
+
+// Pos возвращает позицию цикла loop.
+func (le *LoopExpr) Pos() Position { return le.pos }
+
+// SetPos задаёт позицию цикла loop.
+func (le *LoopExpr) SetPos(pos Position) { le.pos = pos }
+
+// String возвращает строковое представление цикла loop.
+func (le *LoopExpr) String() string { return "LoopExpr" }
+
+// exprString реализует интерфейс Expr.
+func (le *LoopExpr) exprString() string { return le.String() }
+
+// NewLoopExpr создаёт новый узел LoopExpr.
+func NewLoopExpr(pos Position, body *Block) *LoopExpr {
+	return &LoopExpr{pos: pos, Body: body}
+}
+
+// ForExpr представляет цикл обхода `for x in iter { ... }`.
+// Соответствует грамматике: ForExpr ::= [LIFETIME ":"] "for" IDENTIFIER "in" Expr Block
+type ForExpr struct {
+	pos   Position // Позиция ключевого слова "for".
+	Var   string   // Переменная цикла.
+	Iter  Expr     // Обходимое выражение.
+	Body  *Block   // Тело цикла.
+	Label string   // Метка цикла (`'outer`) или "".
+}
+
+// Pos возвращает позицию цикла for.
+func (fe *ForExpr) Pos() Position { return fe.pos }
+
+// SetPos задаёт позицию цикла for.
+func (fe *ForExpr) SetPos(pos Position) { fe.pos = pos }
+
+// String возвращает строковое представление цикла for.
+func (fe *ForExpr) String() string { return fmt.Sprintf("ForExpr{Var: %s}", fe.Var) }
+
+// exprString реализует интерфейс Expr.
+func (fe *ForExpr) exprString() string { return fe.String() }
+
+// NewForExpr создаёт новый узел ForExpr.
+func NewForExpr(pos Position, v string, iter Expr, body *Block) *ForExpr {
+	return &ForExpr{pos: pos, Var: v, Iter: iter, Body: body}
+}
+
+// BreakExpr представляет выход из цикла, возможно со значением (только в loop).
+// Соответствует грамматике: BreakExpr ::= "break" [LIFETIME] [Expr]
+type BreakExpr struct {
+	pos   Position // Позиция ключевого слова "break".
+	Value Expr     // Значение цикла loop или nil.
+	Label string   // Метка прерываемого цикла или "" для ближайшего.
+}
+
+// Pos возвращает позицию оператора break.
+func (be *BreakExpr) Pos() Position { return be.pos }
+
+// SetPos задаёт позицию оператора break.
+func (be *BreakExpr) SetPos(pos Position) { be.pos = pos }
+
+// String возвращает строковое представление оператора break.
+func (be *BreakExpr) String() string { return fmt.Sprintf("BreakExpr{HasValue: %t}", be.Value != nil) }
+
+// exprString реализует интерфейс Expr.
+func (be *BreakExpr) exprString() string { return be.String() }
+
+// NewBreakExpr создаёт новый узел BreakExpr.
+func NewBreakExpr(pos Position, value Expr) *BreakExpr {
+	return &BreakExpr{pos: pos, Value: value}
+}
+
+// ContinueExpr представляет переход к следующей итерации цикла.
+// Соответствует грамматике: ContinueExpr ::= "continue" [LIFETIME]
+type ContinueExpr struct {
+	pos   Position // Позиция ключевого слова "continue".
+	Label string   // Метка продолжаемого цикла или "" для ближайшего.
+}
+
+// Pos возвращает позицию оператора continue.
+func (ce *ContinueExpr) Pos() Position { return ce.pos }
+
+// SetPos задаёт позицию оператора continue.
+func (ce *ContinueExpr) SetPos(pos Position) { ce.pos = pos }
+
+// String возвращает строковое представление оператора continue.
+func (ce *ContinueExpr) String() string { return "ContinueExpr" }
+
+// exprString реализует интерфейс Expr.
+func (ce *ContinueExpr) exprString() string { return ce.String() }
+
+// NewContinueExpr создаёт новый узел ContinueExpr.
+func NewContinueExpr(pos Position) *ContinueExpr {
+	return &ContinueExpr{pos: pos}
+}
+
+// ClosureExpr представляет замыкание: `|x| x + 1`, `|| 0`, `|x: i32| -> i32 { x }`.
+// Соответствует грамматике: ClosureExpr ::= ["move"] "|" [Param ("," Param)*] "|" ["->" Type Block | Expr],
+// где тип параметра (":" Type) необязателен.
+type ClosureExpr struct {
+	pos        Position // Позиция открывающей "|" (или "move").
+	Params     []Param  // Параметры; Type равен nil, если тип не указан.
+	ReturnType Type     // Объявленный возвращаемый тип или nil.
+	Body       Expr     // Тело: выражение или BlockExpr.
+	Move       bool     // Объявлено ли замыкание как `move`.
+}
+
+// Pos возвращает позицию замыкания.
+func (ce *ClosureExpr) Pos() Position { return ce.pos }
+
+// SetPos задаёт позицию замыкания.
+func (ce *ClosureExpr) SetPos(pos Position) { ce.pos = pos }
+
+// String возвращает строковое представление замыкания.
+func (ce *ClosureExpr) String() string { return fmt.Sprintf("ClosureExpr{Params: %d}", len(ce.Params)) }
+
+// exprString реализует интерфейс Expr.
+func (ce *ClosureExpr) exprString() string { return ce.String() }
+
+// NewClosureExpr создаёт новый узел ClosureExpr.
+func NewClosureExpr(pos Position, params []Param, returnType Type, body Expr) *ClosureExpr {
+	return &ClosureExpr{pos: pos, Params: params, ReturnType: returnType, Body: body}
+}
+
+// MatchExpr представляет выражение сопоставления с образцом.
+// Соответствует грамматике: MatchExpr ::= "match" Expr "{" (MatchArm ","?)* "}"
+type MatchExpr struct {
+	pos       Position   // Позиция ключевого слова "match".
+	Scrutinee Expr       // Сопоставляемое выражение.
+	Arms      []MatchArm // Ветви сопоставления в порядке объявления.
+}
+
+// Pos возвращает позицию выражения match.
+func (me *MatchExpr) Pos() Position { return me.pos }
+
+// SetPos задаёт позицию выражения match.
+func (me *MatchExpr) SetPos(pos Position) { me.pos = pos }
+
+// String возвращает строковое представление выражения match.
+func (me *MatchExpr) String() string { return fmt.Sprintf("MatchExpr{Arms: %d}", len(me.Arms)) }
+
+// exprString реализует интерфейс Expr.
+func (me *MatchExpr) exprString() string { return me.String() }
+
+// NewMatchExpr создаёт новый узел MatchExpr.
+func NewMatchExpr(pos Position, scrutinee Expr, arms []MatchArm) *MatchExpr {
+	return &MatchExpr{pos: pos, Scrutinee: scrutinee, Arms: arms}
+}
+
+// MatchArm представляет ветвь выражения match.
+// Соответствует грамматике: MatchArm ::= Pattern "=>" Expr
+type MatchArm struct {
+	pos     Position // Позиция начала образца.
+	Pattern Pattern  // Образец ветви.
+	Body    Expr     // Выражение, вычисляемое при совпадении.
+}
+
+// Pos возвращает позицию ветви.
+func (ma *MatchArm) Pos() Position { return ma.pos }
+
+// SetPos задаёт позицию ветви.
+func (ma *MatchArm) SetPos(pos Position) { ma.pos = pos }
+
+// String возвращает строковое представление ветви.
+func (ma *MatchArm) String() string { return "MatchArm" }
+
+// NewMatchArm создаёт новый узел MatchArm.
+func NewMatchArm(pos Position, pattern Pattern, body Expr) *MatchArm {
+	return &MatchArm{pos: pos, Pattern: pattern, Body: body}
+}
+
+// Pattern — интерфейс для образцов (patterns) в ветвях match.
+type Pattern interface {
+	Node
+	// patternString возвращает строковое представление образца (для внутреннего использования).
+	patternString() string
+}
+
+// WildcardPattern представляет образец `_`, совпадающий с любым значением.
+type WildcardPattern struct {
+	pos Position // Позиция символа "_".
+}
+
+// Pos возвращает позицию образца.
+func (wp *WildcardPattern) Pos() Position { return wp.pos }
+
+// SetPos задаёт позицию образца.
+func (wp *WildcardPattern) SetPos(pos Position) { wp.pos = pos }
+
+// String возвращает строковое представление образца.
+func (wp *WildcardPattern) String() string { return "WildcardPattern" }
+
+// patternString реализует интерфейс Pattern.
+func (wp *WildcardPattern) patternString() string { return wp.String() }
+
+// NewWildcardPattern создаёт новый узел WildcardPattern.
+func NewWildcardPattern(pos Position) *WildcardPattern {
+	return &WildcardPattern{pos: pos}
+}
+
+// IdentPattern представляет образец-привязку: совпадает с любым значением и связывает его с именем.
+type IdentPattern struct {
+	pos  Position // Позиция имени.
+	Name string   // Имя связываемой переменной.
+}
+
+// Pos возвращает позицию образца.
+func (ip *IdentPattern) Pos() Position { return ip.pos }
+
+// SetPos задаёт позицию образца.
+func (ip *IdentPattern) SetPos(pos Position) { ip.pos = pos }
+
+// String возвращает строковое представление образца.
+func (ip *IdentPattern) String() string { return fmt.Sprintf("IdentPattern{%s}", ip.Name) }
+
+// patternString реализует интерфейс Pattern.
+func (ip *IdentPattern) patternString() string { return ip.String() }
+
+// NewIdentPattern создаёт новый узел IdentPattern.
+func NewIdentPattern(pos Position, name string) *IdentPattern {
+	return &IdentPattern{pos: pos, Name: name}
+}
+
+// LiteralPattern представляет образец-литерал (например, `0`, `"abc"`, `true`).
+type LiteralPattern struct {
+	pos  Position // Позиция литерала.
+	Kind string   // Тип литерала: "INT", "STRING", "BOOL" и т.д.
+	Val  string   // Строковое представление значения.
+}
+
+// Pos возвращает позицию образца.
+func (lp *LiteralPattern) Pos() Position { return lp.pos }
+
+// SetPos задаёт позицию образца.
+func (lp *LiteralPattern) SetPos(pos Position) { lp.pos = pos }
+
+// String возвращает строковое представление образца.
+func (lp *LiteralPattern) String() string {
+	return fmt.Sprintf("LiteralPattern{%s: %s}", lp.Kind, lp.Val)
+}
+
+// patternString реализует интерфейс Pattern.
+func (lp *LiteralPattern) patternString() string { return lp.String() }
+
+// NewLiteralPattern создаёт новый узел LiteralPattern.
+func NewLiteralPattern(pos Position, kind string, val string) *LiteralPattern {
+	return &LiteralPattern{pos: pos, Kind: kind, Val: val}
+}
+
+// TuplePattern представляет кортежный образец (например, `(0, _)`).
+type TuplePattern struct {
+	pos   Position  // Позиция открывающей скобки "(".
+	Elems []Pattern // Образцы элементов кортежа.
+}
+
+// Pos возвращает позицию образца.
+func (tp *TuplePattern) Pos() Position { return tp.pos }
+
+// SetPos задаёт позицию образца.
+func (tp *TuplePattern) SetPos(pos Position) { tp.pos = pos }
+
+// String возвращает строковое представление образца.
+func (tp *TuplePattern) String() string { return fmt.Sprintf("TuplePattern{Elems: %d}", len(tp.Elems)) }
+
+// patternString реализует интерфейс Pattern.
+func (tp *TuplePattern) patternString() string { return tp.String() }
+
+// NewTuplePattern создаёт новый узел TuplePattern.
+func NewTuplePattern(pos Position, elems []Pattern) *TuplePattern {
+	return &TuplePattern{pos: pos, Elems: elems}
+}
+
+// StructPattern представляет образец структуры: `Point { x, y: py, .. }`.
+type StructPattern struct {
+	pos    Position       // Позиция имени структуры.
+	Name   string         // Имя структуры.
+	Fields []FieldPattern // Образцы полей в порядке записи.
+	Rest   bool           // Образец завершается `..`: остальные поля игнорируются.
+}
+
+// FieldPattern представляет образец поля в образце структуры.
+// Сокращённая запись `x` эквивалентна `x: x`.
+type FieldPattern struct {
+	Name    string  // Имя поля.
+	Pattern Pattern // Образец значения поля.
+}
+
+// Pos возвращает позицию образца.
+func (sp *StructPattern) Pos() Position { return sp.pos }
+
+// SetPos задаёт позицию образца.
+func (sp *StructPattern) SetPos(pos Position) { sp.pos = pos }
+
+// String возвращает строковое представление образца.
+func (sp *StructPattern) String() string {
+	return fmt.Sprintf("StructPattern{Name: %s, Fields: %d}", sp.Name, len(sp.Fields))
+}
+
+// patternString реализует интерфейс Pattern.
+func (sp *StructPattern) patternString() string { return sp.String() }
+
+// NewStructPattern создаёт новый узел StructPattern.
+func NewStructPattern(pos Position, name string, fields []FieldPattern, rest bool) *StructPattern {
+	return &StructPattern{pos: pos, Name: name, Fields: fields, Rest: rest}
+}
+
+// VariantPattern представляет образец варианта перечисления: `Color::Red`
+// или кортежный вариант с образцами полей `Shape::Circle(r)`.
+type VariantPattern struct {
+	pos      Position  // Позиция первого сегмента пути.
+	Segments []string  // Сегменты пути: ["Shape", "Circle"].
+	Elems    []Pattern // Образцы полей кортежного варианта (nil, если скобок нет).
+}
+
+// Pos возвращает позицию образца.
+func (vp *VariantPattern) Pos() Position { return vp.pos }
+
+// SetPos задаёт позицию образца.
+func (vp *VariantPattern) SetPos(pos Position) { vp.pos = pos }
+
+// String возвращает строковое представление образца.
+func (vp *VariantPattern) String() string {
+	return fmt.Sprintf("VariantPattern{%s}", strings.Join(vp.Segments, "::"))
+}
+
+// patternString реализует интерфейс Pattern.
+func (vp *VariantPattern) patternString() string { return vp.String() }
+
+// NewVariantPattern создаёт новый узел VariantPattern.
+func NewVariantPattern(pos Position, segments []string, elems []Pattern) *VariantPattern {
+	return &VariantPattern{pos: pos, Segments: segments, Elems: elems}
+}
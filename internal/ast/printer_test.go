@@ -1,10 +1,13 @@
 package ast_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
 	"github.com/semetekare/rust2go/internal/ast"
+	"github.com/semetekare/rust2go/internal/lexer"
+	"github.com/semetekare/rust2go/internal/parser"
 	"github.com/semetekare/rust2go/internal/token"
 )
 
@@ -214,6 +217,23 @@ func TestNewField(t *testing.T) {
 	}
 }
 
+func TestSetPos(t *testing.T) {
+	pos := token.Position{Line: 12, Col: 5}
+	nodes := []ast.Node{
+		ast.NewLiteral(token.Position{}, "INT", "1"),
+		ast.NewBinaryExpr(token.Position{}, nil, "+", nil),
+		ast.NewBlock(token.Position{}, nil),
+		ast.NewPathType(token.Position{}, "i32"),
+		&ast.Param{Name: "x"},
+	}
+	for _, n := range nodes {
+		n.SetPos(pos)
+		if n.Pos() != pos {
+			t.Errorf("%s: expected position %v, got %v", n, pos, n.Pos())
+		}
+	}
+}
+
 func TestStringMethods(t *testing.T) {
 	pos := token.Position{Line: 1, Col: 1}
 
@@ -295,7 +315,7 @@ func TestInterfaceImplementation(t *testing.T) {
 	blk := ast.NewBlock(pos, []ast.Stmt{})
 	_ = ast.NewBlockExpr(pos, blk)
 
-	items = append(items, fn, st)
+	items = append(items, fn, st, ast.NewEnum(pos, "Color", nil))
 	stmts = append(stmts, ls, es, blk)
 	exprs = append(exprs, ast.NewLiteral(pos, "INT", "1"), ast.NewBinaryExpr(pos, nil, "+", nil), ast.NewUnaryExpr(pos, "-", nil), ast.NewCallExpr(pos, nil, nil), ast.NewBlockExpr(pos, blk))
 	types = append(types, ast.NewPathType(pos, "i32"))
@@ -412,6 +432,82 @@ func TestPrettyPrintBlockExpr(t *testing.T) {
 	}
 }
 
+func TestPrettyPrintEnum(t *testing.T) {
+	pos := token.Position{Line: 1, Col: 1}
+	enum := ast.NewEnum(pos, "Shape", []ast.Variant{
+		*ast.NewVariant(pos, "Empty", nil, nil),
+		*ast.NewVariant(pos, "Circle", []ast.Type{ast.NewPathType(pos, "f64")}, nil),
+		*ast.NewVariant(pos, "Rect", nil, []ast.Field{*ast.NewField(pos, "w", ast.NewPathType(pos, "f64"))}),
+	})
+
+	output := ast.PrettyPrint(ast.NewCrate(pos, []ast.Item{enum}))
+	expected := "Crate{Items: 1}\n" +
+		"  Enum{Name: Shape}\n" +
+		"    Variant{Name: Empty}\n" +
+		"    Variant{Name: Circle}\n" +
+		"      Type{f64}\n" +
+		"    Variant{Name: Rect}\n" +
+		"      Field{Name: w}\n" +
+		"        Type{f64}\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestPrettyPrintIfExpr(t *testing.T) {
+	pos := token.Position{Line: 1, Col: 1}
+	cond := ast.NewBinaryExpr(pos, ast.NewLiteral(pos, "IDENT", "x"), ">", ast.NewLiteral(pos, "INT", "0"))
+	then := ast.NewBlock(pos, []ast.Stmt{ast.NewExprStmt(pos, ast.NewLiteral(pos, "INT", "1"))})
+	els := ast.NewBlockExpr(pos, ast.NewBlock(pos, []ast.Stmt{ast.NewExprStmt(pos, ast.NewLiteral(pos, "INT", "2"))}))
+	body := ast.NewBlock(pos, []ast.Stmt{ast.NewExprStmt(pos, ast.NewIfExpr(pos, cond, then, els))})
+	fn := ast.NewFunction(pos, "sign", nil, ast.NewPathType(pos, "i32"), body)
+
+	output := ast.PrettyPrint(fn)
+	expected := "Function{Name: sign}\n" +
+		"  Type{i32}\n" +
+		"  Block{Stmts: 1}\n" +
+		"    ExprStmt\n" +
+		"      IfExpr{HasElse: true}\n" +
+		"        BinaryExpr{>}\n" +
+		"          Literal{IDENT: x}\n" +
+		"          Literal{INT: 0}\n" +
+		"        Block{Stmts: 1}\n" +
+		"          ExprStmt\n" +
+		"            Literal{INT: 1}\n" +
+		"        BlockExpr\n" +
+		"          Block{Stmts: 1}\n" +
+		"            ExprStmt\n" +
+		"              Literal{INT: 2}\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	pos := token.Position{Line: 1, Col: 1}
+	call := ast.NewCallExpr(pos, ast.NewLiteral(pos, "IDENT", "f"), []ast.Expr{
+		ast.NewLiteral(pos, "INT", "1"),
+		ast.NewStructLit(pos, "P", []ast.FieldInit{{Name: "x", Value: ast.NewLiteral(pos, "INT", "2")}}),
+	})
+
+	literals := []string{}
+	ast.Walk(call, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.Literal); ok {
+			literals = append(literals, lit.Val)
+		}
+		// Содержимое литерала структуры не обходим
+		_, isStruct := n.(*ast.StructLit)
+		return !isStruct
+	})
+	if got := strings.Join(literals, " "); got != "f 1" {
+		t.Errorf("Expected literals [f 1], got %v", literals)
+	}
+
+	if got := len(ast.Children(call.Args[1])); got != 1 {
+		t.Errorf("Expected struct literal field value as its only child, got %d children", got)
+	}
+}
+
 func TestPrettyPrintNestedExpressions(t *testing.T) {
 	pos := token.Position{Line: 1, Col: 1}
 
@@ -440,3 +536,91 @@ func TestPrettyPrintNestedExpressions(t *testing.T) {
 		t.Error("Expected BinaryExpr in output")
 	}
 }
+
+func TestMarshalJSON(t *testing.T) {
+	pos := token.Position{Line: 1, Col: 1}
+	lit := ast.NewLiteral(token.Position{Line: 1, Col: 20}, "INT", "1")
+	body := ast.NewBlock(token.Position{Line: 1, Col: 10}, []ast.Stmt{ast.NewExprStmt(lit.Pos(), lit)})
+	fn := ast.NewFunction(pos, "main", nil, nil, body)
+	crate := ast.NewCrate(pos, []ast.Item{fn})
+
+	data, err := ast.MarshalJSON(crate)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+
+	item := root["items"].([]any)[0].(map[string]any)
+	if item["kind"] != "Function" || item["name"] != "main" {
+		t.Errorf("Expected Function main, got %v", item)
+	}
+	stmt := item["body"].(map[string]any)["stmts"].([]any)[0].(map[string]any)
+	expr := stmt["expr"].(map[string]any)
+	if expr["kind"] != "Literal" || expr["literalKind"] != "INT" || expr["val"] != "1" {
+		t.Errorf("Expected INT literal 1, got %v", expr)
+	}
+	if pos := expr["pos"].(map[string]any); pos["line"] != 1.0 || pos["col"] != 20.0 {
+		t.Errorf("Expected position 1:20, got %v", pos)
+	}
+	if item["receiver"] != nil {
+		t.Errorf("Expected null receiver, got %v", item["receiver"])
+	}
+}
+
+func TestClone(t *testing.T) {
+	toks, err := lexer.NewLexer().Lex(`
+struct P { x: i32 }
+
+fn f(a: i32, b: i32) -> i32 {
+    let p = P { x: 1 };
+    g(a, 2) + b
+}
+`)
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+	crate, errs := parser.NewParser(toks).ParseFile()
+	if len(errs) > 0 {
+		t.Fatalf("Parse errors: %v", errs)
+	}
+	before := ast.PrettyPrint(crate)
+
+	clone := ast.Clone(crate).(*ast.Crate)
+	if ast.PrettyPrint(clone) != before {
+		t.Fatalf("Expected identical clone, got:\n%s", ast.PrettyPrint(clone))
+	}
+
+	// Изменяем литерал, параметр и поле в копии
+	fn := clone.Items[1].(*ast.Function)
+	fn.Params[0].Name = "z"
+	tail := fn.Body.Stmts[1].(*ast.ExprStmt).Expr.(*ast.BinaryExpr)
+	tail.Left.(*ast.CallExpr).Args[1].(*ast.Literal).Val = "42"
+	fn.Body.Stmts[0].(*ast.LetStmt).Init.(*ast.StructLit).Fields[0].Name = "y"
+	clone.Items[0].(*ast.Struct).Fields[0].Name = "w"
+
+	if after := ast.PrettyPrint(crate); after != before {
+		t.Errorf("Expected original unchanged, got:\n%s", after)
+	}
+	orig := crate.Items[1].(*ast.Function)
+	lit := orig.Body.Stmts[1].(*ast.ExprStmt).Expr.(*ast.BinaryExpr).Left.(*ast.CallExpr).Args[1].(*ast.Literal)
+	if lit.Val != "2" || orig.Params[0].Name != "a" {
+		t.Errorf("Expected original literal 2 and param a, got %s and %s", lit.Val, orig.Params[0].Name)
+	}
+	if name := orig.Body.Stmts[0].(*ast.LetStmt).Init.(*ast.StructLit).Fields[0].Name; name != "x" {
+		t.Errorf("Expected original struct literal field x, got %s", name)
+	}
+	if fn.Pos() != orig.Pos() {
+		t.Errorf("Expected clone to keep position %v, got %v", orig.Pos(), fn.Pos())
+	}
+}
+
+func TestDerives(t *testing.T) {
+	attrs := []string{"#[derive(Debug, Clone)]", "#[allow(dead_code)]", "#[derive( PartialEq )]", "#[derived(X)]"}
+	got := strings.Join(ast.Derives(attrs), " ")
+	if want := "Debug Clone PartialEq"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
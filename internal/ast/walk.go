@@ -0,0 +1,65 @@
+package ast
+
+import "reflect"
+
+// Children возвращает непосредственные дочерние узлы n в порядке объявления полей,
+// который совпадает с порядком в исходном коде. Дочерние узлы находятся по
+// экспортируемым полям узла, поэтому новые типы узлов не требуют доработки обхода:
+// учитываются поля-узлы, списки узлов (в том числе хранящихся по значению, как Param
+// и Field) и вложенные структуры без позиции (FieldInit). Отсутствующие (nil) узлы пропускаются.
+func Children(n Node) []Node {
+	v := reflect.ValueOf(n)
+	if n == nil || v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil
+	}
+	children := []Node{}
+	collectChildren(v.Elem(), &children)
+	return children
+}
+
+// collectChildren добавляет в children узлы, найденные в полях структуры v.
+func collectChildren(v reflect.Value, children *[]Node) {
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			collectNodes(v.Field(i), children)
+		}
+	}
+}
+
+// collectNodes добавляет в children узел v или узлы, содержащиеся в нём.
+func collectNodes(v reflect.Value, children *[]Node) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if n, ok := v.Interface().(Node); ok && v.Kind() == reflect.Pointer {
+			*children = append(*children, n)
+			return
+		}
+		collectNodes(v.Elem(), children)
+	case reflect.Struct:
+		if v.CanAddr() {
+			if n, ok := v.Addr().Interface().(Node); ok {
+				*children = append(*children, n)
+				return
+			}
+		}
+		collectChildren(v, children)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectNodes(v.Index(i), children)
+		}
+	}
+}
+
+// Walk обходит дерево с корнем n в глубину, вызывая visit для каждого узла
+// до его потомков. Если visit возвращает false, потомки узла не обходятся.
+func Walk(n Node, visit func(Node) bool) {
+	if n == nil || !visit(n) {
+		return
+	}
+	for _, child := range Children(n) {
+		Walk(child, visit)
+	}
+}
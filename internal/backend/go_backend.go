@@ -3,43 +3,83 @@ package backend
 
 import (
 	"fmt"
+	"go/format"
+	"go/scanner"
+	gotoken "go/token"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/semetekare/rust2go/internal/ir"
+	"github.com/semetekare/rust2go/internal/lexer"
+	"github.com/semetekare/rust2go/internal/token"
 )
 
 // Generator генерирует код на Go из IR.
 type Generator struct {
 	builder strings.Builder
 	indent  int
+	helpers map[string]bool // Вспомогательные функции, используемые сгенерированным кодом
+	imports map[string]bool // Пакеты, на которые ссылается сгенерированный код
+	fn      *ir.Function    // Функция, для которой сейчас генерируется тело
+
+	pos       token.Position // Позиция исходного кода, из которой генерируются строки
+	line      int            // Число уже выведенных строк
+	positions ir.PositionMap // Строка вывода -> позиция исходного кода
+
+	funcNames   map[string]string            // Имя свободной функции Rust -> имя Go
+	methodNames map[string]map[string]string // Тип -> имя метода Rust -> имя Go
+	fieldNames  map[string]map[string]string // Структура -> имя поля Rust -> имя Go
+	equatable   map[string]bool              // Структуры с методом Equals из #[derive(PartialEq)]
+
+	// PanicLocations включает указание места в исходном файле в сообщениях
+	// паник, порождаемых `.unwrap()`/`.expect()` (как это делает Rust).
+	PanicLocations bool
+	// SourceFile — имя исходного .rs файла для сообщений паник.
+	SourceFile string
+	// CamelCase переводит snake_case в именах функций, методов и полей в camelCase
+	// (`pub fn foo_bar` -> FooBar). Включён по умолчанию; при false имена
+	// сохраняют написание Rust и получают только регистр первой буквы (Foo_bar).
+	CamelCase bool
+	// GeneratedHeader добавляет перед package стандартный комментарий
+	// сгенерированного файла (см. GeneratedComment).
+	GeneratedHeader bool
 }
 
+// GeneratedComment — маркер сгенерированного файла Go (https://go.dev/s/generatedcode),
+// по которому go generate, линтеры и редакторы распознают файл.
+const GeneratedComment = "// Code generated by rust2go; DO NOT EDIT."
+
 // NewGenerator создаёт новый генератор.
 func NewGenerator() *Generator {
 	return &Generator{
-		indent: 0,
+		indent:    0,
+		CamelCase: true,
 	}
 }
 
 // Generate генерирует код Go из IR модуля.
+// Карта строк сгенерированного кода на позиции исходного записывается в module.PositionMap.
 func (g *Generator) Generate(module *ir.Module) string {
 	g.builder.Reset()
+	g.helpers = make(map[string]bool)
+	g.imports = make(map[string]bool)
+	g.positions = make(ir.PositionMap)
+	g.line = 0
+	g.collectNames(module)
 
-	// Генерируем заголовок пакета
-	g.emit("package %s", module.PackageName)
-	g.emit("")
-	g.emit("import (")
-	g.indent++
-	g.emit("\"fmt\"")
-	g.emit("// Add more imports as needed")
-	g.indent--
-	g.emit(")")
-	g.emit("")
+	// Генерируем перечисления
+	for _, en := range module.Enums {
+		g.generateEnum(en)
+		g.emit("")
+	}
 
 	// Генерируем структуры
 	for _, st := range module.Structs {
 		g.generateStruct(st)
 		g.emit("")
+		g.generateDerives(st)
 	}
 
 	// Генерируем функции
@@ -48,15 +88,442 @@ func (g *Generator) Generate(module *ir.Module) string {
 		g.emit("")
 	}
 
+	// Пакет main без точки входа не собирается: добавляем пустую main
+	if module.PackageName == "main" && !module.HasMain() {
+		g.emit("func main() {")
+		g.emit("}")
+		g.emit("")
+	}
+
+	// Генерируем вспомогательные функции, на которые ссылается код
+	g.generateHelpers()
+
+	// Заголовок пакета и импорты известны только после генерации тела
+	body, bodyPositions := g.builder.String(), g.positions
+	g.builder.Reset()
+	g.pos = token.Position{}
+	g.line = 0
+	if g.GeneratedHeader {
+		// Пустая строка отделяет маркер, чтобы он не стал документацией пакета
+		g.emit("%s", GeneratedComment)
+		g.emit("")
+	}
+	g.emit("package %s", module.PackageName)
+	g.emit("")
+	g.generateImports()
+	g.builder.WriteString(body)
+
+	// Строки тела сдвигаются на длину заголовка
+	module.PositionMap = make(ir.PositionMap, len(bodyPositions))
+	for line, pos := range bodyPositions {
+		module.PositionMap[line+g.line] = pos
+	}
 	return g.builder.String()
 }
 
+// collectNames вычисляет имена Go для функций, методов и полей модуля,
+// чтобы определения и ссылки на них переименовывались согласованно.
+func (g *Generator) collectNames(module *ir.Module) {
+	g.funcNames = make(map[string]string)
+	g.methodNames = make(map[string]map[string]string)
+	g.fieldNames = make(map[string]map[string]string)
+	g.equatable = make(map[string]bool)
+
+	for _, st := range module.Structs {
+		g.equatable[st.Name] = slices.Contains(st.Derives, "PartialEq")
+		fields := make(map[string]string)
+		for _, field := range st.Fields {
+			fields[field.Name] = g.fieldName(field.Name, field.Public)
+		}
+		g.fieldNames[st.Name] = fields
+	}
+	for _, fn := range module.Functions {
+		if fn.AssocType != "" {
+			g.funcNames[fn.QualifiedName()] = g.assocName(fn)
+			continue
+		}
+		if fn.GoReceiver == "" {
+			g.funcNames[fn.Name] = g.goName(fn.Name, fn.Public)
+			continue
+		}
+		recv := receiverType(fn)
+		if g.methodNames[recv] == nil {
+			g.methodNames[recv] = make(map[string]string)
+		}
+		name := g.goName(fn.Name, fn.Public)
+		// В Go поле и метод не могут называться одинаково; геттер `fn x(&self)`
+		// для приватного поля x по соглашению Go становится методом X
+		if g.fieldNames[recv][fn.Name] == name {
+			name = capitalize(name)
+		}
+		g.methodNames[recv][fn.Name] = name
+	}
+	// Точка входа программы не переименовывается
+	if _, ok := g.funcNames["main"]; ok {
+		g.funcNames["main"] = "main"
+	}
+}
+
+// assocName возвращает имя Go для ассоциированной функции: в Go нет функций,
+// привязанных к типу, поэтому она становится функцией пакета с именем типа —
+// `Point::new` переводится в NewPoint, `Point::from_pair` — в PointFromPair.
+func (g *Generator) assocName(fn *ir.Function) string {
+	name := capitalize(fn.AssocType) + capitalize(camelCase(fn.Name))
+	if fn.Name == "new" {
+		name = "New" + capitalize(fn.AssocType)
+	}
+	if fn.Public {
+		return name
+	}
+	return decapitalize(name)
+}
+
+// goName переводит имя элемента Rust в имя Go: pub-элементы экспортируются
+// (первая буква заглавная), приватные начинаются со строчной буквы.
+func (g *Generator) goName(name string, public bool) string {
+	if g.CamelCase {
+		name = camelCase(name)
+	}
+	if public {
+		return capitalize(name)
+	}
+	return decapitalize(name)
+}
+
+// fieldName возвращает имя поля Go. Поля кортежей и кортежных структур (`w.0`)
+// становятся полями Field0, Field1, ...: имя Go не может начинаться с цифры.
+func (g *Generator) fieldName(name string, public bool) string {
+	if i, err := strconv.Atoi(name); err == nil {
+		return ir.TupleFieldName(i)
+	}
+	return g.goName(name, public)
+}
+
+// receiverType возвращает имя типа приёмника метода (без указателя).
+func receiverType(fn *ir.Function) string {
+	parts := strings.Fields(fn.GoReceiver)
+	return strings.TrimPrefix(parts[len(parts)-1], "*")
+}
+
+// typeName возвращает имя типа выражения, снимая указатель.
+func typeName(t *ir.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.IsPointer && t.ElementType != nil {
+		return t.ElementType.Name
+	}
+	return t.Name
+}
+
+// generateImports генерирует импорт пакетов, использованных при генерации, в порядке
+// goimports: по алфавиту, единственный пакет — одной строкой `import "fmt"`.
+// Если пакеты не использовались, импорт не генерируется.
+func (g *Generator) generateImports() {
+	if len(g.imports) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 1 {
+		g.emit("import %q", paths[0])
+		g.emit("")
+		return
+	}
+	g.emit("import (")
+	g.indent++
+	for _, path := range paths {
+		g.emit("%q", path)
+	}
+	g.indent--
+	g.emit(")")
+	g.emit("")
+}
+
+// use отмечает пакет как используемый сгенерированным кодом.
+func (g *Generator) use(path string) {
+	g.imports[path] = true
+}
+
+// GenerateFormatted генерирует код Go из IR модуля и приводит его к каноническому виду gofmt.
+// gofmt переносит строки (например, разворачивает тип кортежа `struct{ Field0 int; ... }`
+// на несколько строк), поэтому module.PositionMap пересчитывается для отформатированного кода.
+// Если сгенерированный код не разбирается go/format (ошибка генератора),
+// возвращается неформатированный код вместе с ошибкой.
+func (g *Generator) GenerateFormatted(module *ir.Module) (string, error) {
+	code := g.Generate(module)
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return code, fmt.Errorf("format generated code: %w", err)
+	}
+	module.PositionMap = remapPositions([]byte(code), formatted, module.PositionMap)
+	return string(formatted), nil
+}
+
+// remapPositions переносит карту позиций positions со строк кода src на строки его
+// отформатированной версии formatted. gofmt меняет только пробелы, переводы строк
+// и точки с запятой, поэтому остальные лексемы обоих текстов идут в одном порядке:
+// строка formatted получает позицию строки src, на которой стоит её первая лексема.
+// Если последовательности лексем не совпадают, карта возвращается без изменений.
+func remapPositions(src, formatted []byte, positions ir.PositionMap) ir.PositionMap {
+	before, after := scanLines(src), scanLines(formatted)
+	if len(before) != len(after) {
+		return positions
+	}
+	remapped := make(ir.PositionMap, len(positions))
+	for i, tok := range after {
+		if tok.kind != before[i].kind {
+			return positions
+		}
+		if _, done := remapped[tok.line]; done {
+			continue
+		}
+		if pos, ok := positions[before[i].line]; ok {
+			remapped[tok.line] = pos
+		}
+	}
+	return remapped
+}
+
+// lineToken — лексема Go и номер строки, на которой она начинается.
+type lineToken struct {
+	kind gotoken.Token
+	line int
+}
+
+// scanLines разбивает код Go на лексемы, пропуская комментарии и точки с запятой
+// (в том числе вставленные автоматически в конце строки).
+func scanLines(src []byte) []lineToken {
+	file := gotoken.NewFileSet().AddFile("", -1, len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, 0)
+	tokens := []lineToken{}
+	for {
+		pos, kind, _ := s.Scan()
+		if kind == gotoken.EOF {
+			return tokens
+		}
+		if kind != gotoken.SEMICOLON {
+			tokens = append(tokens, lineToken{kind: kind, line: file.Line(pos)})
+		}
+	}
+}
+
+// unwrapHelper — имя вспомогательной функции для `.unwrap()`/`.expect()`.
+const unwrapHelper = "rustUnwrap"
+
+// repeatHelper — имя вспомогательной функции для массивов `[value; len]`.
+const repeatHelper = "rustRepeat"
+
+// boolToIntHelper — имя вспомогательной функции для приведения `b as i32`.
+const boolToIntHelper = "rustBoolToInt"
+
+// someHelper — имя вспомогательной функции для `Some(value)`: возвращает адрес копии значения.
+const someHelper = "rustSome"
+
+// generateHelpers генерирует вспомогательные функции, использованные при генерации.
+func (g *Generator) generateHelpers() {
+	if g.helpers[unwrapHelper] {
+		g.emit("func %s[T any](v *T, msg string) T {", unwrapHelper)
+		g.indent++
+		g.emit("if v == nil {")
+		g.indent++
+		g.emit("panic(msg)")
+		g.indent--
+		g.emit("}")
+		g.emit("return *v")
+		g.indent--
+		g.emit("}")
+		g.emit("")
+	}
+	if g.helpers[repeatHelper] {
+		g.emit("func %s[T any](v T, n int) []T {", repeatHelper)
+		g.indent++
+		g.emit("s := make([]T, n)")
+		g.emit("for i := range s {")
+		g.indent++
+		g.emit("s[i] = v")
+		g.indent--
+		g.emit("}")
+		g.emit("return s")
+		g.indent--
+		g.emit("}")
+		g.emit("")
+	}
+	if g.helpers[someHelper] {
+		g.emit("func %s[T any](v T) *T {", someHelper)
+		g.indent++
+		g.emit("return &v")
+		g.indent--
+		g.emit("}")
+		g.emit("")
+	}
+	if g.helpers[boolToIntHelper] {
+		g.emit("func %s(b bool) int {", boolToIntHelper)
+		g.indent++
+		g.emit("if b {")
+		g.indent++
+		g.emit("return 1")
+		g.indent--
+		g.emit("}")
+		g.emit("return 0")
+		g.indent--
+		g.emit("}")
+		g.emit("")
+	}
+}
+
+// isZeroLiteral сообщает, является ли выражение литералом нулевого значения Go.
+func isZeroLiteral(expr ir.Expression) bool {
+	lit, ok := expr.(*ir.LiteralExpr)
+	if !ok {
+		return false
+	}
+	switch lit.Kind {
+	case "INT":
+		return lit.Value == "0"
+	case "FLOAT":
+		x, err := strconv.ParseFloat(lit.Value, 64)
+		return err == nil && x == 0
+	case "BOOL":
+		return lit.Value == "false"
+	case "STRING":
+		return lit.Value == ""
+	}
+	return false
+}
+
 // generateStruct генерирует определение структуры на Go.
 func (g *Generator) generateStruct(st *ir.Struct) {
+	g.pos = st.Pos
+	if len(st.Fields) == 0 {
+		g.emit("type %s struct{}", st.Name)
+		return
+	}
 	g.emit("type %s struct {", st.Name)
 	g.indent++
 	for _, field := range st.Fields {
-		g.emit("%s %s", capitalize(field.Name), field.Type.String())
+		g.emit("%s %s", g.fieldNames[st.Name][field.Name], field.Type.String())
+	}
+	g.indent--
+	g.emit("}")
+}
+
+// generateEnum генерирует C-подобное перечисление как именованный целый тип
+// с константами вариантов, пронумерованными через iota.
+func (g *Generator) generateEnum(en *ir.Enum) {
+	g.pos = en.Pos
+	g.emit("type %s int", en.Name)
+	g.emit("")
+	g.emit("const (")
+	g.indent++
+	for i, variant := range en.Variants {
+		if i == 0 {
+			g.emit("%s %s = iota", variantName(en.Name, variant), en.Name)
+		} else {
+			g.emit("%s", variantName(en.Name, variant))
+		}
+	}
+	g.indent--
+	g.emit(")")
+}
+
+// variantName возвращает имя константы Go для варианта перечисления: `Color::Red` — ColorRed.
+func variantName(enum, variant string) string {
+	return enum + variant
+}
+
+// generateDerives генерирует методы Go для трейтов из #[derive(...)] структуры.
+// Метод не создаётся, если в impl уже объявлен метод с тем же именем Go.
+func (g *Generator) generateDerives(st *ir.Struct) {
+	for _, derive := range st.Derives {
+		switch derive {
+		case "Debug":
+			if !g.hasMethod(st.Name, "String") {
+				g.generateDebug(st)
+				g.emit("")
+			}
+		case "PartialEq":
+			if !g.hasMethod(st.Name, "Equals") {
+				g.generatePartialEq(st)
+				g.emit("")
+			}
+		}
+	}
+}
+
+// hasMethod сообщает, есть ли у типа метод с именем Go name.
+func (g *Generator) hasMethod(typ, name string) bool {
+	for _, method := range g.methodNames[typ] {
+		if method == name {
+			return true
+		}
+	}
+	return false
+}
+
+// generateDebug генерирует метод String, печатающий структуру в формате
+// {:?} из Rust: `Point { x: 1, y: 2 }`.
+func (g *Generator) generateDebug(st *ir.Struct) {
+	g.emit("func (x %s) String() string {", st.Name)
+	g.indent++
+	if len(st.Fields) == 0 {
+		g.emit("return %q", st.Name)
+	} else {
+		g.use("fmt")
+		labels := make([]string, len(st.Fields))
+		args := make([]string, len(st.Fields))
+		for i, field := range st.Fields {
+			// Строки в {:?} выводятся в кавычках
+			verb := "%v"
+			if field.Type.Name == "string" {
+				verb = "%q"
+			}
+			labels[i] = field.Name + ": " + verb
+			if st.Tuple {
+				labels[i] = verb
+			}
+			args[i] = "x." + g.fieldNames[st.Name][field.Name]
+		}
+		format := st.Name + " { " + strings.Join(labels, ", ") + " }"
+		if st.Tuple {
+			// Кортежная структура печатается без имён полей: `Meters(1.5)`
+			format = st.Name + "(" + strings.Join(labels, ", ") + ")"
+		}
+		g.emit("return fmt.Sprintf(%q, %s)", format, strings.Join(args, ", "))
+	}
+	g.indent--
+	g.emit("}")
+}
+
+// generatePartialEq генерирует метод Equals, сравнивающий все поля структуры:
+// Go не позволяет перегрузить ==.
+func (g *Generator) generatePartialEq(st *ir.Struct) {
+	g.emit("func (a %s) Equals(b %s) bool {", st.Name, st.Name)
+	g.indent++
+	if len(st.Fields) == 0 {
+		g.emit("return true")
+	} else {
+		conds := make([]string, len(st.Fields))
+		for i, field := range st.Fields {
+			name := g.fieldNames[st.Name][field.Name]
+			// Срезы и карты в Go не сравниваются через ==, вложенные структуры
+			// сравниваются своим методом Equals
+			switch {
+			case field.Type.IsArray || field.Type.IsMap:
+				g.use("reflect")
+				conds[i] = fmt.Sprintf("reflect.DeepEqual(a.%s, b.%s)", name, name)
+			case g.equatable[field.Type.Name]:
+				conds[i] = fmt.Sprintf("a.%s.Equals(b.%s)", name, name)
+			default:
+				conds[i] = fmt.Sprintf("a.%s == b.%s", name, name)
+			}
+		}
+		g.emit("return %s", strings.Join(conds, " && "))
 	}
 	g.indent--
 	g.emit("}")
@@ -65,56 +532,114 @@ func (g *Generator) generateStruct(st *ir.Struct) {
 // generateFunction генерирует функцию на Go.
 func (g *Generator) generateFunction(fn *ir.Function) {
 	// Сигнатура функции
+	g.pos = fn.Pos
 	params := g.generateParams(fn.Params)
 	var returnType string
-	if fn.ReturnType != nil && fn.ReturnType.Name != "" && fn.ReturnType.Name != "()" {
+	if !fn.ReturnType.IsUnit() {
 		returnType = fmt.Sprintf(" %s", fn.ReturnType.String())
 	}
 
-	g.emit("func %s(%s)%s {", fn.Name, params, returnType)
-	g.indent++
-
-	// Проверяем, есть ли явный return
-	hasReturn := false
-	for _, stmt := range fn.Body {
-		if _, ok := stmt.(*ir.Return); ok {
-			hasReturn = true
-			break
+	name := g.funcNames[fn.QualifiedName()]
+	if fn.GoReceiver != "" {
+		name = g.methodNames[receiverType(fn)][fn.Name]
+	}
+	if len(fn.TypeParams) > 0 {
+		params := make([]string, 0, len(fn.TypeParams))
+		for _, param := range fn.TypeParams {
+			params = append(params, param+" "+g.constraint(fn.Bounds[param]))
 		}
+		name += "[" + strings.Join(params, ", ") + "]"
 	}
+	if fn.GoReceiver != "" {
+		g.emit("func (%s) %s(%s)%s {", fn.GoReceiver, name, params, returnType)
+	} else {
+		g.emit("func %s(%s)%s {", name, params, returnType)
+	}
+	g.indent++
+	g.fn = fn
+	defer func() { g.fn = nil }()
 
-	// Генерируем тело функции
-	for i, stmt := range fn.Body {
-		// Если это последний ExprStmt в функции с возвращаемым значением,
-		// и нет явного return, преобразуем его в return
-		isLastStmt := i == len(fn.Body)-1
-		if !hasReturn && isLastStmt && fn.ReturnType != nil && fn.ReturnType.Name != "" && fn.ReturnType.Name != "()" {
-			if exprStmt, ok := stmt.(*ir.ExprStmt); ok {
-				exprStr := g.generateExpression(exprStmt.Expr)
-				if exprStr != "" {
-					g.emit("return %s", exprStr)
-					g.indent--
-					g.emit("}")
-					return
-				}
-			}
+	g.generateBody(fn.Body, fn.ReturnType)
+
+	g.indent--
+	g.emit("}")
+}
+
+// generateBody генерирует тело функции или замыкания, возвращающего returnType.
+// Значение хвостового выражения возвращается через return. Функция, возвращающая (),
+// в том числе main, вычисляет хвостовое выражение как оператор, даже если его тип не ().
+func (g *Generator) generateBody(body []ir.Statement, returnType *ir.Type) {
+	target := ""
+	if !returnType.IsUnit() {
+		target = "return"
+	}
+	g.generateBlockValue(body, target)
+	// Тело без хвостового выражения: Go требует завершающий return
+	if target == "return" && !terminates(body) {
+		if returnType.IsResult {
+			g.emit("%s", g.generateReturn(nil, nil))
+		} else {
+			g.emit("return %s", g.zeroValue(returnType))
 		}
-		g.generateStatement(stmt)
 	}
+}
 
-	// Если нет явного return и функция не void, добавляем пустой return
-	if fn.ReturnType != nil && fn.ReturnType.Name != "" && fn.ReturnType.Name != "()" && !hasReturn {
-		// Проверяем, не добавили ли мы уже return выше
-		if len(fn.Body) == 0 || len(fn.Body) > 0 {
-			lastStmt := fn.Body[len(fn.Body)-1]
-			if _, ok := lastStmt.(*ir.ExprStmt); !ok {
-				g.emit("return // TODO: add return value")
-			}
-		}
+// generateClosure генерирует функциональный литерал Go для замыкания. Тело выводится
+// в отдельный буфер с отступом на уровень глубже текущего оператора, который затем
+// выводит литерал целиком; нумерация строк для PositionMap продолжается с его строки.
+func (g *Generator) generateClosure(c *ir.ClosureExpr) string {
+	sig := "func(" + g.generateParams(c.Params) + ")"
+	if !c.ReturnType.IsUnit() {
+		sig += " " + c.ReturnType.String()
 	}
 
+	savedBuilder, savedLine, savedFn := g.builder, g.line, g.fn
+	g.builder = strings.Builder{}
+	g.line++ // Тело начинается после строки оператора, содержащего литерал
+	closureFn := &ir.Function{ReturnType: c.ReturnType}
+	if savedFn != nil {
+		closureFn.TypeParams = savedFn.TypeParams
+	}
+	g.fn = closureFn
+	g.indent++
+	g.generateBody(c.Body, c.ReturnType)
 	g.indent--
-	g.emit("}")
+	body := g.builder.String()
+	g.builder, g.line, g.fn = savedBuilder, savedLine, savedFn
+
+	return sig + " {\n" + body + strings.Repeat("\t", g.indent) + "}"
+}
+
+// terminates сообщает, передаёт ли последний оператор блока значение в target
+// "return" на всех путях: хвостовое выражение, return, match или if с обеими
+// завершающимися ветвями.
+func terminates(stmts []ir.Statement) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	switch s := stmts[len(stmts)-1].(type) {
+	case *ir.Return:
+		return true
+	case *ir.ExprStmt:
+		return s.Expr != nil && terminatesExpr(s.Expr)
+	case *ir.If, *ir.Block:
+		return terminatesExpr(s.(ir.Expression))
+	}
+	return false
+}
+
+// terminatesExpr — вариант terminates для хвостового выражения.
+func terminatesExpr(expr ir.Expression) bool {
+	switch e := expr.(type) {
+	case *ir.MatchExpr:
+		// switch без неопровержимой ветви получает default с panic (см. generateMatch)
+		return true
+	case *ir.If:
+		return terminates(e.Then) && terminates(e.Else)
+	case *ir.Block:
+		return terminates(e.Stmts)
+	}
+	return true
 }
 
 // generateParams генерирует список параметров.
@@ -131,9 +656,36 @@ func (g *Generator) generateParams(params []*ir.Parameter) string {
 }
 
 // generateStatement генерирует оператор Go.
+// Строки оператора, включая закрывающие скобки, относятся к его позиции.
 func (g *Generator) generateStatement(stmt ir.Statement) {
+	defer g.at(stmt.Pos())()
 	switch s := stmt.(type) {
 	case *ir.Declaration:
+		// match, if или блок как значение: объявляем переменную и присваиваем её в каждой ветви
+		if isBranching(s.InitValue) {
+			declType := s.Type
+			if declType == nil || declType.Name == "" || declType.Name == "infer" {
+				declType = s.InitValue.Type()
+			}
+			if declType != nil {
+				g.emit("var %s %s", s.Name, declType.String())
+				g.generateValue(s.InitValue, s.Name)
+				return
+			}
+		}
+		// nil не имеет типа в Go: `let o: Option<i32> = None` объявляется как `var o *int`
+		if lit, ok := s.InitValue.(*ir.LiteralExpr); ok && lit.Kind == "NIL" && s.Type != nil && s.Type.IsPointer {
+			g.emit("var %s %s", s.Name, s.Type.String())
+			return
+		}
+		// Нетипизированная константа получила бы в Go тип по умолчанию:
+		// `let f: f32 = 1.5` объявляется как `var f float32 = 1.5`
+		if kind := untypedConst(s.InitValue); kind != "" && s.Type != nil && s.Type.Name != "" && s.Type.Name != "infer" {
+			if typ := s.Type.String(); !(kind == "INT" && typ == "int" || kind == "FLOAT" && typ == "float64") {
+				g.emit("var %s %s = %s", s.Name, typ, g.generateExpression(s.InitValue))
+				return
+			}
+		}
 		// Упрощённая генерация: используем :=
 		exprStr := g.generateExpression(s.InitValue)
 		if exprStr != "" {
@@ -142,19 +694,459 @@ func (g *Generator) generateStatement(stmt ir.Statement) {
 			g.emit("var %s %s", s.Name, s.Type.String())
 		}
 	case *ir.Assignment:
-		g.emit("%s = %s", s.Target, g.generateExpression(s.Value))
-	case *ir.Return:
-		if s.Value != nil {
-			g.emit("return %s", g.generateExpression(s.Value))
+		if isBranching(s.Value) {
+			g.generateValue(s.Value, g.generateExpression(s.Target))
+			return
+		}
+		g.emit("%s = %s", g.generateExpression(s.Target), g.generateExpression(s.Value))
+	case *ir.MultiDeclaration:
+		if len(s.Values) > 0 {
+			values := make([]string, 0, len(s.Values))
+			for _, value := range s.Values {
+				values = append(values, g.generateExpression(value))
+			}
+			g.emit("%s := %s", strings.Join(s.Names, ", "), strings.Join(values, ", "))
+			return
+		}
+		g.emit("%s := %s", strings.Join(s.Names, ", "), g.generateExpression(s.InitValue))
+	case *ir.If, *ir.Block:
+		g.generateValue(s.(ir.Expression), "")
+	case *ir.While:
+		g.emitLabel(s.Label)
+		if s.Cond == nil {
+			g.emit("for {")
 		} else {
-			g.emit("return")
+			g.emit("for %s {", g.generateExpression(s.Cond))
 		}
+		g.generateBlock(s.Body)
+		g.emit("}")
+	case *ir.For:
+		g.emitLabel(s.Label)
+		g.generateFor(s)
+	case *ir.Break:
+		g.emit("%s", strings.TrimSpace("break "+goLabel(s.Label)))
+	case *ir.Continue:
+		g.emit("%s", strings.TrimSpace("continue "+goLabel(s.Label)))
+	case *ir.Return:
+		g.emit("%s", g.generateReturn(s.Value, s.Err))
 	case *ir.ExprStmt:
+		if match, ok := s.Expr.(*ir.MatchExpr); ok {
+			g.generateMatch(match, "")
+			return
+		}
+		if call, ok := s.Expr.(*ir.CallExpr); ok && call.IsMacro && g.generateAssert(call) {
+			return
+		}
 		exprStr := g.generateExpression(s.Expr)
 		g.emit("%s", exprStr)
 	}
 }
 
+// generateIf генерирует условный оператор Go. Если ветка else состоит
+// из единственного If, она выводится цепочкой `} else if cond {`.
+// Значения хвостовых выражений ветвей передаются в target (см. generateMatch).
+func (g *Generator) generateIf(s *ir.If, target string) {
+	g.emit("if %s {", g.generateExpression(s.Cond))
+	for {
+		g.indent++
+		g.generateBlockValue(s.Then, target)
+		g.indent--
+		if len(s.Else) == 0 {
+			break
+		}
+		if next, ok := s.Else[0].(*ir.If); ok && len(s.Else) == 1 {
+			g.emit("} else if %s {", g.generateExpression(next.Cond))
+			s = next
+			continue
+		}
+		g.emit("} else {")
+		g.indent++
+		g.generateBlockValue(s.Else, target)
+		g.indent--
+		break
+	}
+	g.emit("}")
+}
+
+// isBranching сообщает, является ли выражение match, if или блоком:
+// в Go они не являются выражениями и раскрываются в операторы.
+func isBranching(expr ir.Expression) bool {
+	switch expr.(type) {
+	case *ir.MatchExpr, *ir.If, *ir.Block:
+		return true
+	}
+	return false
+}
+
+// generateBlockValue генерирует операторы блока. Хвостовой оператор передаёт
+// своё значение в target: "" — значение не используется, "return" — возвращается
+// из функции, иначе — присваивается переменной target.
+func (g *Generator) generateBlockValue(stmts []ir.Statement, target string) {
+	for i, stmt := range stmts {
+		if i < len(stmts)-1 || target == "" {
+			g.generateStatement(stmt)
+			continue
+		}
+		switch s := stmt.(type) {
+		case *ir.ExprStmt:
+			g.generateValue(s.Expr, target)
+		case *ir.If, *ir.Block:
+			g.generateValue(s.(ir.Expression), target)
+		default:
+			g.generateStatement(stmt)
+		}
+	}
+}
+
+// generateValue генерирует вычисление выражения, значение которого передаётся в target.
+// match, if и блоки раскрываются так, что значение передаёт каждая их ветвь.
+func (g *Generator) generateValue(expr ir.Expression, target string) {
+	if expr != nil {
+		defer g.at(expr.Pos())()
+	}
+	switch e := expr.(type) {
+	case *ir.MatchExpr:
+		g.generateMatch(e, target)
+		return
+	case *ir.If:
+		g.generateIf(e, target)
+		return
+	case *ir.Block:
+		g.emit("{")
+		g.indent++
+		g.generateBlockValue(e.Stmts, target)
+		g.indent--
+		g.emit("}")
+		return
+	}
+
+	if target == "return" && !ir.IsDiverging(expr) {
+		// Ok(v) / Err(e) в функции, возвращающей Result
+		if ret, ok := g.generateResultReturn(expr); ok {
+			g.emit("%s", ret)
+			return
+		}
+	}
+	code := g.generateExpression(expr)
+	switch {
+	case code == "":
+	case ir.IsDiverging(expr) || target == "":
+		g.emit("%s", code)
+	case target == "return":
+		g.emit("return %s", code)
+	default:
+		g.emit("%s = %s", target, code)
+	}
+}
+
+// generateBlock генерирует операторы тела с увеличенным отступом.
+func (g *Generator) generateBlock(stmts []ir.Statement) {
+	g.indent++
+	for _, stmt := range stmts {
+		g.generateStatement(stmt)
+	}
+	g.indent--
+}
+
+// emitLabel выводит метку Go перед циклом с меткой Rust label.
+func (g *Generator) emitLabel(label string) {
+	if label != "" {
+		g.emit("%s:", goLabel(label))
+	}
+}
+
+// goLabel переводит метку цикла Rust (`'outer`) в метку Go (`outer`).
+// Метки, совпадающие с ключевыми словами Go (`'range`), получают суффикс `_`.
+func goLabel(label string) string {
+	name := strings.TrimPrefix(label, "'")
+	if gotoken.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// generateFor генерирует цикл for. Диапазон `start..end` становится
+// счётным циклом `for i := start; i < end; i++`, обход коллекции — `for _, x := range xs`.
+// Цикл `for _ in 0..n` без переменной становится `for range n`.
+func (g *Generator) generateFor(s *ir.For) {
+	if r, ok := s.Iter.(*ir.RangeExpr); ok {
+		start := g.generateExpression(r.Start)
+		if s.Var == "_" && start == "0" && !r.Inclusive {
+			g.emit("for range %s {", g.generateExpression(r.End))
+			g.generateBlock(s.Body)
+			g.emit("}")
+			return
+		}
+		// Литерал начала получает тип диапазона: `for i := int64(0); i < n; i++`
+		if lit, ok := r.Start.(*ir.LiteralExpr); ok && r.TypeInfo != nil && lit.TypeInfo != nil && lit.TypeInfo.String() != r.TypeInfo.String() {
+			start = fmt.Sprintf("%s(%s)", r.TypeInfo.String(), start)
+		}
+		name := s.Var
+		if name == "_" {
+			name = "i_"
+		}
+		cmp := "<"
+		if r.Inclusive {
+			cmp = "<="
+		}
+		g.emit("for %s := %s; %s %s %s; %s++ {",
+			name, start, name, cmp, g.generateExpression(r.End), name)
+	} else {
+		g.emit("for _, %s := range %s {", s.Var, g.generateExpression(s.Iter))
+	}
+	g.generateBlock(s.Body)
+	g.emit("}")
+}
+
+// generateReturn формирует оператор return.
+// В функции, возвращающей Result (в Go — (T, error)), возвращаются значение и ошибка;
+// отсутствующее значение заменяется нулевым, отсутствующая ошибка — nil.
+func (g *Generator) generateReturn(value, err ir.Expression) string {
+	if g.fn == nil || g.fn.ReturnType == nil || !g.fn.ReturnType.IsResult {
+		if value != nil {
+			return "return " + g.generateExpression(value)
+		}
+		return "return"
+	}
+
+	parts := []string{}
+	if valueType := g.fn.ReturnType.ElementType; !valueType.IsUnit() {
+		if value != nil {
+			parts = append(parts, g.generateExpression(value))
+		} else {
+			parts = append(parts, g.zeroValue(valueType))
+		}
+	}
+	if err != nil {
+		parts = append(parts, g.generateError(err))
+	} else {
+		parts = append(parts, "nil")
+	}
+	return "return " + strings.Join(parts, ", ")
+}
+
+// generateError приводит значение Err(e) к типу error: строка становится
+// `errors.New(e)`, остальные значения — `fmt.Errorf("%v", e)`.
+func (g *Generator) generateError(err ir.Expression) string {
+	if typ := err.Type(); typ != nil && !typ.IsPointer {
+		switch typ.Name {
+		case "error":
+			return g.generateExpression(err)
+		case "string":
+			g.use("errors")
+			return fmt.Sprintf("errors.New(%s)", g.generateExpression(err))
+		}
+	}
+	g.use("fmt")
+	return fmt.Sprintf("fmt.Errorf(\"%%v\", %s)", g.generateExpression(err))
+}
+
+// generateResultReturn понижает `Ok(v)` и `Err(e)` в return функции, возвращающей Result.
+// Возвращает false, если expr не является таким конструктором.
+func (g *Generator) generateResultReturn(expr ir.Expression) (string, bool) {
+	call, ok := expr.(*ir.CallExpr)
+	if !ok || len(call.Args) != 1 || g.fn == nil || g.fn.ReturnType == nil || !g.fn.ReturnType.IsResult {
+		return "", false
+	}
+	switch call.FuncName {
+	case "Ok":
+		return g.generateReturn(call.Args[0], nil), true
+	case "Err":
+		return g.generateReturn(nil, call.Args[0]), true
+	}
+	return "", false
+}
+
+// zeroValue возвращает нулевое значение типа Go.
+func (g *Generator) zeroValue(t *ir.Type) string {
+	switch {
+	case t == nil, t.IsPointer, t.IsArray, t.IsMap, t.IsResult, t.IsFunc:
+		return "nil"
+	case t.IsTuple:
+		return t.Name + "{}"
+	}
+	switch t.Name {
+	case "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "0"
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "interface{}", "error":
+		return "nil"
+	}
+	if g.fn != nil {
+		for _, param := range g.fn.TypeParams {
+			if param == t.Name {
+				return "*new(" + t.Name + ")"
+			}
+		}
+	}
+	// Пользовательские структуры
+	return t.Name + "{}"
+}
+
+// constraint возвращает ограничение Go для параметра типа с трейтами bounds.
+// Сравнение на порядок (Ord, PartialOrd) переводится в cmp.Ordered, на равенство
+// (Eq, PartialEq, Hash) — в comparable; остальные трейты (Clone, Copy, Debug, ...)
+// ничего не требуют от типа Go, и используется any.
+func (g *Generator) constraint(bounds []string) string {
+	result := "any"
+	for _, bound := range bounds {
+		switch bound {
+		case "Ord", "PartialOrd":
+			g.use("cmp")
+			return "cmp.Ordered"
+		case "Eq", "PartialEq", "Hash":
+			result = "comparable"
+		}
+	}
+	return result
+}
+
+// generateMatch генерирует switch для выражения match. Если все ветви, кроме
+// последней неопровержимой, сопоставляют литералы, генерируется switch с тегом
+// (`switch x { case 1: ... default: ... }`); иначе образцы понижаются
+// до условий сравнения и привязок переменных в switch без тега.
+//
+// target определяет, куда попадает значение ветви: "" — значение вычисляется
+// как оператор, "return" — возвращается из функции, иначе — присваивается
+// переменной target.
+func (g *Generator) generateMatch(m *ir.MatchExpr, target string) {
+	subject := g.generateExpression(m.Scrutinee)
+	tagged := isLiteralMatch(m)
+	switch lit, ok := m.Scrutinee.(*ir.LiteralExpr); {
+	case ok && lit.Kind == "IDENT" && tagged:
+		g.emit("switch %s {", subject)
+	case ok && lit.Kind == "IDENT":
+		g.emit("switch {")
+	case tagged:
+		// Сопоставляемое выражение вычисляется один раз
+		g.emit("switch matchValue := %s; matchValue {", subject)
+		subject = "matchValue"
+	default:
+		g.emit("switch matchValue := %s; {", subject)
+		subject = "matchValue"
+	}
+
+	hasDefault := false
+	for i, arm := range m.Arms {
+		conds, binds := g.lowerPattern(arm.Pattern, subject)
+		switch {
+		case tagged && len(conds) > 0:
+			g.emit("case %s:", g.caseValue(arm.Pattern))
+		case len(conds) > 0:
+			g.emit("case %s:", strings.Join(conds, " && "))
+		case i == len(m.Arms)-1:
+			g.emit("default:")
+			hasDefault = true
+		default:
+			// Неопровержимый образец не в последней ветви: порядок ветвей важен
+			g.emit("case true:")
+		}
+
+		g.indent++
+		for _, bind := range binds {
+			g.emit("%s := %s", bind[0], bind[1])
+		}
+		// Блок ветви уже ограничен case, дополнительные скобки не нужны
+		if block, ok := arm.Body.(*ir.Block); ok {
+			g.generateBlockValue(block.Stmts, target)
+		} else {
+			g.generateValue(arm.Body, target)
+		}
+		g.indent--
+	}
+	// Ветви match в Rust исчерпывающие: недостижимый default делает switch полным,
+	// и Go считает его завершающим оператором, даже если match — значение функции
+	if !hasDefault {
+		g.emit("default:")
+		g.indent++
+		g.emit("panic(\"unreachable\")")
+		g.indent--
+	}
+	g.emit("}")
+}
+
+// caseValue возвращает значение case для образца switch с тегом: литерал или вариант перечисления.
+func (g *Generator) caseValue(pat ir.Pattern) string {
+	if v, ok := pat.(*ir.VariantPattern); ok {
+		return variantName(v.Enum, v.Variant)
+	}
+	lit := pat.(*ir.LiteralPattern)
+	return g.generateExpression(&ir.LiteralExpr{Value: lit.Value, Kind: lit.Kind})
+}
+
+// isLiteralMatch сообщает, можно ли сгенерировать match как switch с тегом:
+// все ветви сопоставляют литералы или варианты перечисления, кроме, возможно,
+// последней неопровержимой.
+func isLiteralMatch(m *ir.MatchExpr) bool {
+	for i, arm := range m.Arms {
+		switch arm.Pattern.(type) {
+		case *ir.LiteralPattern, *ir.VariantPattern:
+		case *ir.WildcardPattern, *ir.BindingPattern:
+			if i != len(m.Arms)-1 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// lowerPattern понижает образец, применённый к выражению path, до списка условий
+// и списка привязок вида {имя, выражение}.
+func (g *Generator) lowerPattern(pat ir.Pattern, path string) ([]string, [][2]string) {
+	switch p := pat.(type) {
+	case *ir.BindingPattern:
+		return nil, [][2]string{{p.Name, path}}
+	case *ir.LiteralPattern:
+		value := g.generateExpression(&ir.LiteralExpr{Value: p.Value, Kind: p.Kind})
+		return []string{fmt.Sprintf("%s == %s", path, value)}, nil
+	case *ir.VariantPattern:
+		return []string{fmt.Sprintf("%s == %s", path, variantName(p.Enum, p.Variant))}, nil
+	case *ir.TuplePattern:
+		var conds []string
+		var binds [][2]string
+		for i, elem := range p.Elems {
+			elemConds, elemBinds := g.lowerPattern(elem, path+"."+ir.TupleFieldName(i))
+			conds = append(conds, elemConds...)
+			binds = append(binds, elemBinds...)
+		}
+		return conds, binds
+	case *ir.StructPattern:
+		var conds []string
+		var binds [][2]string
+		for _, f := range p.Fields {
+			field := g.fieldName(f.Name, true)
+			if name, ok := g.fieldNames[p.Name][f.Name]; ok {
+				field = name
+			}
+			fieldConds, fieldBinds := g.lowerPattern(f.Pattern, path+"."+field)
+			conds = append(conds, fieldConds...)
+			binds = append(binds, fieldBinds...)
+		}
+		return conds, binds
+	case *ir.WildcardPattern:
+		return nil, nil
+	}
+	// Неизвестный образец нельзя считать неопровержимым: ветвь перехватила бы все значения.
+	// Семантический анализ отвергает такие образцы до генерации
+	panic(fmt.Sprintf("backend: unsupported pattern %T", pat))
+}
+
+// stringMethods сопоставляет методы String функциям пакета strings:
+// `s.contains(x)` становится `strings.Contains(s, x)`.
+var stringMethods = map[string]string{
+	"contains":     "Contains",
+	"starts_with":  "HasPrefix",
+	"ends_with":    "HasSuffix",
+	"to_uppercase": "ToUpper",
+	"to_lowercase": "ToLower",
+	"trim":         "TrimSpace",
+}
+
 // generateExpression генерирует выражение Go.
 func (g *Generator) generateExpression(expr ir.Expression) string {
 	if expr == nil {
@@ -165,30 +1157,109 @@ func (g *Generator) generateExpression(expr ir.Expression) string {
 	case *ir.VarExpr:
 		return e.Name
 	case *ir.LiteralExpr:
-		// Для строк добавляем кавычки, но убираем существующие из Value
+		// Строки декодируются и заново экранируются по правилам Go
 		if e.Kind == "STRING" {
-			val := strings.Trim(e.Value, `"`)
-			return fmt.Sprintf(`"%s"`, val)
+			if val, err := lexer.Unquote(e.Value); err == nil {
+				return strconv.Quote(val)
+			}
+		}
+		// Литерал с суффиксом имеет тип суффикса, а константа Go получила бы тип
+		// по умолчанию: `5u8` становится `uint8(5)`, а `5i32` остаётся `5`
+		if e.Suffix != "" && e.TypeInfo != nil {
+			if typ := e.TypeInfo.String(); !(e.Kind == "INT" && typ == "int" || e.Kind == "FLOAT" && typ == "float64") {
+				return fmt.Sprintf("%s(%s)", typ, e.Value)
+			}
 		}
 		return e.Value
+	case *ir.CastExpr:
+		return g.generateCast(e)
 	case *ir.BinaryExpr:
 		left := g.generateExpression(e.Left)
 		right := g.generateExpression(e.Right)
 		if left == "" || right == "" {
 			return ""
 		}
-		// Специальная обработка для println! макросов
-		if e.Op == "," && isPrintlnMacro(left) {
-			args := g.extractPrintlnArgs(e.Right)
-			return g.generatePrintlnCall(args)
+		// Дерево IR уже задаёт порядок вычисления: скобки нужны, только если
+		// приоритет операнда ниже приоритета оператора по правилам Go
+		if needsParens(e.Left, e.Op, false) {
+			left = "(" + left + ")"
 		}
-		return fmt.Sprintf("(%s %s %s)", left, e.Op, right)
+		if needsParens(e.Right, e.Op, true) {
+			right = "(" + right + ")"
+		}
+		return fmt.Sprintf("%s %s %s", left, e.Op, right)
 	case *ir.UnaryExpr:
-		exprStr := g.generateExpression(e.Expr)
+		exprStr := g.generateOperand(e.Expr)
 		if exprStr == "" {
 			return ""
 		}
 		return fmt.Sprintf("%s%s", e.Op, exprStr)
+	case *ir.UnwrapExpr:
+		return g.generateUnwrap(e)
+	case *ir.SomeExpr:
+		return g.generateSome(e)
+	case *ir.ClosureExpr:
+		return g.generateClosure(e)
+	case *ir.VariantExpr:
+		return variantName(e.Enum, e.Variant)
+	case *ir.MethodCall:
+		args := []string{}
+		for _, arg := range e.Args {
+			args = append(args, g.generateExpression(arg))
+		}
+		if fn, ok := stringMethods[e.Method]; ok && typeName(e.Receiver.Type()) == "string" {
+			g.use("strings")
+			args = append([]string{g.generateExpression(e.Receiver)}, args...)
+			return fmt.Sprintf("strings.%s(%s)", fn, strings.Join(args, ", "))
+		}
+		method := e.Method
+		if name, ok := g.methodNames[typeName(e.Receiver.Type())][e.Method]; ok {
+			method = name
+		}
+		return fmt.Sprintf("%s.%s(%s)", g.generateOperand(e.Receiver), method, strings.Join(args, ", "))
+	case *ir.FieldExpr:
+		field := g.fieldName(e.Field, true)
+		if name, ok := g.fieldNames[typeName(e.Receiver.Type())][e.Field]; ok {
+			field = name
+		}
+		return fmt.Sprintf("%s.%s", g.generateOperand(e.Receiver), field)
+	case *ir.IndexExpr:
+		return fmt.Sprintf("%s[%s]", g.generateOperand(e.Expr), g.generateExpression(e.Index))
+	case *ir.TupleExpr:
+		elems := []string{}
+		for _, elem := range e.Elems {
+			elems = append(elems, g.generateExpression(elem))
+		}
+		return fmt.Sprintf("%s{%s}", e.TypeInfo.String(), strings.Join(elems, ", "))
+	case *ir.ArrayLit:
+		elems := []string{}
+		for _, elem := range e.Elems {
+			elems = append(elems, g.generateExpression(elem))
+		}
+		return fmt.Sprintf("%s{%s}", e.TypeInfo.String(), strings.Join(elems, ", "))
+	case *ir.ArrayRepeat:
+		length := g.generateExpression(e.Len)
+		// Нулевое значение даёт сам make; иначе срез заполняется вспомогательной функцией
+		if isZeroLiteral(e.Value) {
+			return fmt.Sprintf("make(%s, %s)", e.TypeInfo.String(), length)
+		}
+		g.helpers[repeatHelper] = true
+		// Тип элементов, отличный от типа значения (`let v: Vec<i64> = vec![1; n]`),
+		// передаётся явно: иначе Go выведет его из нетипизированной константы
+		if elem := e.TypeInfo.ElementType; elem != nil && e.Value.Type() != nil && elem.String() != e.Value.Type().String() {
+			return fmt.Sprintf("%s[%s](%s, %s)", repeatHelper, elem.String(), g.generateExpression(e.Value), length)
+		}
+		return fmt.Sprintf("%s(%s, %s)", repeatHelper, g.generateExpression(e.Value), length)
+	case *ir.StructLit:
+		fields := []string{}
+		for _, field := range e.Fields {
+			name, ok := g.fieldNames[e.Name][field.Name]
+			if !ok {
+				name = capitalize(field.Name)
+			}
+			fields = append(fields, fmt.Sprintf("%s: %s", name, g.generateExpression(field.Value)))
+		}
+		return fmt.Sprintf("%s{%s}", e.Name, strings.Join(fields, ", "))
 	case *ir.CallExpr:
 		// Обрабатываем макросы
 		if e.IsMacro {
@@ -198,6 +1269,9 @@ func (g *Generator) generateExpression(expr ir.Expression) string {
 			if e.FuncName == "format!" {
 				return g.generateFormatCall(e.Args)
 			}
+			if msg, ok := panicMessages[e.FuncName]; ok {
+				return fmt.Sprintf("panic(%s)", g.generatePanicMessage(e.Args, msg))
+			}
 			// Для других макросов пока возвращаем TODO
 			return fmt.Sprintf("// TODO: macro %s", e.FuncName)
 		}
@@ -209,13 +1283,141 @@ func (g *Generator) generateExpression(expr ir.Expression) string {
 				args = append(args, argStr)
 			}
 		}
-		return fmt.Sprintf("%s(%s)", e.FuncName, strings.Join(args, ", "))
+		funcName := e.FuncName
+		if name, ok := g.funcNames[e.FuncName]; ok {
+			funcName = name
+		}
+		return fmt.Sprintf("%s(%s)", funcName, strings.Join(args, ", "))
+	}
+	return ""
+}
+
+// generateCast генерирует приведение `expr as T` как явное преобразование Go `T(expr)`.
+// bool в Go в число не преобразуется, поэтому значение сначала проходит через
+// вспомогательную функцию. Приведение к unit-типу не меняет выражение.
+func (g *Generator) generateCast(e *ir.CastExpr) string {
+	inner := g.generateExpression(e.Expr)
+	if inner == "" || e.TypeInfo == nil || e.TypeInfo.IsUnit() {
+		return inner
+	}
+	if src := e.Expr.Type(); src != nil && src.Name == "bool" && e.TypeInfo.Name != "bool" {
+		g.helpers[boolToIntHelper] = true
+		inner = fmt.Sprintf("%s(%s)", boolToIntHelper, inner)
+	}
+	target := e.TypeInfo.String()
+	// `*T(x)` разбирается как разыменование, `func() T(x)` — как литерал функции
+	if strings.HasPrefix(target, "*") || strings.HasPrefix(target, "func") {
+		target = "(" + target + ")"
+	}
+	return fmt.Sprintf("%s(%s)", target, inner)
+}
+
+// untypedConst возвращает "INT" или "FLOAT", если выражение составлено только из
+// числовых литералов без суффикса одного вида (`1`, `-2.5`, `(1 + 2) * 3`), иначе "".
+func untypedConst(expr ir.Expression) string {
+	switch e := expr.(type) {
+	case *ir.LiteralExpr:
+		if e.Suffix == "" && (e.Kind == "INT" || e.Kind == "FLOAT") {
+			return e.Kind
+		}
+	case *ir.UnaryExpr:
+		if e.Op == "-" {
+			return untypedConst(e.Expr)
+		}
+	case *ir.BinaryExpr:
+		if kind := untypedConst(e.Left); kind == untypedConst(e.Right) {
+			switch e.Op {
+			case "+", "-", "*", "/", "%":
+				return kind
+			}
+		}
 	}
 	return ""
 }
 
+// generateOperand генерирует операнд унарного оператора, селектора или индексирования.
+// Бинарные и унарные выражения заключаются в скобки: `(a + b).f`, `(*p).x`, `-(-x)`.
+func (g *Generator) generateOperand(expr ir.Expression) string {
+	code := g.generateExpression(expr)
+	switch expr.(type) {
+	case *ir.BinaryExpr, *ir.UnaryExpr:
+		if code != "" {
+			return "(" + code + ")"
+		}
+	}
+	return code
+}
+
+// needsParens сообщает, нужно ли заключить операнд бинарного оператора op в скобки.
+// Бинарные операторы Go левоассоциативны, поэтому правый операнд с тем же
+// приоритетом тоже требует скобок: `a - (b - c)`.
+func needsParens(operand ir.Expression, op string, right bool) bool {
+	inner, ok := operand.(*ir.BinaryExpr)
+	if !ok {
+		return false
+	}
+	innerPrec, outerPrec := precedence(inner.Op), precedence(op)
+	return innerPrec < outerPrec || (right && innerPrec == outerPrec)
+}
+
+// precedence возвращает приоритет бинарного оператора Go (5 — наивысший).
+func precedence(op string) int {
+	switch op {
+	case "*", "/", "%", "<<", ">>", "&", "&^":
+		return 5
+	case "+", "-", "|", "^":
+		return 4
+	case "==", "!=", "<", "<=", ">", ">=":
+		return 3
+	case "&&":
+		return 2
+	case "||":
+		return 1
+	}
+	return 0
+}
+
+// generateUnwrap генерирует вызов вспомогательной функции, паникующей на None.
+// Сообщение совпадает с сообщением Rust; при включённом PanicLocations
+// к нему добавляется место вызова в исходном файле.
+func (g *Generator) generateUnwrap(e *ir.UnwrapExpr) string {
+	g.helpers[unwrapHelper] = true
+
+	msg := `"called ` + "`Option::unwrap()`" + ` on a ` + "`None`" + ` value"`
+	if e.Method == "expect" && e.Message != nil {
+		msg = g.generateExpression(e.Message)
+	}
+	if g.PanicLocations {
+		msg = fmt.Sprintf(`%s + " at %s:%d:%d"`, msg, g.SourceFile, e.Position.Line, e.Position.Col)
+	}
+	return fmt.Sprintf("%s(%s, %s)", unwrapHelper, g.generateExpression(e.Expr), msg)
+}
+
+// generateSome генерирует `Some(value)` как адрес копии значения: `rustSome(x)`.
+// Если объявленный тип *T отличается от типа литерала (`let o: Option<i64> = Some(1)`),
+// параметр типа указывается явно: `rustSome[int64](1)`.
+func (g *Generator) generateSome(e *ir.SomeExpr) string {
+	g.helpers[someHelper] = true
+	value := g.generateExpression(e.Value)
+	if _, ok := e.Value.(*ir.LiteralExpr); ok && e.TypeInfo != nil && e.TypeInfo.ElementType != nil {
+		if valueType := e.Value.Type(); valueType == nil || valueType.String() != e.TypeInfo.ElementType.String() {
+			return fmt.Sprintf("%s[%s](%s)", someHelper, e.TypeInfo.ElementType.String(), value)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", someHelper, value)
+}
+
 // generatePrintlnCall генерирует вызов fmt.Println.
+// Если первый аргумент — строковый литерал с плейсхолдерами `{}`/`{:?}`,
+// генерируется fmt.Printf с преобразованными глаголами и завершающим переводом строки.
 func (g *Generator) generatePrintlnCall(args []ir.Expression) string {
+	g.use("fmt")
+	if format, fmtArgs, ok := g.translateFormatArgs(args); ok && len(fmtArgs) > 0 {
+		return fmt.Sprintf("fmt.Printf(%s)", strings.Join(append([]string{strconv.Quote(format + "\n")}, fmtArgs...), ", "))
+	} else if ok {
+		return fmt.Sprintf("fmt.Println(%s)", strconv.Quote(plainText(format)))
+	}
+
 	argStrs := []string{}
 	for _, arg := range args {
 		argStrs = append(argStrs, g.generateExpression(arg))
@@ -223,11 +1425,159 @@ func (g *Generator) generatePrintlnCall(args []ir.Expression) string {
 	return fmt.Sprintf("fmt.Println(%s)", strings.Join(argStrs, ", "))
 }
 
+// translateFormatArgs преобразует аргументы макроса форматирования Rust в аргументы fmt.Printf.
+// Возвращает декодированную строку формата Go (без кавычек и экранирования) и аргументы по порядку плейсхолдеров.
+// ok == false, если первый аргумент не строковый литерал или строка формата некорректна.
+func (g *Generator) translateFormatArgs(args []ir.Expression) (string, []string, bool) {
+	if len(args) == 0 {
+		return "", nil, false
+	}
+	lit, isLit := args[0].(*ir.LiteralExpr)
+	if !isLit || lit.Kind != "STRING" {
+		return "", nil, false
+	}
+
+	positional := []string{}
+	for _, arg := range args[1:] {
+		positional = append(positional, g.generateExpression(arg))
+	}
+	format, err := lexer.Unquote(lit.Value)
+	if err != nil {
+		return "", nil, false
+	}
+	return translateFormatString(format, positional)
+}
+
+// translateFormatString заменяет плейсхолдеры Rust глаголами fmt (см. formatVerb):
+// `{}` -> `%v`, `{:?}` -> `%+v`, `{:x}` -> `%x`. Поддерживаются позиционные (`{0}`) и именованные (`{name}`)
+// плейсхолдеры, а также экранирование `{{`/`}}`; символ `%` экранируется как `%%`.
+// Возвращает строку формата и аргументы в порядке плейсхолдеров.
+func translateFormatString(format string, positional []string) (string, []string, bool) {
+	var sb strings.Builder
+	args := []string{}
+	next := 0
+
+	for i := 0; i < len(format); i++ {
+		ch := format[i]
+		switch {
+		case ch == '{' && i+1 < len(format) && format[i+1] == '{':
+			sb.WriteByte('{')
+			i++
+		case ch == '}' && i+1 < len(format) && format[i+1] == '}':
+			sb.WriteByte('}')
+			i++
+		case ch == '%':
+			sb.WriteString("%%")
+		case ch == '{':
+			end := strings.IndexByte(format[i:], '}')
+			if end < 0 {
+				return "", nil, false
+			}
+			name, spec, _ := strings.Cut(format[i+1:i+end], ":")
+
+			var arg string
+			switch {
+			case name == "":
+				if next >= len(positional) {
+					return "", nil, false
+				}
+				arg = positional[next]
+				next++
+			case name[0] >= '0' && name[0] <= '9':
+				idx, err := strconv.Atoi(name)
+				if err != nil || idx >= len(positional) {
+					return "", nil, false
+				}
+				arg = positional[idx]
+			default:
+				// Захват переменной из окружения: `{name}`
+				arg = name
+			}
+
+			sb.WriteString(formatVerb(spec))
+			args = append(args, arg)
+			i += end
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String(), args, true
+}
+
+// plainText возвращает текст строки формата без плейсхолдеров: экранированный
+// для fmt символ `%%` снова становится `%`.
+func plainText(format string) string {
+	return strings.ReplaceAll(format, "%%", "%")
+}
+
+// formatVerb возвращает глагол fmt для спецификации формата Rust (часть после `:`).
+// Спецификация: [[fill]align][sign]['#']['0'][width]['.' precision][type].
+// Тип: пусто -> %v, `?` -> %+v, `x`/`X`/`o`/`b`/`e`/`E` -> одноимённый глагол.
+// Выравнивание влево (`<`) становится флагом `-`; символ заполнения, кроме `0`, не переносится.
+func formatVerb(spec string) string {
+	var flags strings.Builder
+
+	// Выравнивание, возможно с символом заполнения перед ним
+	if len(spec) >= 2 && strings.IndexByte("<^>", spec[1]) >= 0 {
+		if spec[1] == '<' {
+			flags.WriteByte('-')
+		}
+		spec = spec[2:]
+	} else if len(spec) >= 1 && strings.IndexByte("<^>", spec[0]) >= 0 {
+		if spec[0] == '<' {
+			flags.WriteByte('-')
+		}
+		spec = spec[1:]
+	}
+	for len(spec) > 0 && strings.IndexByte("+#0", spec[0]) >= 0 {
+		flags.WriteByte(spec[0])
+		spec = spec[1:]
+	}
+
+	// Ширина и точность
+	i := 0
+	for i < len(spec) && spec[i] >= '0' && spec[i] <= '9' {
+		i++
+	}
+	width, spec := spec[:i], spec[i:]
+	precision := ""
+	if strings.HasPrefix(spec, ".") {
+		i = 1
+		for i < len(spec) && spec[i] >= '0' && spec[i] <= '9' {
+			i++
+		}
+		precision, spec = spec[:i], spec[i:]
+	}
+
+	verb := "v"
+	switch spec {
+	case "?", "#?":
+		verb = "+v"
+	case "x", "X", "o", "b", "e", "E":
+		verb = spec
+	case "":
+		// `{:.2}` в Rust задаёт число знаков после точки, что в Go соответствует %f
+		if precision != "" {
+			verb = "f"
+		}
+	}
+	return "%" + flags.String() + width + precision + verb
+}
+
 // generateFormatCall генерирует вызов fmt.Sprintf для format! макроса.
+// Плейсхолдеры строки формата преобразуются так же, как для println!.
 func (g *Generator) generateFormatCall(args []ir.Expression) string {
 	if len(args) == 0 {
 		return `""`
 	}
+	if format, fmtArgs, ok := g.translateFormatArgs(args); ok && len(fmtArgs) > 0 {
+		g.use("fmt")
+		return fmt.Sprintf("fmt.Sprintf(%s)", strings.Join(append([]string{strconv.Quote(format)}, fmtArgs...), ", "))
+	} else if ok {
+		// Строка без плейсхолдеров форматировать не нужно
+		return strconv.Quote(plainText(format))
+	}
+	g.use("fmt")
 
 	argStrs := []string{}
 	for _, arg := range args {
@@ -236,15 +1586,75 @@ func (g *Generator) generateFormatCall(args []ir.Expression) string {
 	return fmt.Sprintf("fmt.Sprintf(%s)", strings.Join(argStrs, ", "))
 }
 
-// isPrintlnMacro проверяет, является ли выражение частью println! макроса.
-func isPrintlnMacro(expr string) bool {
-	return strings.Contains(expr, "println!") || strings.Contains(expr, "IDENT")
+// panicMessages — сообщения по умолчанию для макросов, вызывающих панику.
+var panicMessages = map[string]string{
+	"panic!":         "explicit panic",
+	"todo!":          "not yet implemented",
+	"unimplemented!": "not implemented",
+	"unreachable!":   "internal error: entered unreachable code",
 }
 
-// extractPrintlnArgs извлекает аргументы для println! из бинарных операторов.
-func (g *Generator) extractPrintlnArgs(expr ir.Expression) []ir.Expression {
-	// Упрощённая реализация
-	return []ir.Expression{expr}
+// generatePanicMessage генерирует аргумент panic: форматированное сообщение,
+// само выражение сообщения или defaultMsg, если аргументов нет.
+func (g *Generator) generatePanicMessage(args []ir.Expression, defaultMsg string) string {
+	if len(args) == 0 {
+		return strconv.Quote(defaultMsg)
+	}
+	if _, _, ok := g.translateFormatArgs(args); ok {
+		return g.generateFormatCall(args)
+	}
+	return g.generateExpression(args[0])
+}
+
+// generateAssert генерирует проверку для assert!, assert_eq! и assert_ne!:
+// `if !cond { panic(...) }`. Возвращает false для остальных макросов.
+func (g *Generator) generateAssert(call *ir.CallExpr) bool {
+	var cond, msg string
+	switch {
+	case call.FuncName == "assert!" && len(call.Args) >= 1:
+		cond = "!" + g.generateOperand(call.Args[0])
+		msg = g.generatePanicMessage(call.Args[1:], "assertion failed")
+	case (call.FuncName == "assert_eq!" || call.FuncName == "assert_ne!") && len(call.Args) >= 2:
+		left, right := g.generateExpression(call.Args[0]), g.generateExpression(call.Args[1])
+		op, rustOp := "!=", "=="
+		if call.FuncName == "assert_ne!" {
+			op, rustOp = "==", "!="
+		}
+		cond = fmt.Sprintf("%s %s %s", left, op, right)
+		// Структуры с #[derive(PartialEq)] сравниваются методом Equals, как и в `a == b`
+		if g.equatable[typeName(call.Args[0].Type())] {
+			cond = fmt.Sprintf("%s.Equals(%s)", g.generateOperand(call.Args[0]), right)
+			if call.FuncName == "assert_eq!" {
+				cond = "!" + cond
+			}
+		}
+		g.use("fmt")
+		msg = fmt.Sprintf("fmt.Sprintf(%q, %s, %s)",
+			"assertion `left "+rustOp+" right` failed\n  left: %v\n right: %v", left, right)
+		if len(call.Args) > 2 {
+			msg = fmt.Sprintf("fmt.Sprintf(%q, %s, %s, %s)",
+				"assertion `left "+rustOp+" right` failed: %s\n  left: %v\n right: %v",
+				g.generatePanicMessage(call.Args[2:], ""), left, right)
+		}
+	default:
+		return false
+	}
+	g.emit("if %s {", cond)
+	g.indent++
+	g.emit("panic(%s)", msg)
+	g.indent--
+	g.emit("}")
+	return true
+}
+
+// at делает pos текущей позицией исходного кода, если она известна,
+// и возвращает функцию, восстанавливающую предыдущую позицию.
+func (g *Generator) at(pos token.Position) func() {
+	saved := g.pos
+	if pos.Line > 0 {
+		g.pos = pos
+	}
+	return func() { g.pos = saved }
 }
 
 // emit добавляет строку с учётом отступов.
@@ -252,11 +1662,36 @@ func (g *Generator) emit(format string, args ...interface{}) {
 	indent := strings.Repeat("\t", g.indent)
 	line := fmt.Sprintf(format, args...)
 	g.builder.WriteString(indent + line + "\n")
+	g.line++
+	if g.pos.Line > 0 && line != "" {
+		g.positions[g.line] = g.pos
+	}
+	g.line += strings.Count(line, "\n")
 }
 
 // emitln добавляет пустую строку.
 func (g *Generator) emitln() {
 	g.builder.WriteString("\n")
+	g.line++
+}
+
+// decapitalize делает первую букву строчной (неэкспортируемое имя Go).
+func decapitalize(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// camelCase переводит snake_case в camelCase: `foo_bar` -> `fooBar`.
+// Ведущие подчёркивания сохраняются.
+func camelCase(s string) string {
+	trimmed := strings.TrimLeft(s, "_")
+	parts := strings.Split(trimmed, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = capitalize(parts[i])
+	}
+	return s[:len(s)-len(trimmed)] + strings.Join(parts, "")
 }
 
 // capitalize делает первую букву заглавной (для Go).
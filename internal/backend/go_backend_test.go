@@ -0,0 +1,1475 @@
+package backend_test
+
+import (
+	goast "go/ast"
+	"go/format"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/semetekare/rust2go/internal/ast"
+	"github.com/semetekare/rust2go/internal/backend"
+	"github.com/semetekare/rust2go/internal/ir"
+	"github.com/semetekare/rust2go/internal/lexer"
+	"github.com/semetekare/rust2go/internal/parser"
+)
+
+// parseCode разбирает исходный код и возвращает AST.
+func parseCode(code string, t *testing.T) *ast.Crate {
+	t.Helper()
+	lx := lexer.NewLexer()
+	toks, err := lx.Lex(code)
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+
+	p := parser.NewParser(toks)
+	crate, errs := p.ParseFile()
+	if len(errs) > 0 {
+		t.Fatalf("Parse errors: %v", errs)
+	}
+	return crate
+}
+
+// generateCode прогоняет исходный код через лексер, парсер и IR и возвращает сгенерированный Go-код.
+func generateCode(code string, t *testing.T) string {
+	t.Helper()
+	module := ir.NewTransformer().Transform(parseCode(code, t))
+	return backend.NewGenerator().Generate(module)
+}
+
+// assertCompiles проверяет, что сгенерированный код — корректная программа Go.
+func assertCompiles(t *testing.T, code string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, "main.go", code, 0)
+	if err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, code)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("main", fset, []*goast.File{file}, nil); err != nil {
+		t.Fatalf("Generated code does not compile: %v\n%s", err, code)
+	}
+}
+
+// assertContains проверяет, что сгенерированный код содержит все ожидаемые фрагменты.
+func assertContains(t *testing.T, code string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if !strings.Contains(code, fragment) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", fragment, code)
+		}
+	}
+}
+
+func TestGenerateMatchTuplePatterns(t *testing.T) {
+	code := `
+fn classify(point: (i32, i32)) -> i32 {
+    match point {
+        (0, 0) => 0,
+        (x, _) => x,
+    }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func classify(point struct{ Field0 int; Field1 int }) int {",
+		"switch {",
+		"case point.Field0 == 0 && point.Field1 == 0:",
+		"return 0",
+		"default:",
+		"x := point.Field0",
+		"return x",
+	)
+	// Элемент `_` не порождает ни сравнения, ни привязки
+	if n := strings.Count(goCode, "point.Field1"); n != 1 {
+		t.Errorf("Expected point.Field1 to appear only in the (0, 0) arm, got %d occurrences:\n%s", n, goCode)
+	}
+}
+
+func TestGenerateMatchStatement(t *testing.T) {
+	code := `
+fn main() {
+    match (1, 2) {
+        (1, y) => println!("{}", y),
+        _ => println!("other"),
+    }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"switch matchValue := struct{ Field0 int; Field1 int }{1, 2}; {",
+		"case matchValue.Field0 == 1:",
+		"y := matchValue.Field1",
+		"default:",
+	)
+}
+
+func TestGenerateValueMatch(t *testing.T) {
+	code := `
+fn describe(n: i32) -> i32 {
+    let code = match n {
+        1 => 10,
+        2 => 20,
+        _ => 0,
+    };
+    code
+}
+`
+	goCode := generateCode(code, t)
+	expected := `	var code int
+	switch n {
+	case 1:
+		code = 10
+	case 2:
+		code = 20
+	default:
+		code = 0
+	}
+	return code
+`
+	assertContains(t, goCode, expected)
+}
+
+func TestGenerateUnwrapPanicLocations(t *testing.T) {
+	code := `fn get(o: Option<i32>) -> i32 {
+    o.unwrap()
+}
+`
+	lx := lexer.NewLexer()
+	toks, err := lx.Lex(code)
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+	crate, errs := parser.NewParser(toks).ParseFile()
+	if len(errs) > 0 {
+		t.Fatalf("Parse errors: %v", errs)
+	}
+	module := ir.NewTransformer().Transform(crate)
+
+	gen := backend.NewGenerator()
+	plain := gen.Generate(module)
+	assertContains(t, plain,
+		"func get(o *int) int {",
+		"return rustUnwrap(o, \"called `Option::unwrap()` on a `None` value\")",
+		"func rustUnwrap[T any](v *T, msg string) T {",
+	)
+	if strings.Contains(plain, "main.rs") {
+		t.Errorf("Expected no source location without PanicLocations:\n%s", plain)
+	}
+
+	gen.PanicLocations = true
+	gen.SourceFile = "main.rs"
+	located := gen.Generate(module)
+	assertContains(t, located, `" at main.rs:2:7"`)
+}
+
+func TestGenerateExpectMessage(t *testing.T) {
+	code := `fn get(o: Option<String>) -> String {
+    o.expect("no value")
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode, `return rustUnwrap(o, "no value")`)
+}
+
+func TestGenerateMethodCallOnReference(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+impl Point {
+    fn sum(&self) -> i32 {
+        self.x + self.y
+    }
+}
+
+fn total(p: &Point, q: Point) -> i32 {
+    let a = p.sum();
+    (&q).sum() + a
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func (recv *Point) sum() int {",
+		"return recv.x + recv.y",
+		"func total(p Point, q Point) int {",
+		"a := p.sum()",
+		"return q.sum() + a",
+	)
+}
+
+func TestGenerateMutableReferences(t *testing.T) {
+	code := `
+struct Counter {
+    n: i32,
+}
+
+impl Counter {
+    fn add(&mut self, by: &mut i32) {
+        self.n = self.n + *by;
+        *by = 0;
+    }
+}
+
+fn inc(x: &mut i32) {
+    *x = *x + 1;
+}
+
+fn show(x: &i32) -> i32 {
+    *x
+}
+
+fn set_first(v: &mut Vec<i32>) {
+    v[0] = 9;
+}
+
+fn main() {
+    let mut a = 1;
+    inc(&mut a);
+    let mut c = Counter { n: 1 };
+    c.add(&mut a);
+    let r = &mut a;
+    inc(r);
+    let s = show(r);
+    let mut v = vec![1, 2];
+    set_first(&mut v);
+    println!("{} {} {}", a, s, *r);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func (recv *Counter) add(by *int) {",
+		"recv.n = recv.n + *by",
+		"*by = 0",
+		"func inc(x *int) {\n\t*x = *x + 1\n}",
+		"func show(x int) int {",
+		"func setFirst(v *[]int) {\n\t(*v)[0] = 9\n}",
+		"inc(&a)",
+		"c.add(&a)",
+		"r := &a",
+		"inc(r)",
+		"s := show(*r)",
+		"setFirst(&v)",
+		"fmt.Printf(\"%v %v %v\\n\", a, s, *r)",
+	)
+	assertCompiles(t, goCode)
+}
+
+func TestGenerateImplMethodReceivers(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+}
+
+impl Point {
+    fn x(&self) -> i32 {
+        self.x
+    }
+
+    fn into_x(self) -> i32 {
+        self.x
+    }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func (recv *Point) X() int {\n\treturn recv.x\n}",
+		"func (recv Point) intoX() int {\n\treturn recv.x\n}",
+	)
+}
+
+func TestGenerateVisibility(t *testing.T) {
+	code := `
+pub struct Counter {
+    pub total_count: i32,
+    step: i32,
+}
+
+impl Counter {
+    pub fn next_value(&self) -> i32 {
+        self.total_count + self.step
+    }
+}
+
+pub fn foo_bar(c: Counter) -> i32 {
+    c.next_value() + baz()
+}
+
+fn baz() -> i32 {
+    1
+}
+`
+	crate := parseCode(code, t)
+	goCode := backend.NewGenerator().Generate(ir.NewTransformer().Transform(crate))
+	assertContains(t, goCode,
+		"TotalCount int",
+		"step int",
+		"func (recv *Counter) NextValue() int {",
+		"return recv.TotalCount + recv.step",
+		"func FooBar(c Counter) int {",
+		"return c.NextValue() + baz()",
+		"func baz() int {",
+	)
+
+	gen := backend.NewGenerator()
+	gen.CamelCase = false
+	goCode = gen.Generate(ir.NewTransformer().Transform(crate))
+	assertContains(t, goCode,
+		"Total_count int",
+		"func (recv *Counter) Next_value() int {",
+		"return recv.Total_count + recv.step",
+		"func Foo_bar(c Counter) int {",
+		"return c.Next_value() + baz()",
+	)
+}
+
+func TestGenerateGenericFunction(t *testing.T) {
+	code := `
+fn first<'a, T, U>(x: &'a T, y: U) -> &'a T {
+    x
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode, "func first[T any, U any](x T, y U) T {")
+}
+
+func TestGenerateGenericConstraints(t *testing.T) {
+	code := `
+fn id<T>(x: T) -> T {
+    x
+}
+
+fn larger<T: PartialOrd + Copy>(a: T, b: T) -> T {
+    if a > b { a } else { b }
+}
+
+fn same<T, U: Clone>(a: T, b: T, u: U) -> bool where T: PartialEq + Debug {
+    a == b
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		`"cmp"`,
+		"func id[T any](x T) T {",
+		"func larger[T cmp.Ordered](a T, b T) T {",
+		"func same[T comparable, U any](a T, b T, u U) bool {",
+	)
+}
+
+func TestGenerateTryOperator(t *testing.T) {
+	code := `
+fn parse() -> Result<i32, ParseError> {
+    Ok(1)
+}
+
+fn double() -> Result<i32, ParseError> {
+    let x = parse()?;
+    let y = parse()? + x;
+    Ok(y)
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func parse() (int, error) {",
+		"return 1, nil",
+		"x, err := parse()",
+		"tryValue1, err := parse()",
+		"return 0, err",
+		"y := tryValue1 + x",
+		"return y, nil",
+	)
+}
+
+func TestGenerateErrValues(t *testing.T) {
+	code := `
+fn check(n: i32) -> Result<i32, String> {
+    if n < 0 {
+        Err("neg")
+    } else if n == 0 {
+        Err(format!("zero {}", n))
+    } else {
+        Ok(n * 2)
+    }
+}
+
+fn code(n: i32) -> Result<i32, i32> {
+    if n > 10 {
+        Err(n)
+    } else {
+        Ok(n)
+    }
+}
+
+fn twice(n: i32) -> Result<i32, String> {
+    let v = check(n)?;
+    let w = code(v)?;
+    Ok(v + w)
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"return 0, errors.New(\"neg\")",
+		"return 0, errors.New(fmt.Sprintf(\"zero %v\", n))",
+		"return 0, fmt.Errorf(\"%v\", n)",
+		"v, err := check(n)",
+		"return 0, err",
+		"\"errors\"",
+	)
+	assertCompiles(t, goCode)
+}
+
+func TestGenerateFormattedIsGofmtCanonical(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    longer_name: i32,
+}
+
+fn classify(point: (i32, i32)) -> i32 {
+    let p = (1, 2);
+    match point {
+        (0, 0) => 0,
+        (x, _) => x,
+    }
+}
+`
+	lx := lexer.NewLexer()
+	toks, err := lx.Lex(code)
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+	crate, errs := parser.NewParser(toks).ParseFile()
+	if len(errs) > 0 {
+		t.Fatalf("Parse errors: %v", errs)
+	}
+	module := ir.NewTransformer().Transform(crate)
+
+	goCode, err := backend.NewGenerator().GenerateFormatted(module)
+	if err != nil {
+		t.Fatalf("GenerateFormatted failed: %v", err)
+	}
+	formatted, err := format.Source([]byte(goCode))
+	if err != nil {
+		t.Fatalf("format.Source failed: %v", err)
+	}
+	if string(formatted) != goCode {
+		t.Errorf("Expected gofmt-canonical output, got:\n%s\nwant:\n%s", goCode, formatted)
+	}
+}
+
+func TestGenerateGeneratedHeader(t *testing.T) {
+	module := ir.NewTransformer().Transform(parseCode("fn main() {}", t))
+	if goCode := backend.NewGenerator().Generate(module); strings.Contains(goCode, "DO NOT EDIT") {
+		t.Errorf("Expected no header by default, got:\n%s", goCode)
+	}
+
+	gen := backend.NewGenerator()
+	gen.GeneratedHeader = true
+	goCode, err := gen.GenerateFormatted(module)
+	if err != nil {
+		t.Fatalf("GenerateFormatted failed: %v", err)
+	}
+	want := "// Code generated by rust2go; DO NOT EDIT.\n\npackage main\n"
+	if !strings.HasPrefix(goCode, want) {
+		t.Errorf("Expected output to start with %q, got:\n%s", want, goCode)
+	}
+	// Маркер должен соответствовать соглашению go generate
+	if !regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`).MatchString(goCode) {
+		t.Errorf("Header does not match the generated-code convention:\n%s", goCode)
+	}
+	// Строки тела сдвигаются на длину заголовка
+	if pos, ok := module.PositionMap[5]; !ok || pos.Line != 1 {
+		t.Errorf("Expected line 5 (func main) to map to source line 1, got %v", module.PositionMap)
+	}
+}
+
+func TestGenerateFormattedFallsBackOnInvalidCode(t *testing.T) {
+	module := &ir.Module{
+		PackageName: "main",
+		Functions: []*ir.Function{{
+			Name:       "not valid",
+			ReturnType: ir.NewType("", true),
+		}},
+	}
+
+	goCode, err := backend.NewGenerator().GenerateFormatted(module)
+	if err == nil {
+		t.Fatal("Expected formatting error for invalid generated code")
+	}
+	assertContains(t, goCode, "func not valid() {")
+}
+
+func TestGeneratePrintlnPlaceholders(t *testing.T) {
+	code := `
+fn main() {
+    let a = 1;
+    let b = 2;
+    println!("{} {}", a, b);
+    println!("{:?} is 100%", a);
+    println!("hi");
+    println!("{{braces}} and 100%");
+    let s = format!("{{literal}}");
+    panic!("{{boom}}");
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		`fmt.Printf("%v %v\n", a, b)`,
+		`fmt.Printf("%+v is 100%%\n", a)`,
+		`fmt.Println("hi")`,
+		`fmt.Println("{braces} and 100%")`,
+		`s := "{literal}"`,
+		`panic("{boom}")`,
+	)
+}
+
+func TestGenerateFormatPlaceholders(t *testing.T) {
+	code := `
+fn show(a: i32, b: f64) -> String {
+    let s = format!("{}-{}", a, b);
+    let hex = format!("{:x} {:>5} {:<3} {:08.3}", a, a, a, b);
+    format!("{a}: {0:?} {{raw}}", s)
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		`fmt.Sprintf("%v-%v", a, b)`,
+		`fmt.Sprintf("%x %5v %-3v %08.3f", a, a, a, b)`,
+		`fmt.Sprintf("%v: %+v {raw}", a, s)`,
+	)
+}
+
+func TestGenerateImportsOnlyWhenUsed(t *testing.T) {
+	goCode := generateCode(`
+fn add(a: i32, b: i32) -> i32 {
+    a + b
+}
+`, t)
+	if strings.Contains(goCode, "import") {
+		t.Errorf("Expected no imports for a program without I/O, got:\n%s", goCode)
+	}
+
+	goCode = generateCode(`
+fn main() {
+    println!("hi");
+}
+`, t)
+	assertContains(t, goCode, "import \"fmt\"\n")
+	if strings.Contains(goCode, "import (") {
+		t.Errorf("Expected a single-line import for one package, got:\n%s", goCode)
+	}
+
+	goCode = generateCode(`
+fn shout(s: String) {
+    println!("{}", s.to_uppercase());
+}
+`, t)
+	assertContains(t, goCode, "import (\n\t\"fmt\"\n\t\"strings\"\n)\n", "strings.ToUpper(s)")
+}
+
+func TestGenerateIfElseChain(t *testing.T) {
+	ident := func(name string) ir.Expression {
+		return &ir.LiteralExpr{Value: name, Kind: "IDENT", TypeInfo: ir.NewType("int", true)}
+	}
+	less := func(left, right string) ir.Expression {
+		return &ir.BinaryExpr{Left: ident(left), Op: "<", Right: ident(right), TypeInfo: ir.NewType("bool", true)}
+	}
+	assign := func(value string) ir.Statement {
+		return &ir.Assignment{Target: ident("r"), Value: ident(value)}
+	}
+
+	module := &ir.Module{
+		PackageName: "main",
+		Functions: []*ir.Function{{
+			Name:       "f",
+			Params:     []*ir.Parameter{{Name: "a", Type: ir.NewType("int", true)}, {Name: "b", Type: ir.NewType("int", true)}},
+			ReturnType: ir.NewType("", true),
+			Body: []ir.Statement{
+				&ir.Declaration{Name: "r", Type: ir.NewType("int", true), InitValue: ident("0")},
+				&ir.If{
+					Cond: less("a", "b"),
+					Then: []ir.Statement{assign("a")},
+					Else: []ir.Statement{&ir.If{
+						Cond: less("b", "a"),
+						Then: []ir.Statement{assign("b")},
+						Else: []ir.Statement{assign("0")},
+					}},
+				},
+				&ir.If{
+					Cond: &ir.LiteralExpr{Value: "true", Kind: "BOOL", TypeInfo: ir.NewType("bool", true)},
+					Then: []ir.Statement{assign("1")},
+				},
+			},
+		}},
+	}
+
+	goCode := backend.NewGenerator().Generate(module)
+	expected := `	if a < b {
+		r = a
+	} else if b < a {
+		r = b
+	} else {
+		r = 0
+	}
+	if true {
+		r = 1
+	}
+`
+	assertContains(t, goCode, expected)
+}
+
+func TestGenerateLoops(t *testing.T) {
+	code := `
+fn f(n: i32, xs: Vec<i32>) {
+    let mut sum = 0;
+    while sum < n {
+        sum = n;
+        continue;
+    }
+    for i in 0..n {
+        sum = sum + i;
+    }
+    for i in 1..=n {
+        sum = sum + i;
+    }
+    for i in xs {
+        sum = sum + i;
+        break;
+    }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"\tfor sum < n {\n\t\tsum = n\n\t\tcontinue\n\t}\n",
+		"\tfor i := 0; i < n; i++ {\n\t\tsum = sum + i\n\t}\n",
+		"\tfor i := 1; i <= n; i++ {\n",
+		"\tfor _, i := range xs {\n\t\tsum = sum + i\n\t\tbreak\n\t}\n",
+	)
+}
+
+func TestGenerateRangeLoops(t *testing.T) {
+	code := `
+fn f(m: i64) {
+    for k in 0..m {
+        println!("{}", k);
+    }
+    for _ in 0..3 {
+        println!("x");
+    }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		// Литерал начала получает тип диапазона
+		"\tfor k := int64(0); k < m; k++ {\n",
+		"\tfor range 3 {\n",
+	)
+}
+
+func TestGenerateStructLiteral(t *testing.T) {
+	code := `
+pub struct Point {
+    pub x: i32,
+    pub y: i32,
+}
+
+struct Empty {}
+
+fn make() -> Point {
+    let e = Empty {};
+    Point { x: 1, y: 2 }
+}
+
+fn flipped(x: i32) -> Point {
+    Point { y: 0, x }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"e := Empty{}",
+		"return Point{X: 1, Y: 2}",
+		// Поля следуют в порядке записи, чтобы сохранить порядок вычисления Rust
+		"return Point{Y: 0, X: x}",
+	)
+}
+
+func TestGenerateArrayLiteralsAndIndexing(t *testing.T) {
+	code := `
+fn main() {
+    let arr = [1, 2, 3];
+    let m = [[1, 2], [3, 4]];
+    let zeros = [0; 5];
+    let flags = [true; 2];
+    let i = 1;
+    let j = 0;
+    let a = arr[i];
+    let b = m[i][j];
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"arr := []int{1, 2, 3}",
+		"m := [][]int{[]int{1, 2}, []int{3, 4}}",
+		"zeros := make([]int, 5)",
+		"flags := rustRepeat(true, 2)",
+		"func rustRepeat[T any](v T, n int) []T {",
+		"a := arr[i]",
+		"b := m[i][j]",
+	)
+}
+
+func TestGenerateVecRepeat(t *testing.T) {
+	code := `
+fn main() {
+    let n = 3;
+    let zeros = vec![0; 5];
+    let wide: Vec<i64> = vec![0; n];
+    let sevens: Vec<i64> = vec![7; 2];
+    let seen = vec![false; n];
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"zeros := make([]int, 5)",
+		"wide := make([]int64, n)",
+		"sevens := rustRepeat[int64](7, 2)",
+		"seen := make([]bool, n)",
+	)
+}
+
+func TestGenerateMinimalParentheses(t *testing.T) {
+	code := `
+fn f(a: i32, b: i32, c: i32, p: bool, q: bool) {
+    let x2 = (a + b) * c;
+    let x3 = (a - b) - c;
+    let x4 = a - (b - c);
+    let x5 = a * b == c && p || q;
+    let x6 = p && (q || p);
+    let x7 = !(p && q);
+    let x8 = -(a + b);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"x2 := (a + b) * c",
+		"x3 := a - b - c",
+		"x4 := a - (b - c)",
+		"x5 := a * b == c && p || q",
+		"x6 := p && (q || p)",
+		"x7 := !(p && q)",
+		"x8 := -(a + b)",
+	)
+}
+
+func TestGenerateMacros(t *testing.T) {
+	code := `
+fn check(n: i32) -> i32 {
+    match n {
+        0 => panic!("zero"),
+        _ => n,
+    }
+}
+
+fn main() {
+    let v = vec![1, 2, 3];
+    let empty: Vec<String> = vec![];
+    assert!(v[0] == 1);
+    assert_eq!(v[1], 2);
+    panic!("failed at {}", v[0]);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"case 0:\n\t\tpanic(\"zero\")\n\tdefault:\n\t\treturn n\n",
+		"v := []int{1, 2, 3}",
+		"empty := []string{}",
+		"\tif !(v[0] == 1) {\n\t\tpanic(\"assertion failed\")\n\t}\n",
+		"\tif v[1] != 2 {\n\t\tpanic(fmt.Sprintf(\"assertion `left == right` failed\\n  left: %v\\n right: %v\", v[1], 2))\n\t}\n",
+		"panic(fmt.Sprintf(\"failed at %v\", v[0]))",
+	)
+	if strings.Contains(goCode, "TODO") {
+		t.Errorf("Expected all macros to be translated, got:\n%s", goCode)
+	}
+}
+
+func TestGenerateTailReturns(t *testing.T) {
+	code := `
+fn pick(c: bool) -> i32 {
+    if c { 1 } else { 2 }
+}
+
+fn sign(n: i32) -> i32 {
+    if n > 0 {
+        1
+    } else if n < 0 {
+        -1
+    } else {
+        let z = 0;
+        z
+    }
+}
+
+fn nested(n: i32) -> i32 {
+    let x = if n > 0 { n } else { 0 };
+    {
+        let y = x * 2;
+        y
+    }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"\tif c {\n\t\treturn 1\n\t} else {\n\t\treturn 2\n\t}\n",
+		"\t} else if n < 0 {\n\t\treturn -1\n\t} else {\n\t\tz := 0\n\t\treturn z\n\t}\n",
+		"\tvar x int\n\tif n > 0 {\n\t\tx = n\n\t} else {\n\t\tx = 0\n\t}\n",
+		"\t{\n\t\ty := x * 2\n\t\treturn y\n\t}\n",
+	)
+}
+
+func TestGenerateZeroValueReturns(t *testing.T) {
+	code := `
+struct Point { x: i32 }
+
+fn int() -> i32 { let a = 1; }
+fn float() -> f64 { let a = 1; }
+fn text() -> String { let a = 1; }
+fn flag() -> bool { let a = 1; }
+fn point() -> Point { let a = 1; }
+fn tail(c: bool) -> i32 { if c { 1 } else { 2 } }
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"int {\n\ta := 1\n\treturn 0\n}\n",
+		"float64 {\n\ta := 1\n\treturn 0\n}\n",
+		"string {\n\ta := 1\n\treturn \"\"\n}\n",
+		"bool {\n\ta := 1\n\treturn false\n}\n",
+		"Point {\n\ta := 1\n\treturn Point{}\n}\n",
+		"\t\treturn 2\n\t}\n}\n",
+	)
+}
+
+func TestGenerateUnitTailIsNotReturned(t *testing.T) {
+	code := `
+fn helper() -> i32 { 1 }
+fn side() {}
+fn unit() -> () { side() }
+fn main() {
+    println!("start");
+    helper()
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func unit() {\n\tside()\n}\n",
+		"func main() {\n\tfmt.Println(\"start\")\n\thelper()\n}\n",
+	)
+	if strings.Count(goCode, "return") != 1 {
+		t.Errorf("Expected only helper to return a value, got:\n%s", goCode)
+	}
+}
+
+func TestGenerateClosures(t *testing.T) {
+	code := `
+fn main() {
+    let base = 10;
+    let inc = |x: i32| x + 1;
+    let add = |a: i32, b: i32| -> i32 {
+        let s = a + b;
+        s + base
+    };
+    let log = |m: &str| println!("{}", m);
+    let y = inc(add(1, 2));
+    log("done");
+}
+`
+	goCode, err := backend.NewGenerator().GenerateFormatted(ir.NewTransformer().Transform(parseCode(code, t)))
+	if err != nil {
+		t.Fatalf("Generated closure is not valid Go: %v\n%s", err, goCode)
+	}
+	assertContains(t, goCode,
+		"inc := func(x int) int {\n\t\treturn x + 1\n\t}\n",
+		"add := func(a int, b int) int {\n\t\ts := a + b\n\t\treturn s + base\n\t}\n",
+		"log := func(m string) {\n\t\tfmt.Printf(\"%v\\n\", m)\n\t}\n",
+		"y := inc(add(1, 2))\n",
+	)
+}
+
+func TestGenerateIdentNotMistakenForMacro(t *testing.T) {
+	code := `
+fn f(IDENT: i32, println_IDENT: i32) -> i32 {
+    let total = IDENT + println_IDENT;
+    total * 2
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"total := IDENT + println_IDENT",
+		"return total * 2",
+	)
+	if strings.Contains(goCode, "fmt.") {
+		t.Errorf("Expected no fmt calls for plain variables, got:\n%s", goCode)
+	}
+}
+
+func TestGenerateStringEscapes(t *testing.T) {
+	code := `
+fn main() {
+    let a = "say \"hi\"";
+    let b = "line\n";
+    let c = "ends with quote\"";
+    let d = "back\\slash";
+    println!("{} \"{}\"\n", a, b);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		`a := "say \"hi\""`,
+		`b := "line\n"`,
+		`c := "ends with quote\""`,
+		`d := "back\\slash"`,
+		`fmt.Printf("%v \"%v\"\n\n", a, b)`,
+	)
+}
+
+func TestGeneratePositionMap(t *testing.T) {
+	code := `fn add(a: i32, b: i32) -> i32 {
+    let c = a + b;
+    if c > 10 {
+        println!("big");
+    }
+    c
+}
+`
+	module := ir.NewTransformer().Transform(parseCode(code, t))
+	goCode, err := backend.NewGenerator().GenerateFormatted(module)
+	if err != nil {
+		t.Fatalf("GenerateFormatted failed: %v", err)
+	}
+
+	// Ищем каждую строку Go по её тексту и сверяем, на какую строку .rs она указывает
+	lines := strings.Split(goCode, "\n")
+	expected := map[string]int{
+		"func add(a int, b int) int {": 1,
+		"c := a + b":                   2,
+		"if c > 10 {":                  3,
+		`fmt.Println("big")`:           4,
+		"return c":                     6,
+	}
+	for text, srcLine := range expected {
+		found := false
+		for i, line := range lines {
+			if strings.TrimSpace(line) != text {
+				continue
+			}
+			found = true
+			pos, ok := module.PositionMap[i+1]
+			if !ok || pos.Line != srcLine {
+				t.Errorf("Expected line %d (%q) to map to source line %d, got %v (ok=%t)", i+1, text, srcLine, pos, ok)
+			}
+		}
+		if !found {
+			t.Errorf("Expected generated line %q in:\n%s", text, goCode)
+		}
+	}
+	if _, ok := module.PositionMap[1]; ok {
+		t.Errorf("Expected package clause to have no source position")
+	}
+}
+
+func TestGeneratePositionMapAfterReflow(t *testing.T) {
+	code := `fn main() {
+    let t = (1, 2);
+    let a = t.0;
+    println!("{}", a);
+}
+`
+	module := ir.NewTransformer().Transform(parseCode(code, t))
+	goCode, err := backend.NewGenerator().GenerateFormatted(module)
+	if err != nil {
+		t.Fatalf("GenerateFormatted failed: %v", err)
+	}
+
+	// gofmt разворачивает тип кортежа на несколько строк; строки после него не должны сдвигаться
+	lines := strings.Split(goCode, "\n")
+	expected := map[string]int{
+		"t := struct {":         2,
+		"Field1 int":            2,
+		"a := t.Field0":         3,
+		`fmt.Printf("%v\n", a)`: 4,
+	}
+	for text, srcLine := range expected {
+		i := slices.IndexFunc(lines, func(line string) bool { return strings.TrimSpace(line) == text })
+		if i < 0 {
+			t.Errorf("Expected generated line %q in:\n%s", text, goCode)
+			continue
+		}
+		if pos, ok := module.PositionMap[i+1]; !ok || pos.Line != srcLine {
+			t.Errorf("Expected line %d (%q) to map to source line %d, got %v (ok=%t)", i+1, text, srcLine, pos, ok)
+		}
+	}
+}
+
+func TestGenerateDebugDerive(t *testing.T) {
+	code := `
+#[derive(Debug)]
+struct Point {
+    pub x: i32,
+    pub y: i32,
+}
+
+#[derive(Debug)]
+struct Empty {}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		`import "fmt"`,
+		"func (x Point) String() string {",
+		`return fmt.Sprintf("Point { x: %v, y: %v }", x.X, x.Y)`,
+		"func (x Empty) String() string {",
+		`return "Empty"`,
+	)
+}
+
+func TestGeneratePartialEqDerive(t *testing.T) {
+	code := `
+#[derive(PartialEq)]
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+#[derive(Debug, PartialEq)]
+struct Bag {
+    items: Vec<i32>,
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func (a Point) Equals(b Point) bool {",
+		"return a.x == b.x && a.y == b.y",
+		"func (a Bag) Equals(b Bag) bool {",
+		"return reflect.DeepEqual(a.items, b.items)",
+		`"reflect"`,
+	)
+}
+
+func TestGeneratePartialEqComparison(t *testing.T) {
+	code := `
+#[derive(PartialEq)]
+struct Bag {
+    items: Vec<i32>,
+}
+
+#[derive(PartialEq)]
+struct Pair {
+    bag: Bag,
+    n: i32,
+}
+
+struct Plain {
+    n: i32,
+}
+
+fn same(a: Bag, b: Bag) -> bool {
+    a == b
+}
+
+fn differ(a: Pair, b: Pair) -> bool {
+    a != b
+}
+
+fn plain(a: Plain, b: Plain) -> bool {
+    a == b
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		// == на срезах в Go не компилируется: сравнение идёт через Equals
+		"return a.Equals(b)",
+		"return !a.Equals(b)",
+		"return a.bag.Equals(b.bag) && a.n == b.n",
+		// Без derive(PartialEq) метода Equals нет, остаётся ==
+		"func plain(a Plain, b Plain) bool {\n\treturn a == b\n}",
+	)
+	if _, err := format.Source([]byte(goCode)); err != nil {
+		t.Errorf("Generated code is not valid Go: %v\n%s", err, goCode)
+	}
+}
+
+func TestGeneratePartialEqAssert(t *testing.T) {
+	code := `
+#[derive(PartialEq, Debug)]
+struct Bag {
+    items: Vec<i32>,
+}
+
+fn check(a: Bag, b: Bag) {
+    assert_eq!(a, b);
+    assert_ne!(a, b, "bags {}", 1);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"if !a.Equals(b) {\n\t\tpanic(fmt.Sprintf(\"assertion `left == right` failed\\n  left: %v\\n right: %v\", a, b))",
+		"if a.Equals(b) {\n\t\tpanic(fmt.Sprintf(\"assertion `left != right` failed: %s",
+	)
+	assertCompiles(t, goCode)
+}
+
+func TestGenerateAssociatedFunctions(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+impl Point {
+    pub fn new(x: i32, y: i32) -> Point {
+        Point { x, y }
+    }
+
+    fn from_pair(a: i32) -> Point {
+        Point::new(a, a)
+    }
+}
+
+fn main() {
+    let p = Point::new(1, 2);
+    let q = Point::from_pair(3);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func NewPoint(x int, y int) Point {",
+		"func pointFromPair(a int) Point {",
+		"return NewPoint(a, a)",
+		"p := NewPoint(1, 2)",
+		"q := pointFromPair(3)",
+	)
+}
+
+func TestGenerateCasts(t *testing.T) {
+	code := `
+fn convert(x: i32, n: i32, b: bool) -> f64 {
+    let wide = x as i64;
+    let small = n as f32;
+    let scaled = -x as f64 * 2.0;
+    let flag = b as i32;
+    let nothing = x as ();
+    x as i64 as f64
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"wide := int64(x)",
+		"small := float32(n)",
+		"scaled := float64(-x) * 2.0",
+		"flag := int(rustBoolToInt(b))",
+		"func rustBoolToInt(b bool) int {",
+		"nothing := x",
+		"return float64(int64(x))",
+	)
+}
+
+func TestGenerateConstantCasts(t *testing.T) {
+	code := `
+fn main() {
+    let a = 3.5 as i32;
+    let b = -1 as u8;
+}
+`
+	// Go не преобразует константы с потерей, поэтому операнд сворачивается по правилам Rust
+	module := ir.NewTransformer().Transform(parseCode(code, t))
+	ir.Fold(module)
+	goCode := backend.NewGenerator().Generate(module)
+	assertContains(t, goCode,
+		"a := int(3)",
+		"b := uint8(255)",
+	)
+}
+
+func TestGenerateSuffixedLiterals(t *testing.T) {
+	code := `
+fn main() {
+    let a = 5u8;
+    let b = 1_000i64;
+    let c = 2.5f32;
+    let d = 1f32;
+    let e = 255u8 as i32;
+    let f = 5i32;
+    println!("{} {} {} {} {} {}", a, b, c, d, e, f);
+}
+`
+	module := ir.NewTransformer().Transform(parseCode(code, t))
+	ir.Fold(module)
+	goCode := backend.NewGenerator().Generate(module)
+	assertContains(t, goCode,
+		"a := uint8(5)",
+		"b := int64(1000)",
+		"c := float32(2.5)",
+		"d := float32(1)",
+		"e := int(255)",
+		"f := 5\n",
+	)
+	assertCompiles(t, goCode)
+}
+
+func TestGenerateTypedLiteralDeclarations(t *testing.T) {
+	code := `
+fn main() {
+    let a: i32 = 1;
+    let f: f32 = 1.5;
+    let x: u8 = 5;
+    let y: i64 = (1 + 2) * 3;
+    let z: f64 = 2.0;
+    println!("{} {} {} {} {}", a, f, x, y, z);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"a := 1\n",
+		"var f float32 = 1.5",
+		"var x uint8 = 5",
+		"var y int64 = (1 + 2) * 3",
+		"z := 2.0\n",
+	)
+	assertCompiles(t, goCode)
+}
+
+func TestGenerateOptionValues(t *testing.T) {
+	code := `
+fn find(a: i32) -> Option<i32> {
+    if a > 0 {
+        Some(a)
+    } else {
+        None
+    }
+}
+
+fn main() {
+    let o: Option<i32> = None;
+    let w: Option<i64> = Some(7);
+    let p = Some(5);
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"return rustSome(a)",
+		"return nil",
+		// nil не имеет типа: объявление берёт тип *T из аннотации
+		"var o *int\n",
+		"w := rustSome[int64](7)",
+		"p := rustSome(5)",
+		"func rustSome[T any](v T) *T {\n\treturn &v\n}",
+	)
+}
+
+func TestGenerateLabeledLoops(t *testing.T) {
+	code := `
+fn main() {
+    let xs = [1, 2, 3];
+    'outer: loop {
+        for x in xs {
+            if x == 2 { continue 'outer; }
+            break 'outer;
+        }
+    }
+    'unused: while true { break; }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		// loop — бесконечный цикл без условия, while true сохраняет условие
+		"outer:\n\tfor {",
+		"continue outer",
+		"break outer",
+		"\tfor true {\n\t\tbreak\n\t}\n",
+	)
+	// Go не допускает неиспользуемых меток
+	if strings.Contains(goCode, "unused") {
+		t.Errorf("Expected unreferenced label to be dropped, got:\n%s", goCode)
+	}
+}
+
+func TestGenerateNestedFunctions(t *testing.T) {
+	code := `
+fn main() {
+    let x = helper(2);
+    fn helper(n: i32) -> i32 {
+        n * 2
+    }
+    fn count(n: i32) -> i32 {
+        if n == 0 { 0 } else { count(n - 1) + 1 }
+    }
+    fn unused() {}
+    println!("{} {}", x, count(3));
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"helper := func(n int) int {\n\t\treturn n * 2\n\t}",
+		"var count func(int) int\n",
+		"count = func(n int) int {",
+		"return count(n - 1) + 1",
+		"_ = unused",
+		"x := helper(2)",
+	)
+	// Замыкание объявляется до первого вызова
+	if strings.Index(goCode, "helper := func") > strings.Index(goCode, "helper(2)") {
+		t.Errorf("Expected helper to be declared before its call, got:\n%s", goCode)
+	}
+}
+
+func TestGenerateStructPatternMatch(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+fn describe(p: Point) -> i32 {
+    match p {
+        Point { x: 0, y } => y,
+        Point { x, .. } => x,
+    }
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"case p.x == 0:\n\t\ty := p.y\n\t\treturn y",
+		"default:\n\t\tx := p.x\n\t\treturn x",
+	)
+	assertCompiles(t, goCode)
+}
+
+func TestGenerateExhaustiveEnumMatch(t *testing.T) {
+	code := `
+enum Color { Red, Green, Blue }
+
+fn code(c: Color) -> i32 {
+    match c {
+        Color::Red => 1,
+        Color::Green => 2,
+        Color::Blue => 3,
+    }
+}
+
+fn name(c: Color) -> i32 {
+    match c {
+        Color::Red => 0,
+        _ => 1,
+    }
+}
+`
+	goCode, err := backend.NewGenerator().GenerateFormatted(ir.NewTransformer().Transform(parseCode(code, t)))
+	if err != nil {
+		t.Fatalf("Generated enum match is not valid Go: %v\n%s", err, goCode)
+	}
+	want := `package lib
+
+type Color int
+
+const (
+	ColorRed Color = iota
+	ColorGreen
+	ColorBlue
+)
+
+func code(c Color) int {
+	switch c {
+	case ColorRed:
+		return 1
+	case ColorGreen:
+		return 2
+	case ColorBlue:
+		return 3
+	default:
+		panic("unreachable")
+	}
+}
+
+func name(c Color) int {
+	switch c {
+	case ColorRed:
+		return 0
+	default:
+		return 1
+	}
+}
+`
+	if goCode != want {
+		t.Errorf("Generated code mismatch.\nwant:\n%s\ngot:\n%s", want, goCode)
+	}
+}
+
+func TestGenerateTupleDestructuring(t *testing.T) {
+	code := `
+fn pair() -> (i32, bool) {
+    (1, true)
+}
+
+fn main() {
+    let t = (1, 2);
+    let (a, b) = t;
+    let (c, _) = (3, 4);
+    let (d, e) = pair();
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"a, b := t.Field0, t.Field1",
+		"c := 3",
+		// Результат вызова вычисляется один раз
+		"tuple1 := pair()\n\td, e := tuple1.Field0, tuple1.Field1",
+	)
+}
+
+func TestGenerateTupleAndUnitStructs(t *testing.T) {
+	code := `
+#[derive(Debug)]
+struct Wrapper(i32);
+
+struct Unit;
+
+fn main() {
+    let w = Wrapper(5);
+    let b = w.0;
+    let u = Unit;
+    let t = (1, 2);
+    let a = t.0;
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"type Wrapper struct {\n\tField0 int\n}",
+		"type Unit struct{}",
+		`return fmt.Sprintf("Wrapper(%v)", x.Field0)`,
+		"w := Wrapper{Field0: 5}",
+		"b := w.Field0",
+		"u := Unit{}",
+		"a := t.Field0",
+	)
+}
+
+func TestGenerateStringConcatenation(t *testing.T) {
+	code := `
+fn greet(name: String, suffix: &str) -> String {
+    let s = name + suffix;
+    s + "!"
+}
+`
+	goCode := generateCode(code, t)
+	assertContains(t, goCode,
+		"func greet(name string, suffix string) string",
+		"s := name + suffix",
+		`return s + "!"`,
+	)
+}
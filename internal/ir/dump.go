@@ -0,0 +1,375 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NodeKind — вид узла IR (оператора или выражения).
+type NodeKind int
+
+const (
+	KindUnknown NodeKind = iota
+	KindDeclaration
+	KindAssignment
+	KindReturn
+	KindMultiDeclaration
+	KindIf
+	KindBlock
+	KindWhile
+	KindFor
+	KindBreak
+	KindContinue
+	KindExprStmt
+	KindVar
+	KindLiteral
+	KindBinary
+	KindUnary
+	KindCall
+	KindTuple
+	KindMatch
+	KindMethodCall
+	KindField
+	KindIndex
+	KindArrayLit
+	KindArrayRepeat
+	KindStructLit
+	KindRange
+	KindUnwrap
+	KindClosure
+	KindCast
+	KindVariant
+	KindSome
+)
+
+// kindNames — имена видов узлов для отладочного вывода.
+var kindNames = [...]string{
+	KindUnknown:          "Unknown",
+	KindDeclaration:      "Declaration",
+	KindAssignment:       "Assignment",
+	KindReturn:           "Return",
+	KindMultiDeclaration: "MultiDeclaration",
+	KindIf:               "If",
+	KindBlock:            "Block",
+	KindWhile:            "While",
+	KindFor:              "For",
+	KindBreak:            "Break",
+	KindContinue:         "Continue",
+	KindExprStmt:         "ExprStmt",
+	KindVar:              "Var",
+	KindLiteral:          "Literal",
+	KindBinary:           "Binary",
+	KindUnary:            "Unary",
+	KindCall:             "Call",
+	KindTuple:            "Tuple",
+	KindMatch:            "Match",
+	KindMethodCall:       "MethodCall",
+	KindField:            "Field",
+	KindIndex:            "Index",
+	KindArrayLit:         "ArrayLit",
+	KindArrayRepeat:      "ArrayRepeat",
+	KindStructLit:        "StructLit",
+	KindRange:            "Range",
+	KindUnwrap:           "Unwrap",
+	KindClosure:          "Closure",
+	KindCast:             "Cast",
+	KindVariant:          "Variant",
+	KindSome:             "Some",
+}
+
+// String возвращает имя вида узла.
+func (k NodeKind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return kindNames[KindUnknown]
+	}
+	return kindNames[k]
+}
+
+// KindOf возвращает вид узла IR: оператора (Statement) или выражения (Expression).
+func KindOf(node any) NodeKind {
+	switch node.(type) {
+	case *Declaration:
+		return KindDeclaration
+	case *Assignment:
+		return KindAssignment
+	case *Return:
+		return KindReturn
+	case *MultiDeclaration:
+		return KindMultiDeclaration
+	case *If:
+		return KindIf
+	case *Block:
+		return KindBlock
+	case *While:
+		return KindWhile
+	case *For:
+		return KindFor
+	case *Break:
+		return KindBreak
+	case *Continue:
+		return KindContinue
+	case *ExprStmt:
+		return KindExprStmt
+	case *VarExpr:
+		return KindVar
+	case *LiteralExpr:
+		return KindLiteral
+	case *BinaryExpr:
+		return KindBinary
+	case *UnaryExpr:
+		return KindUnary
+	case *CallExpr:
+		return KindCall
+	case *TupleExpr:
+		return KindTuple
+	case *MatchExpr:
+		return KindMatch
+	case *MethodCall:
+		return KindMethodCall
+	case *FieldExpr:
+		return KindField
+	case *IndexExpr:
+		return KindIndex
+	case *ArrayLit:
+		return KindArrayLit
+	case *ArrayRepeat:
+		return KindArrayRepeat
+	case *StructLit:
+		return KindStructLit
+	case *RangeExpr:
+		return KindRange
+	case *UnwrapExpr:
+		return KindUnwrap
+	case *SomeExpr:
+		return KindSome
+	case *CastExpr:
+		return KindCast
+	case *ClosureExpr:
+		return KindClosure
+	case *VariantExpr:
+		return KindVariant
+	}
+	return KindUnknown
+}
+
+// Dump возвращает отладочное представление модуля: структуры, сигнатуры функций
+// и их тела в виде дерева с отступами, аналогично ast.PrettyPrint.
+// Для выражений после двоеточия указывается их тип в Go.
+func Dump(m *Module) string {
+	d := &dumper{}
+	d.line(0, "Module %s", m.PackageName)
+	for _, st := range m.Structs {
+		d.line(1, "Struct %s", st.Name)
+		for _, f := range st.Fields {
+			d.line(2, "Field %s %s", f.Name, f.Type)
+		}
+	}
+	for _, en := range m.Enums {
+		d.line(1, "Enum %s %s", en.Name, strings.Join(en.Variants, " "))
+	}
+	for _, fn := range m.Functions {
+		params := make([]string, 0, len(fn.Params))
+		for _, p := range fn.Params {
+			params = append(params, fmt.Sprintf("%s %s", p.Name, p.Type))
+		}
+		sig := fmt.Sprintf("Function %s(%s)", fn.Name, strings.Join(params, ", "))
+		if fn.GoReceiver != "" {
+			sig = fmt.Sprintf("Function (%s) %s(%s)", fn.GoReceiver, fn.Name, strings.Join(params, ", "))
+		}
+		if fn.ReturnType != nil && !fn.ReturnType.IsUnit() {
+			sig += " " + fn.ReturnType.String()
+		}
+		d.line(1, "%s", sig)
+		d.stmts(2, fn.Body)
+	}
+	return d.sb.String()
+}
+
+// dumper накапливает вывод Dump.
+type dumper struct {
+	sb strings.Builder
+}
+
+// line выводит строку с отступом depth.
+func (d *dumper) line(depth int, format string, args ...any) {
+	d.sb.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(&d.sb, format, args...)
+	d.sb.WriteByte('\n')
+}
+
+// stmts выводит список операторов.
+func (d *dumper) stmts(depth int, stmts []Statement) {
+	for _, s := range stmts {
+		d.stmt(depth, s)
+	}
+}
+
+// stmt выводит оператор и его вложенные выражения и блоки.
+func (d *dumper) stmt(depth int, stmt Statement) {
+	kind := KindOf(stmt)
+	switch s := stmt.(type) {
+	case *Declaration:
+		d.line(depth, "%s %s %s", kind, s.Name, s.Type)
+		d.expr(depth+1, s.InitValue)
+	case *MultiDeclaration:
+		d.line(depth, "%s %s", kind, strings.Join(s.Names, ", "))
+		d.expr(depth+1, s.InitValue)
+		d.exprs(depth+1, s.Values)
+	case *Assignment:
+		d.line(depth, "%s", kind)
+		d.expr(depth+1, s.Target)
+		d.expr(depth+1, s.Value)
+	case *Return:
+		d.line(depth, "%s", kind)
+		d.expr(depth+1, s.Value)
+		d.expr(depth+1, s.Err)
+	case *While:
+		d.line(depth, "%s", kind)
+		d.expr(depth+1, s.Cond)
+		d.stmts(depth+1, s.Body)
+	case *For:
+		d.line(depth, "%s %s", kind, s.Var)
+		d.expr(depth+1, s.Iter)
+		d.stmts(depth+1, s.Body)
+	case *ExprStmt:
+		d.line(depth, "%s", kind)
+		d.expr(depth+1, s.Expr)
+	case *If, *Block:
+		d.expr(depth, s.(Expression))
+	default:
+		d.line(depth, "%s", kind)
+	}
+}
+
+// expr выводит выражение с его типом и подвыражения. Отсутствующее (nil) выражение пропускается.
+func (d *dumper) expr(depth int, expr Expression) {
+	if expr == nil {
+		return
+	}
+	label := KindOf(expr).String()
+	switch e := expr.(type) {
+	case *VarExpr:
+		label += " " + e.Name
+	case *LiteralExpr:
+		label += " " + e.Value
+	case *BinaryExpr:
+		label += " " + e.Op
+	case *UnaryExpr:
+		label += " " + e.Op
+	case *CallExpr:
+		label += " " + e.FuncName
+	case *MethodCall:
+		label += " " + e.Method
+	case *FieldExpr:
+		label += " " + e.Field
+	case *StructLit:
+		label += " " + e.Name
+	case *UnwrapExpr:
+		label += " " + e.Method
+	case *VariantExpr:
+		label += " " + e.Enum + "::" + e.Variant
+	case *RangeExpr:
+		if e.Inclusive {
+			label += " inclusive"
+		}
+	}
+	if t := expr.Type(); t != nil {
+		label += " : " + t.String()
+	}
+	d.line(depth, "%s", label)
+
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		d.expr(depth+1, e.Left)
+		d.expr(depth+1, e.Right)
+	case *UnaryExpr:
+		d.expr(depth+1, e.Expr)
+	case *CastExpr:
+		d.expr(depth+1, e.Expr)
+	case *CallExpr:
+		d.exprs(depth+1, e.Args)
+	case *MethodCall:
+		d.expr(depth+1, e.Receiver)
+		d.exprs(depth+1, e.Args)
+	case *TupleExpr:
+		d.exprs(depth+1, e.Elems)
+	case *ArrayLit:
+		d.exprs(depth+1, e.Elems)
+	case *ArrayRepeat:
+		d.expr(depth+1, e.Value)
+		d.expr(depth+1, e.Len)
+	case *FieldExpr:
+		d.expr(depth+1, e.Receiver)
+	case *IndexExpr:
+		d.expr(depth+1, e.Expr)
+		d.expr(depth+1, e.Index)
+	case *StructLit:
+		for _, f := range e.Fields {
+			d.line(depth+1, "%s:", f.Name)
+			d.expr(depth+2, f.Value)
+		}
+	case *RangeExpr:
+		d.expr(depth+1, e.Start)
+		d.expr(depth+1, e.End)
+	case *UnwrapExpr:
+		d.expr(depth+1, e.Expr)
+		d.expr(depth+1, e.Message)
+	case *SomeExpr:
+		d.expr(depth+1, e.Value)
+	case *MatchExpr:
+		d.expr(depth+1, e.Scrutinee)
+		for _, arm := range e.Arms {
+			d.line(depth+1, "Arm %s", patternString(arm.Pattern))
+			d.expr(depth+2, arm.Body)
+		}
+	case *If:
+		d.expr(depth+1, e.Cond)
+		d.line(depth+1, "Then")
+		d.stmts(depth+2, e.Then)
+		if len(e.Else) > 0 {
+			d.line(depth+1, "Else")
+			d.stmts(depth+2, e.Else)
+		}
+	case *Block:
+		d.stmts(depth+1, e.Stmts)
+	case *ClosureExpr:
+		for _, p := range e.Params {
+			d.line(depth+1, "Param %s %s", p.Name, p.Type)
+		}
+		d.stmts(depth+1, e.Body)
+	}
+}
+
+// exprs выводит список выражений.
+func (d *dumper) exprs(depth int, exprs []Expression) {
+	for _, e := range exprs {
+		d.expr(depth, e)
+	}
+}
+
+// patternString возвращает образец ветви match в записи Rust.
+func patternString(pat Pattern) string {
+	switch p := pat.(type) {
+	case *WildcardPattern:
+		return "_"
+	case *BindingPattern:
+		return p.Name
+	case *LiteralPattern:
+		return p.Value
+	case *TuplePattern:
+		elems := make([]string, 0, len(p.Elems))
+		for _, e := range p.Elems {
+			elems = append(elems, patternString(e))
+		}
+		return "(" + strings.Join(elems, ", ") + ")"
+	case *VariantPattern:
+		return p.Enum + "::" + p.Variant
+	case *StructPattern:
+		fields := make([]string, 0, len(p.Fields))
+		for _, f := range p.Fields {
+			fields = append(fields, f.Name+": "+patternString(f.Pattern))
+		}
+		return p.Name + " { " + strings.Join(fields, ", ") + ", .. }"
+	}
+	return "?"
+}
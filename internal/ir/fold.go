@@ -0,0 +1,390 @@
+// Package ir предоставляет проход свёртки констант над IR.
+package ir
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/semetekare/rust2go/internal/ast"
+)
+
+// Fold упрощает константные выражения во всех функциях модуля:
+// `2 + 3` -> `5`, `!true` -> `false`, `(1 + 2) * 3` -> `9`.
+// Выражение сворачивается, только если все его операнды — литералы;
+// тип результата сохраняется (int остаётся int, float — float).
+func Fold(module *Module) {
+	for _, fn := range module.Functions {
+		foldStmts(fn.Body)
+	}
+}
+
+// foldStmts сворачивает константы в каждом операторе списка.
+func foldStmts(stmts []Statement) {
+	for _, stmt := range stmts {
+		foldStmt(stmt)
+	}
+}
+
+// foldStmt сворачивает константы в выражениях оператора.
+func foldStmt(stmt Statement) {
+	switch s := stmt.(type) {
+	case *Declaration:
+		s.InitValue = foldExpr(s.InitValue)
+	case *MultiDeclaration:
+		s.InitValue = foldExpr(s.InitValue)
+		foldExprs(s.Values)
+	case *Assignment:
+		s.Target = foldExpr(s.Target)
+		s.Value = foldExpr(s.Value)
+	case *Return:
+		s.Value = foldExpr(s.Value)
+	case *ExprStmt:
+		s.Expr = foldExpr(s.Expr)
+	case *If:
+		foldExpr(s)
+	case *Block:
+		foldStmts(s.Stmts)
+	case *While:
+		s.Cond = foldExpr(s.Cond)
+		foldStmts(s.Body)
+	case *For:
+		s.Iter = foldExpr(s.Iter)
+		foldStmts(s.Body)
+	}
+}
+
+// foldExpr рекурсивно сворачивает константы в дереве выражения
+// и возвращает упрощённое выражение (или исходное, если свернуть нельзя).
+func foldExpr(expr Expression) Expression {
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		e.Left = foldExpr(e.Left)
+		e.Right = foldExpr(e.Right)
+		if folded := foldBinary(e); folded != nil {
+			return folded
+		}
+	case *UnaryExpr:
+		e.Expr = foldExpr(e.Expr)
+		if folded := foldUnary(e); folded != nil {
+			return folded
+		}
+	case *CastExpr:
+		// Приведение остаётся, а константный операнд заменяется значением по правилам
+		// Rust: Go не преобразует константы с потерей (`int(3.5)`, `uint8(-1)`)
+		e.Expr = foldExpr(e.Expr)
+		if folded := foldCast(e); folded != nil {
+			e.Expr = folded
+		}
+	case *CallExpr:
+		foldExprs(e.Args)
+	case *MethodCall:
+		e.Receiver = foldExpr(e.Receiver)
+		foldExprs(e.Args)
+	case *TupleExpr:
+		foldExprs(e.Elems)
+	case *ArrayLit:
+		foldExprs(e.Elems)
+	case *ArrayRepeat:
+		e.Value = foldExpr(e.Value)
+		e.Len = foldExpr(e.Len)
+	case *StructLit:
+		for _, field := range e.Fields {
+			field.Value = foldExpr(field.Value)
+		}
+	case *MatchExpr:
+		e.Scrutinee = foldExpr(e.Scrutinee)
+		for _, arm := range e.Arms {
+			arm.Body = foldExpr(arm.Body)
+		}
+	case *SomeExpr:
+		e.Value = foldExpr(e.Value)
+	case *UnwrapExpr:
+		e.Expr = foldExpr(e.Expr)
+		e.Message = foldExpr(e.Message)
+	case *If:
+		e.Cond = foldExpr(e.Cond)
+		foldStmts(e.Then)
+		foldStmts(e.Else)
+	case *Block:
+		foldStmts(e.Stmts)
+	case *FieldExpr:
+		e.Receiver = foldExpr(e.Receiver)
+	case *IndexExpr:
+		e.Expr = foldExpr(e.Expr)
+		e.Index = foldExpr(e.Index)
+	case *RangeExpr:
+		e.Start = foldExpr(e.Start)
+		e.End = foldExpr(e.End)
+	case *ClosureExpr:
+		foldStmts(e.Body)
+	}
+	return expr
+}
+
+// foldExprs сворачивает константы в каждом выражении списка на месте.
+func foldExprs(exprs []Expression) {
+	for i, expr := range exprs {
+		exprs[i] = foldExpr(expr)
+	}
+}
+
+// foldBinary вычисляет бинарное выражение над двумя литералами.
+// Возвращает nil, если операнды не литералы или операция не сворачивается
+// (например, деление на ноль или смешение int и float).
+func foldBinary(b *BinaryExpr) Expression {
+	left, ok := b.Left.(*LiteralExpr)
+	if !ok {
+		return nil
+	}
+	right, ok := b.Right.(*LiteralExpr)
+	if !ok || left.Kind != right.Kind {
+		return nil
+	}
+	// Тип результата задаёт операнд с суффиксом: `2 + 3u8` имеет тип u8
+	typed := left
+	if left.Suffix == "" {
+		typed = right
+	}
+
+	switch left.Kind {
+	case "INT":
+		x, okX := parseIntLiteral(left.Value)
+		y, okY := parseIntLiteral(right.Value)
+		if !okX || !okY {
+			return nil
+		}
+		switch b.Op {
+		case "+":
+			return intLiteral(x+y, typed, b)
+		case "-":
+			return intLiteral(x-y, typed, b)
+		case "*":
+			return intLiteral(x*y, typed, b)
+		case "/":
+			if y == 0 {
+				return nil
+			}
+			return intLiteral(x/y, typed, b)
+		case "%":
+			if y == 0 {
+				return nil
+			}
+			return intLiteral(x%y, typed, b)
+		}
+		return compareLiterals(b, compareOrdered(x, y))
+	case "FLOAT":
+		x, okX := parseFloatLiteral(left.Value)
+		y, okY := parseFloatLiteral(right.Value)
+		if !okX || !okY {
+			return nil
+		}
+		switch b.Op {
+		case "+":
+			return floatLiteral(x+y, typed, b)
+		case "-":
+			return floatLiteral(x-y, typed, b)
+		case "*":
+			return floatLiteral(x*y, typed, b)
+		case "/":
+			if y == 0 {
+				return nil
+			}
+			return floatLiteral(x/y, typed, b)
+		}
+		return compareLiterals(b, compareOrdered(x, y))
+	case "BOOL":
+		x, y := left.Value == "true", right.Value == "true"
+		switch b.Op {
+		case "&&":
+			return boolLiteral(x && y, b)
+		case "||":
+			return boolLiteral(x || y, b)
+		case "==":
+			return boolLiteral(x == y, b)
+		case "!=":
+			return boolLiteral(x != y, b)
+		}
+	}
+	return nil
+}
+
+// foldUnary вычисляет унарное выражение над литералом: `-5`, `!true`.
+func foldUnary(u *UnaryExpr) Expression {
+	lit, ok := u.Expr.(*LiteralExpr)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case u.Op == "!" && lit.Kind == "BOOL":
+		return boolLiteral(lit.Value != "true", u)
+	case u.Op == "-" && lit.Kind == "INT":
+		if x, ok := parseIntLiteral(lit.Value); ok {
+			return intLiteral(-x, lit, u)
+		}
+	case u.Op == "-" && lit.Kind == "FLOAT":
+		if x, ok := parseFloatLiteral(lit.Value); ok {
+			return floatLiteral(-x, lit, u)
+		}
+	}
+	return nil
+}
+
+// intTypes — разрядность и знаковость целых типов Go. int соответствует i32 Rust
+// (см. MapRustToGoType), поэтому приводится как 32-битный.
+var intTypes = map[string]struct {
+	bits   uint
+	signed bool
+}{
+	"int8": {8, true}, "int16": {16, true}, "int": {32, true}, "int32": {32, true}, "int64": {64, true},
+	"uint8": {8, false}, "uint16": {16, false}, "uint32": {32, false}, "uint64": {64, false},
+}
+
+// foldCast вычисляет приведение литерала к целому типу, как это делает Rust:
+// целое усекается до разрядности типа (`-1 as u8` — 255), дробное отбрасывает
+// дробную часть и насыщается на границах типа (`3.9 as i32` — 3, `1e10 as u8` — 255,
+// NaN — 0). Возвращает литерал целевого типа или nil, если приведение не сворачивается.
+func foldCast(c *CastExpr) Expression {
+	lit, ok := c.Expr.(*LiteralExpr)
+	if !ok || c.TypeInfo == nil {
+		return nil
+	}
+	target, ok := intTypes[c.TypeInfo.Name]
+	if !ok {
+		return nil
+	}
+	// Границы типа: точные значения и их степени двойки для сравнения с float64
+	minValue, maxValue := "0", strconv.FormatUint(math.MaxUint64>>(64-target.bits), 10)
+	lower, upper := 0.0, math.Ldexp(1, int(target.bits))
+	if target.signed {
+		minValue = strconv.FormatInt(math.MinInt64>>(64-target.bits), 10)
+		maxValue = strconv.FormatInt(math.MaxInt64>>(64-target.bits), 10)
+		lower, upper = -math.Ldexp(1, int(target.bits)-1), math.Ldexp(1, int(target.bits)-1)
+	}
+
+	var value string
+	switch lit.Kind {
+	case "INT":
+		x, ok := parseIntLiteral(lit.Value)
+		if !ok {
+			return nil
+		}
+		bits := uint64(x)
+		if target.bits < 64 {
+			bits &= 1<<target.bits - 1
+		}
+		if !target.signed {
+			value = strconv.FormatUint(bits, 10)
+		} else {
+			// Знаковый результат: старший бит разрядности задаёт знак
+			shift := 64 - target.bits
+			value = strconv.FormatInt(int64(bits<<shift)>>shift, 10)
+		}
+	case "FLOAT":
+		x, ok := parseFloatLiteral(lit.Value)
+		if !ok {
+			return nil
+		}
+		switch x = math.Trunc(x); {
+		case math.IsNaN(x):
+			value = "0"
+		case x < lower:
+			value = minValue
+		case x >= upper:
+			value = maxValue
+		default:
+			value = strconv.FormatFloat(x, 'f', -1, 64)
+		}
+	default:
+		return nil
+	}
+	return &LiteralExpr{Value: value, Kind: "INT", TypeInfo: c.TypeInfo, Position: lit.Position}
+}
+
+// compareOrdered сравнивает два числа: -1, 0 или 1.
+func compareOrdered[T int64 | float64](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compareLiterals сворачивает оператор сравнения по результату compareOrdered.
+func compareLiterals(b *BinaryExpr, cmp int) Expression {
+	switch b.Op {
+	case "==":
+		return boolLiteral(cmp == 0, b)
+	case "!=":
+		return boolLiteral(cmp != 0, b)
+	case "<":
+		return boolLiteral(cmp < 0, b)
+	case "<=":
+		return boolLiteral(cmp <= 0, b)
+	case ">":
+		return boolLiteral(cmp > 0, b)
+	case ">=":
+		return boolLiteral(cmp >= 0, b)
+	}
+	return nil
+}
+
+// parseIntLiteral разбирает целочисленный литерал Rust (с учётом `_`, суффикса типа
+// и префиксов 0x, 0o, 0b).
+func parseIntLiteral(value string) (int64, bool) {
+	value, _ = ast.NumberSuffix(value)
+	value = strings.ReplaceAll(value, "_", "")
+	x, err := strconv.ParseInt(value, 0, 64)
+	return x, err == nil
+}
+
+// parseFloatLiteral разбирает литерал с плавающей точкой (с учётом `_` и суффикса типа).
+func parseFloatLiteral(value string) (float64, bool) {
+	value, _ = ast.NumberSuffix(value)
+	x, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64)
+	return x, err == nil
+}
+
+// intLiteral создаёт целочисленный литерал с типом исходного операнда.
+func intLiteral(x int64, operand *LiteralExpr, at Expression) Expression {
+	return &LiteralExpr{
+		Value:    strconv.FormatInt(x, 10),
+		Kind:     "INT",
+		Suffix:   operand.Suffix,
+		TypeInfo: operand.TypeInfo,
+		Position: at.Pos(),
+	}
+}
+
+// floatLiteral создаёт литерал с плавающей точкой с типом исходного операнда.
+// Результат всегда содержит точку или экспоненту, чтобы в Go остаться float.
+// Бесконечность и NaN не имеют литерала в Go, поэтому такие выражения не сворачиваются.
+func floatLiteral(x float64, operand *LiteralExpr, at Expression) Expression {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return nil
+	}
+	value := strconv.FormatFloat(x, 'g', -1, 64)
+	if !strings.ContainsAny(value, ".e") {
+		value += ".0"
+	}
+	return &LiteralExpr{
+		Value:    value,
+		Kind:     "FLOAT",
+		Suffix:   operand.Suffix,
+		TypeInfo: operand.TypeInfo,
+		Position: at.Pos(),
+	}
+}
+
+// boolLiteral создаёт булев литерал.
+func boolLiteral(x bool, at Expression) Expression {
+	return &LiteralExpr{
+		Value:    strconv.FormatBool(x),
+		Kind:     "BOOL",
+		TypeInfo: NewType("bool", true),
+		Position: at.Pos(),
+	}
+}
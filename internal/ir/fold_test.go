@@ -0,0 +1,117 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/semetekare/rust2go/internal/ir"
+)
+
+// foldedInit возвращает свёрнутый инициализатор первого объявления в функции main.
+func foldedInit(code string, t *testing.T) ir.Expression {
+	t.Helper()
+	module := transformCode(code, t)
+	ir.Fold(module)
+	decl, ok := module.Functions[0].Body[0].(*ir.Declaration)
+	if !ok {
+		t.Fatalf("Expected Declaration, got %T", module.Functions[0].Body[0])
+	}
+	return decl.InitValue
+}
+
+func TestFoldConstantExpression(t *testing.T) {
+	init := foldedInit(`fn main() { let x = (1 + 2) * 3; }`, t)
+	lit, ok := init.(*ir.LiteralExpr)
+	if !ok {
+		t.Fatalf("Expected LiteralExpr, got %T", init)
+	}
+	if lit.Value != "9" || lit.Kind != "INT" || lit.Type().Name != "int" {
+		t.Errorf("Expected int literal 9, got %s %q of type %s", lit.Kind, lit.Value, lit.Type())
+	}
+}
+
+func TestFoldPreservesFloatAndBool(t *testing.T) {
+	tests := []struct {
+		code  string
+		value string
+		typ   string
+	}{
+		{`fn main() { let x = 1.5 * 2.0; }`, "3.0", "float64"},
+		{`fn main() { let x = !true; }`, "false", "bool"},
+		{`fn main() { let x = 7 / 2 < 4; }`, "true", "bool"},
+	}
+	for _, tt := range tests {
+		lit, ok := foldedInit(tt.code, t).(*ir.LiteralExpr)
+		if !ok {
+			t.Errorf("%s: expected LiteralExpr", tt.code)
+			continue
+		}
+		if lit.Value != tt.value || lit.Type().Name != tt.typ {
+			t.Errorf("%s: expected %s of type %s, got %s of type %s", tt.code, tt.value, tt.typ, lit.Value, lit.Type())
+		}
+	}
+}
+
+func TestFoldConstantCasts(t *testing.T) {
+	tests := []struct {
+		code  string
+		value string
+		typ   string
+	}{
+		// Дробное отбрасывает дробную часть и насыщается на границах типа
+		{`fn main() { let x = 3.9 as i32; }`, "3", "int"},
+		{`fn main() { let x = -3.9 as i64; }`, "-3", "int64"},
+		{`fn main() { let x = 1e10 as u8; }`, "255", "uint8"},
+		{`fn main() { let x = -1.5 as u32; }`, "0", "uint32"},
+		{`fn main() { let x = 1e30 as u64; }`, "18446744073709551615", "uint64"},
+		// Целое усекается до разрядности типа
+		{`fn main() { let x = -1 as u8; }`, "255", "uint8"},
+		{`fn main() { let x = -1 as u64; }`, "18446744073709551615", "uint64"},
+		{`fn main() { let x = 300 as i8; }`, "44", "int8"},
+		// Суффикс типа операнда не мешает свёртке
+		{`fn main() { let x = 255u8 as i32; }`, "255", "int"},
+		{`fn main() { let x = 0x1_00u16 as u8; }`, "0", "uint8"},
+		{`fn main() { let x = 2.9f32 as u8; }`, "2", "uint8"},
+	}
+	for _, tt := range tests {
+		cast, ok := foldedInit(tt.code, t).(*ir.CastExpr)
+		if !ok {
+			t.Errorf("%s: expected CastExpr", tt.code)
+			continue
+		}
+		lit, ok := cast.Expr.(*ir.LiteralExpr)
+		if !ok || lit.Value != tt.value || lit.Type().Name != tt.typ {
+			t.Errorf("%s: expected operand %s of type %s, got %v", tt.code, tt.value, tt.typ, cast.Expr)
+		}
+	}
+}
+
+func TestFoldSuffixedLiterals(t *testing.T) {
+	tests := []struct {
+		code   string
+		value  string
+		suffix string
+		typ    string
+	}{
+		{`fn main() { let x = 2 + 3u8; }`, "5", "u8", "uint8"},
+		{`fn main() { let x = 1_000i64 * 2; }`, "2000", "i64", "int64"},
+		{`fn main() { let x = -5i8; }`, "-5", "i8", "int8"},
+		{`fn main() { let x = 1.5f32 + 1.0; }`, "2.5", "f32", "float32"},
+	}
+	for _, tt := range tests {
+		lit, ok := foldedInit(tt.code, t).(*ir.LiteralExpr)
+		if !ok || lit.Value != tt.value || lit.Suffix != tt.suffix || lit.Type().Name != tt.typ {
+			t.Errorf("%s: expected %s%s of type %s, got %#v", tt.code, tt.value, tt.suffix, tt.typ, lit)
+		}
+	}
+}
+
+func TestFoldLeavesVariablesUnchanged(t *testing.T) {
+	init := foldedInit(`fn main(a: i32) { let x = a + 1; }`, t)
+	bin, ok := init.(*ir.BinaryExpr)
+	if !ok {
+		t.Fatalf("Expected BinaryExpr, got %T", init)
+	}
+	if left, ok := bin.Left.(*ir.LiteralExpr); !ok || left.Value != "a" {
+		t.Errorf("Expected left operand a, got %#v", bin.Left)
+	}
+}
@@ -3,6 +3,9 @@
 package ir
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/semetekare/rust2go/internal/token"
 )
 
@@ -11,24 +14,59 @@ type Module struct {
 	Name        string      // Имя модуля
 	Functions   []*Function // Функции модуля
 	Structs     []*Struct   // Структуры модуля
+	Enums       []*Enum     // C-подобные перечисления модуля
 	PackageName string      // Имя пакета Go
+	PositionMap PositionMap // Карта строк сгенерированного кода (заполняется бэкендом)
+}
+
+// HasMain сообщает, есть ли в модуле точка входа программы — свободная функция main.
+func (m *Module) HasMain() bool {
+	for _, fn := range m.Functions {
+		if fn.Name == "main" && fn.GoReceiver == "" {
+			return true
+		}
+	}
+	return false
 }
 
+// PositionMap сопоставляет номерам строк сгенерированного Go-кода (начиная с 1)
+// позиции исходного Rust-кода, из которых эти строки получены. По ней отладчики
+// и трассировки стека могут указывать на строки .rs файла.
+type PositionMap map[int]token.Position
+
+// ReceiverName — имя приёмника Go, в которое переводится `self` в методах.
+const ReceiverName = "recv"
+
 // Function представляет IR-функцию.
 type Function struct {
 	Name       string         // Имя функции
+	TypeParams []string       // Обобщённые параметры типа
 	Params     []*Parameter   // Параметры функции
 	ReturnType *Type          // Возвращаемый тип
 	Body       []Statement    // Тело функции (список операторов)
 	Pos        token.Position // Позиция в исходном коде
 	GoPackage  string         // Пакет Go для экспорта
 	GoReceiver string         // Приёмник для методов (если есть)
+	AssocType  string         // Тип блока impl для ассоциированной функции без self
+	Public     bool           // Функция объявлена как pub
+
+	Bounds map[string][]string // Ограничения-трейты параметров типа: T -> [Clone, Ord]
+}
+
+// QualifiedName возвращает имя, под которым функция вызывается в Rust:
+// `Point::new` для ассоциированной функции, иначе просто имя.
+func (f *Function) QualifiedName() string {
+	if f.AssocType != "" {
+		return f.AssocType + "::" + f.Name
+	}
+	return f.Name
 }
 
 // Parameter представляет параметр функции.
 type Parameter struct {
-	Name string // Имя параметра
-	Type *Type  // Тип параметра
+	Name    string // Имя параметра
+	Type    *Type  // Тип параметра
+	Mutable bool   // Параметр объявлен как `mut`
 }
 
 // Statement представляет оператор в IR.
@@ -42,6 +80,7 @@ type Declaration struct {
 	Name      string
 	Type      *Type
 	InitValue Expression
+	Mutable   bool // Переменная объявлена как `let mut`
 	Position  token.Position
 }
 
@@ -50,7 +89,7 @@ func (d *Declaration) Pos() token.Position { return d.Position }
 
 // Assignment представляет присваивание.
 type Assignment struct {
-	Target   string
+	Target   Expression // Переменная, поле или элемент коллекции
 	Value    Expression
 	Position token.Position
 }
@@ -59,14 +98,102 @@ func (a *Assignment) stmtNode()           {}
 func (a *Assignment) Pos() token.Position { return a.Position }
 
 // Return представляет возврат значения.
+// Для функций, возвращающих Result, Err — возвращаемая ошибка (nil — успешный возврат);
+// отсутствующее Value при этом заменяется нулевым значением типа.
 type Return struct {
 	Value    Expression
+	Err      Expression
 	Position token.Position
 }
 
 func (r *Return) stmtNode()           {}
 func (r *Return) Pos() token.Position { return r.Position }
 
+// MultiDeclaration представляет объявление нескольких переменных из одного выражения
+// (`value, err := f()`) или, если задан Values, по значению на каждое имя (`a, b := x, y`).
+type MultiDeclaration struct {
+	Names     []string
+	InitValue Expression
+	Values    []Expression
+	Position  token.Position
+}
+
+func (m *MultiDeclaration) stmtNode()           {}
+func (m *MultiDeclaration) Pos() token.Position { return m.Position }
+
+// If представляет условный оператор. Else может быть пустым.
+// Как выражение (`let x = if c { 1 } else { 2 }`) If имеет значение
+// хвостовых выражений своих ветвей.
+type If struct {
+	Cond     Expression
+	Then     []Statement
+	Else     []Statement
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (i *If) stmtNode()           {}
+func (i *If) exprNode()           {}
+func (i *If) Type() *Type         { return i.TypeInfo }
+func (i *If) Pos() token.Position { return i.Position }
+
+// Block представляет вложенный блок `{ ... }` с собственной областью видимости.
+// Как выражение Block имеет значение своего хвостового выражения.
+type Block struct {
+	Stmts    []Statement
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (b *Block) stmtNode()           {}
+func (b *Block) exprNode()           {}
+func (b *Block) Type() *Type         { return b.TypeInfo }
+func (b *Block) Pos() token.Position { return b.Position }
+
+// While представляет цикл с условием `while cond { ... }`.
+// Бесконечный цикл `loop { ... }` не имеет условия (Cond == nil).
+type While struct {
+	Cond     Expression
+	Body     []Statement
+	Label    string // Метка цикла Rust (`'outer`), если на неё ссылаются break или continue
+	Position token.Position
+}
+
+func (w *While) stmtNode()           {}
+func (w *While) Pos() token.Position { return w.Position }
+
+// For представляет цикл `for var in iter { ... }`. Если Iter — RangeExpr,
+// цикл счётный; иначе выполняется обход элементов коллекции.
+type For struct {
+	Var      string
+	Iter     Expression
+	Body     []Statement
+	Label    string // Метка цикла Rust, если на неё ссылаются break или continue
+	Position token.Position
+}
+
+func (f *For) stmtNode()           {}
+func (f *For) Pos() token.Position { return f.Position }
+
+// Break представляет выход из ближайшего цикла или из цикла с меткой Label.
+type Break struct {
+	Label    string
+	Position token.Position
+}
+
+func (b *Break) stmtNode()           {}
+func (b *Break) Pos() token.Position { return b.Position }
+
+// Continue представляет переход к следующей итерации ближайшего цикла
+// или цикла с меткой Label.
+type Continue struct {
+	Label    string
+	Position token.Position
+}
+
+func (c *Continue) stmtNode()           {}
+func (c *Continue) Pos() token.Position { return c.Position }
+
 // Expression представляет выражение в IR.
 type Expression interface {
 	exprNode()
@@ -89,6 +216,7 @@ func (v *VarExpr) Pos() token.Position { return v.Position }
 type LiteralExpr struct {
 	Value    string
 	Kind     string // "INT", "FLOAT", "STRING", "BOOL"
+	Suffix   string // Суффикс типа числового литерала Rust (`5u8` — "u8"); тип задаёт TypeInfo
 	TypeInfo *Type
 	Position token.Position
 }
@@ -122,6 +250,17 @@ func (u *UnaryExpr) exprNode()           {}
 func (u *UnaryExpr) Type() *Type         { return u.TypeInfo }
 func (u *UnaryExpr) Pos() token.Position { return u.Position }
 
+// CastExpr представляет приведение `expr as T`; TypeInfo — целевой тип.
+type CastExpr struct {
+	Expr     Expression
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (c *CastExpr) exprNode()           {}
+func (c *CastExpr) Type() *Type         { return c.TypeInfo }
+func (c *CastExpr) Pos() token.Position { return c.Position }
+
 // CallExpr представляет вызов функции.
 type CallExpr struct {
 	FuncName string
@@ -135,6 +274,244 @@ func (c *CallExpr) exprNode()           {}
 func (c *CallExpr) Type() *Type         { return c.TypeInfo }
 func (c *CallExpr) Pos() token.Position { return c.Position }
 
+// TupleExpr представляет кортежное значение.
+// В Go кортеж представляется анонимной структурой с полями Field0, Field1, ...
+type TupleExpr struct {
+	Elems    []Expression
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (t *TupleExpr) exprNode()           {}
+func (t *TupleExpr) Type() *Type         { return t.TypeInfo }
+func (t *TupleExpr) Pos() token.Position { return t.Position }
+
+// MatchExpr представляет сопоставление с образцом.
+type MatchExpr struct {
+	Scrutinee Expression
+	Arms      []*MatchArm
+	TypeInfo  *Type
+	Position  token.Position
+}
+
+func (m *MatchExpr) exprNode()           {}
+func (m *MatchExpr) Type() *Type         { return m.TypeInfo }
+func (m *MatchExpr) Pos() token.Position { return m.Position }
+
+// MatchArm представляет ветвь сопоставления.
+type MatchArm struct {
+	Pattern Pattern
+	Body    Expression
+}
+
+// Pattern представляет образец ветви сопоставления.
+type Pattern interface {
+	patternNode()
+}
+
+// WildcardPattern совпадает с любым значением (`_`).
+type WildcardPattern struct{}
+
+// BindingPattern совпадает с любым значением и связывает его с именем.
+type BindingPattern struct {
+	Name string
+}
+
+// LiteralPattern совпадает со значением, равным литералу.
+type LiteralPattern struct {
+	Value string
+	Kind  string // "INT", "FLOAT", "STRING", "BOOL"
+}
+
+// TuplePattern поэлементно сопоставляет кортеж.
+type TuplePattern struct {
+	Elems []Pattern
+}
+
+// VariantPattern совпадает с вариантом C-подобного перечисления (`Color::Red`).
+type VariantPattern struct {
+	Enum    string
+	Variant string
+}
+
+// StructPattern сопоставляет поля структуры (`Point { x: 0, y }`); поля,
+// пропущенные через `..`, не проверяются.
+type StructPattern struct {
+	Name   string
+	Fields []*FieldPattern
+}
+
+// FieldPattern — образец одного поля в StructPattern.
+type FieldPattern struct {
+	Name    string
+	Pattern Pattern
+}
+
+func (*WildcardPattern) patternNode() {}
+func (*BindingPattern) patternNode()  {}
+func (*LiteralPattern) patternNode()  {}
+func (*TuplePattern) patternNode()    {}
+func (*VariantPattern) patternNode()  {}
+func (*StructPattern) patternNode()   {}
+
+// MethodCall представляет вызов метода с сохранением получателя.
+type MethodCall struct {
+	Receiver Expression
+	Method   string
+	Args     []Expression
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (m *MethodCall) exprNode()           {}
+func (m *MethodCall) Type() *Type         { return m.TypeInfo }
+func (m *MethodCall) Pos() token.Position { return m.Position }
+
+// FieldExpr представляет доступ к полю структуры.
+type FieldExpr struct {
+	Receiver Expression
+	Field    string
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (f *FieldExpr) exprNode()           {}
+func (f *FieldExpr) Type() *Type         { return f.TypeInfo }
+func (f *FieldExpr) Pos() token.Position { return f.Position }
+
+// IndexExpr представляет индексирование среза или отображения.
+type IndexExpr struct {
+	Expr     Expression
+	Index    Expression
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (i *IndexExpr) exprNode()           {}
+func (i *IndexExpr) Type() *Type         { return i.TypeInfo }
+func (i *IndexExpr) Pos() token.Position { return i.Position }
+
+// ArrayLit представляет литерал массива. Массивы Rust переводятся в срезы Go.
+type ArrayLit struct {
+	Elems    []Expression
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (a *ArrayLit) exprNode()           {}
+func (a *ArrayLit) Type() *Type         { return a.TypeInfo }
+func (a *ArrayLit) Pos() token.Position { return a.Position }
+
+// ArrayRepeat представляет массив `[value; len]` из len копий значения.
+type ArrayRepeat struct {
+	Value    Expression
+	Len      Expression
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (a *ArrayRepeat) exprNode()           {}
+func (a *ArrayRepeat) Type() *Type         { return a.TypeInfo }
+func (a *ArrayRepeat) Pos() token.Position { return a.Position }
+
+// StructLit представляет литерал структуры. Поля перечислены в порядке записи в исходном коде.
+type StructLit struct {
+	Name     string
+	Fields   []*FieldInit
+	TypeInfo *Type
+	Position token.Position
+}
+
+// FieldInit представляет инициализатор поля в литерале структуры.
+type FieldInit struct {
+	Name  string
+	Value Expression
+}
+
+func (s *StructLit) exprNode()           {}
+func (s *StructLit) Type() *Type         { return s.TypeInfo }
+func (s *StructLit) Pos() token.Position { return s.Position }
+
+// RangeExpr представляет диапазон `start..end` (или `start..=end`, если Inclusive).
+type RangeExpr struct {
+	Start     Expression
+	End       Expression
+	Inclusive bool
+	TypeInfo  *Type
+	Position  token.Position
+}
+
+func (r *RangeExpr) exprNode()           {}
+func (r *RangeExpr) Type() *Type         { return r.TypeInfo }
+func (r *RangeExpr) Pos() token.Position { return r.Position }
+
+// ClosureExpr представляет замыкание Rust, которое в Go становится функциональным литералом.
+// Захваченные переменные объемлющей функции в Go доступны литералу напрямую.
+type ClosureExpr struct {
+	Params     []*Parameter
+	ReturnType *Type
+	Body       []Statement // Хвостовое выражение тела — возвращаемое значение
+	TypeInfo   *Type       // Функциональный тип (см. NewFuncType)
+	Position   token.Position
+}
+
+func (c *ClosureExpr) exprNode()           {}
+func (c *ClosureExpr) Type() *Type         { return c.TypeInfo }
+func (c *ClosureExpr) Pos() token.Position { return c.Position }
+
+// VariantExpr — значение варианта C-подобного перечисления: `Color::Red`.
+type VariantExpr struct {
+	Enum     string
+	Variant  string
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (v *VariantExpr) exprNode()           {}
+func (v *VariantExpr) Type() *Type         { return v.TypeInfo }
+func (v *VariantExpr) Pos() token.Position { return v.Position }
+
+// IsDiverging сообщает, не возвращает ли выражение управление:
+// это вызовы panic!, todo!, unimplemented! и unreachable!.
+func IsDiverging(expr Expression) bool {
+	call, ok := expr.(*CallExpr)
+	if !ok || !call.IsMacro {
+		return false
+	}
+	switch call.FuncName {
+	case "panic!", "todo!", "unimplemented!", "unreachable!":
+		return true
+	}
+	return false
+}
+
+// UnwrapExpr представляет извлечение значения из Option через `.unwrap()`/`.expect(msg)`.
+// Если значение отсутствует, генерируемый код паникует.
+type UnwrapExpr struct {
+	Expr     Expression // Значение Option (в Go — указатель)
+	Method   string     // "unwrap" или "expect"
+	Message  Expression // Сообщение для expect (nil для unwrap)
+	TypeInfo *Type
+	Position token.Position
+}
+
+func (u *UnwrapExpr) exprNode()           {}
+func (u *UnwrapExpr) Type() *Type         { return u.TypeInfo }
+func (u *UnwrapExpr) Pos() token.Position { return u.Position }
+
+// SomeExpr представляет `Some(value)`. Option<T> в Go — указатель *T, поэтому
+// значение копируется во временную переменную, и результатом становится её адрес.
+// None понижается до литерала nil (Kind "NIL") с типом *T, если он известен.
+type SomeExpr struct {
+	Value    Expression
+	TypeInfo *Type // Указатель на тип значения
+	Position token.Position
+}
+
+func (s *SomeExpr) exprNode()           {}
+func (s *SomeExpr) Type() *Type         { return s.TypeInfo }
+func (s *SomeExpr) Pos() token.Position { return s.Position }
+
 // ExprStmt оборачивает выражение как оператор.
 type ExprStmt struct {
 	Expr     Expression
@@ -150,7 +527,15 @@ type Type struct {
 	IsPrimitive bool
 	IsPointer   bool
 	IsArray     bool
-	ElementType *Type // Для массивов и указателей
+	IsMap       bool
+	IsTuple     bool
+	IsResult    bool
+	IsFunc      bool
+	IsRef       bool    // Указатель представляет изменяемую ссылку &mut T, а не Option<T> или Box<T>
+	KeyType     *Type   // Для отображений (map)
+	ErrorType   *Type   // Для Result: тип ошибки в Rust (в Go ошибка всегда error)
+	Elements    []*Type // Для кортежей; для функций — типы параметров
+	ElementType *Type   // Для массивов, указателей, значений отображений и значений Result; для функций — возвращаемый тип
 }
 
 // Struct представляет определение структуры в IR.
@@ -158,12 +543,28 @@ type Struct struct {
 	Name   string
 	Fields []*Field
 	Pos    token.Position
+	Public bool // Структура объявлена как pub
+	// Tuple — кортежная структура `struct Meters(f64);`: поля называются "0", "1", ...
+	// и в Go становятся полями Field0, Field1, ... (см. TupleFieldName)
+	Tuple bool
+	// Derives — трейты из #[derive(...)], для которых генерируются методы Go
+	Derives []string
+}
+
+// Enum представляет перечисление, варианты которого не содержат данных.
+// В Go оно становится именованным целым типом с константами вариантов.
+type Enum struct {
+	Name     string
+	Variants []string
+	Pos      token.Position
+	Public   bool // Перечисление объявлено как pub
 }
 
 // Field представляет поле структуры.
 type Field struct {
-	Name string
-	Type *Type
+	Name   string
+	Type   *Type
+	Public bool // Поле объявлено как pub
 }
 
 // NewType создаёт новый тип.
@@ -189,6 +590,81 @@ func NewPointerType(elementType *Type) *Type {
 	}
 }
 
+// NewRefType создаёт тип изменяемой ссылки &mut T: в Go это указатель *T,
+// через который вызываемая функция меняет значение вызывающей.
+func NewRefType(elementType *Type) *Type {
+	t := NewPointerType(elementType)
+	t.IsRef = true
+	return t
+}
+
+// NewMapType создаёт тип отображения (map).
+func NewMapType(keyType, valueType *Type) *Type {
+	return &Type{
+		Name:        "map[" + keyType.Name + "]" + valueType.Name,
+		IsMap:       true,
+		KeyType:     keyType,
+		ElementType: valueType,
+	}
+}
+
+// NewTupleType создаёт кортежный тип, представляемый анонимной структурой Go.
+func NewTupleType(elements []*Type) *Type {
+	fields := make([]string, 0, len(elements))
+	for i, elem := range elements {
+		fields = append(fields, TupleFieldName(i)+" "+elem.Name)
+	}
+	return &Type{
+		Name:     "struct{ " + strings.Join(fields, "; ") + " }",
+		IsTuple:  true,
+		Elements: elements,
+	}
+}
+
+// NewResultType создаёт тип Result<T, E>, который в Go становится парой (T, error).
+// Result<(), E> становится просто error.
+func NewResultType(valueType, errType *Type) *Type {
+	name := "error"
+	if !valueType.IsUnit() {
+		name = "(" + valueType.String() + ", error)"
+	}
+	return &Type{
+		Name:        name,
+		IsResult:    true,
+		ElementType: valueType,
+		ErrorType:   errType,
+	}
+}
+
+// NewFuncType создаёт функциональный тип Go `func(int) int` (тип замыкания).
+// Возвращаемый тип () в записи типа опускается.
+func NewFuncType(params []*Type, ret *Type) *Type {
+	names := make([]string, 0, len(params))
+	for _, param := range params {
+		names = append(names, param.String())
+	}
+	name := "func(" + strings.Join(names, ", ") + ")"
+	if !ret.IsUnit() {
+		name += " " + ret.String()
+	}
+	return &Type{
+		Name:        name,
+		IsFunc:      true,
+		Elements:    params,
+		ElementType: ret,
+	}
+}
+
+// TupleFieldName возвращает имя поля структуры Go для i-го элемента кортежа.
+func TupleFieldName(i int) string {
+	return fmt.Sprintf("Field%d", i)
+}
+
+// IsUnit проверяет, является ли тип unit-типом `()` (в Go — отсутствие значения).
+func (t *Type) IsUnit() bool {
+	return t == nil || ((t.Name == "" || t.Name == "()") && !t.IsArray && !t.IsPointer && !t.IsMap)
+}
+
 // String возвращает строковое представление типа.
 func (t *Type) String() string {
 	if t.Name != "" {
@@ -200,6 +676,9 @@ func (t *Type) String() string {
 	if t.IsPointer {
 		return "*" + t.ElementType.String()
 	}
+	if t.IsMap {
+		return "map[" + t.KeyType.String() + "]" + t.ElementType.String()
+	}
 	return "unknown"
 }
 
@@ -214,6 +693,8 @@ func MapRustToGoType(rustType string) string {
 		"u16":    "uint16",
 		"u32":    "uint32",
 		"u64":    "uint64",
+		"isize":  "int",
+		"usize":  "uint",
 		"f32":    "float32",
 		"f64":    "float64",
 		"bool":   "bool",
@@ -228,3 +709,12 @@ func MapRustToGoType(rustType string) string {
 	// Для неизвестных типов просто возвращаем как есть (могут быть пользовательские типы)
 	return rustType
 }
+
+// MapRustToGoTypeStrict преобразует тип из Rust в Go, сохраняя разрядность целых:
+// `i32` становится `int32`, а не `int`. Остальные типы отображаются как в MapRustToGoType.
+func MapRustToGoTypeStrict(rustType string) string {
+	if rustType == "i32" {
+		return "int32"
+	}
+	return MapRustToGoType(rustType)
+}
@@ -2,28 +2,98 @@
 package ir
 
 import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
 	"github.com/semetekare/rust2go/internal/ast"
 )
 
 // Transformer преобразует AST в промежуточное представление.
 type Transformer struct {
-	module *Module
+	module  *Module
+	locals  map[string]*Type   // Типы параметров и локальных переменных текущей функции
+	structs map[string]*Struct // Структуры модуля для определения типов полей
+	enums   map[string]*Enum   // C-подобные перечисления модуля
+
+	methods  map[string]map[string]*ast.Function // Методы из блоков impl: тип -> имя -> определение
+	selfType string                              // Тип, которому соответствует Self в текущем блоке impl
+
+	functions map[string]*ast.Function // Свободные функции модуля для определения типов вызовов
+	pending   []Statement              // Операторы, вынесенные из выражений (например, для `?`) перед текущим оператором
+	tempCount int                      // Счётчик временных переменных текущей функции
+
+	usedLabels map[string]bool // Метки циклов, на которые ссылаются break и continue преобразуемого тела
+
+	strictIntWidths bool // i32 отображается в int32 вместо int (см. SetStrictIntWidths)
 }
 
 // NewTransformer создаёт новый трансформер.
 func NewTransformer() *Transformer {
 	return &Transformer{
 		module: &Module{
-			Name:        "main",
-			PackageName: "main",
-			Functions:   []*Function{},
-			Structs:     []*Struct{},
+			Name:      "main",
+			Functions: []*Function{},
+			Structs:   []*Struct{},
 		},
+		structs:    make(map[string]*Struct),
+		enums:      make(map[string]*Enum),
+		methods:    make(map[string]map[string]*ast.Function),
+		functions:  make(map[string]*ast.Function),
+		usedLabels: make(map[string]bool),
+	}
+}
+
+// SetPackageName задаёт имя пакета Go генерируемого модуля. По умолчанию пакет
+// называется main, если в crate есть функция main, и lib для библиотечного crate.
+func (t *Transformer) SetPackageName(name string) {
+	t.module.PackageName = name
+}
+
+// SetStrictIntWidths включает точное отображение целых типов: `i32` становится `int32`
+// вместо `int`, как и нетипизированные целые литералы. Полезно при взаимодействии с C
+// и сериализации, где важна разрядность.
+func (t *Transformer) SetStrictIntWidths(strict bool) {
+	t.strictIntWidths = strict
+}
+
+// mapType преобразует имя типа Rust в Go с учётом настроек трансформера.
+func (t *Transformer) mapType(rustType string) string {
+	if t.strictIntWidths {
+		return MapRustToGoTypeStrict(rustType)
 	}
+	return MapRustToGoType(rustType)
 }
 
 // Transform преобразует AST-код в IR-модуль.
+// Структуры преобразуются первыми, чтобы в телах функций были известны типы их полей.
 func (t *Transformer) Transform(crate *ast.Crate) *Module {
+	for _, item := range crate.Items {
+		switch node := item.(type) {
+		case *ast.Struct:
+			st := t.transformStruct(node)
+			if st != nil {
+				t.module.Structs = append(t.module.Structs, st)
+				t.structs[st.Name] = st
+			}
+		case *ast.Enum:
+			if en := t.transformEnum(node); en != nil {
+				t.module.Enums = append(t.module.Enums, en)
+				t.enums[en.Name] = en
+			}
+		case *ast.Function:
+			t.functions[node.Name] = node
+		case *ast.Impl:
+			if t.methods[node.Type] == nil {
+				t.methods[node.Type] = make(map[string]*ast.Function)
+			}
+			for _, method := range node.Methods {
+				t.methods[node.Type][method.Name] = method
+			}
+		}
+	}
 	for _, item := range crate.Items {
 		switch node := item.(type) {
 		case *ast.Function:
@@ -31,11 +101,21 @@ func (t *Transformer) Transform(crate *ast.Crate) *Module {
 			if fn != nil {
 				t.module.Functions = append(t.module.Functions, fn)
 			}
-		case *ast.Struct:
-			st := t.transformStruct(node)
-			if st != nil {
-				t.module.Structs = append(t.module.Structs, st)
+		case *ast.Impl:
+			t.selfType = node.Type
+			for _, method := range node.Methods {
+				fn := t.transformFunction(method)
+				if fn != nil {
+					t.module.Functions = append(t.module.Functions, fn)
+				}
 			}
+			t.selfType = ""
+		}
+	}
+	if t.module.PackageName == "" {
+		t.module.PackageName = "lib"
+		if t.module.HasMain() {
+			t.module.PackageName = "main"
 		}
 	}
 	return t.module
@@ -49,51 +129,415 @@ func (t *Transformer) transformFunction(fn *ast.Function) *Function {
 
 	irFunc := &Function{
 		Name:       fn.Name,
+		Public:     fn.Public,
+		TypeParams: fn.TypeParams,
+		Bounds:     fn.Bounds,
 		Params:     []*Parameter{},
 		ReturnType: t.transformType(fn.ReturnType),
 		Body:       []Statement{},
 		Pos:        fn.Pos(),
 		GoPackage:  "main",
 	}
+	t.locals = make(map[string]*Type)
+	t.tempCount = 0
+
+	// Метод получает приёмник Go: &self и &mut self — указатель, self — значение
+	if fn.Receiver != nil {
+		recvType := NewType(t.selfType, false)
+		t.locals["self"] = recvType
+		if _, isRef := fn.Receiver.Type.(*ast.RefType); isRef {
+			irFunc.GoReceiver = ReceiverName + " *" + recvType.Name
+		} else {
+			irFunc.GoReceiver = ReceiverName + " " + recvType.Name
+		}
+	} else if t.selfType != "" {
+		irFunc.AssocType = t.selfType
+	}
 
 	// Преобразуем параметры
 	for _, param := range fn.Params {
+		paramType := t.transformType(param.Type)
+		t.locals[param.Name] = paramType
 		irFunc.Params = append(irFunc.Params, &Parameter{
-			Name: param.Name,
-			Type: t.transformType(param.Type),
+			Name:    param.Name,
+			Type:    paramType,
+			Mutable: param.Mutable,
 		})
 	}
 
 	// Преобразуем тело функции
-	for _, stmt := range fn.Body.Stmts {
+	irFunc.Body = t.transformStmts(fn.Body.Stmts)
+
+	return irFunc
+}
+
+// transformStmts преобразует список операторов.
+// Операторы, вынесенные из выражений при преобразовании (t.pending), вставляются перед оператором, из которого вынесены.
+func (t *Transformer) transformStmts(stmts []ast.Stmt) []Statement {
+	body := t.transformNestedFunctions(stmts)
+	for _, stmt := range stmts {
 		irStmt := t.transformStmt(stmt)
+		body = append(body, t.pending...)
+		t.pending = nil
 		if irStmt != nil {
-			irFunc.Body = append(irFunc.Body, irStmt)
+			body = append(body, irStmt)
 		}
 	}
+	return body
+}
 
-	return irFunc
+// transformNestedFunctions преобразует функции, вложенные в блок. В Go нет вложенных
+// именованных функций, поэтому функция становится замыканием, присвоенным переменной:
+// `helper := func(...) {...}`. Объявления выносятся в начало блока — в Rust функция видна
+// во всём блоке, в том числе до места объявления. Переменная функции, на которую ссылается
+// она сама или функция выше неё, объявляется заранее (`var helper func(...)`), а замыкание
+// присваивается ей отдельно. Неиспользуемая функция помечается `_ = helper`: Go не допускает
+// неиспользуемых переменных.
+func (t *Transformer) transformNestedFunctions(stmts []ast.Stmt) []Statement {
+	var fns []*ast.Function
+	for _, stmt := range stmts {
+		if fs, ok := stmt.(*ast.FnStmt); ok {
+			fns = append(fns, fs.Func)
+		}
+	}
+	if len(fns) == 0 {
+		return []Statement{}
+	}
+
+	closures := make([]*ClosureExpr, len(fns))
+	for i, fn := range fns {
+		closures[i] = t.nestedFunctionSignature(fn)
+		t.locals[fn.Name] = closures[i].TypeInfo
+	}
+
+	body := []Statement{}
+	predeclared := make(map[string]bool)
+	for i, fn := range fns {
+		for _, later := range fns[i:] {
+			if !predeclared[later.Name] && referencesName(fn.Body, later.Name) {
+				predeclared[later.Name] = true
+			}
+		}
+	}
+	for i, fn := range fns {
+		if predeclared[fn.Name] {
+			body = append(body, &Declaration{Name: fn.Name, Type: closures[i].TypeInfo, Position: fn.Pos()})
+		}
+	}
+	for i, fn := range fns {
+		closures[i].Body = t.transformNestedBody(fn, closures[i])
+		if predeclared[fn.Name] {
+			target := &LiteralExpr{Value: fn.Name, Kind: "IDENT", TypeInfo: closures[i].TypeInfo}
+			body = append(body, &Assignment{Target: target, Value: closures[i], Position: fn.Pos()})
+		} else {
+			body = append(body, &Declaration{Name: fn.Name, Type: closures[i].TypeInfo, InitValue: closures[i], Position: fn.Pos()})
+		}
+		if !t.nestedFunctionUsed(fn, stmts) {
+			blank := &LiteralExpr{Value: "_", Kind: "IDENT", TypeInfo: closures[i].TypeInfo}
+			value := &LiteralExpr{Value: fn.Name, Kind: "IDENT", TypeInfo: closures[i].TypeInfo}
+			body = append(body, &Assignment{Target: blank, Value: value, Position: fn.Pos()})
+		}
+	}
+	return body
+}
+
+// nestedFunctionSignature возвращает замыкание с параметрами и типом вложенной функции fn;
+// тело заполняется позже, когда объявлены все функции блока.
+func (t *Transformer) nestedFunctionSignature(fn *ast.Function) *ClosureExpr {
+	params := make([]*Parameter, 0, len(fn.Params))
+	paramTypes := make([]*Type, 0, len(fn.Params))
+	for _, param := range fn.Params {
+		paramType := t.transformType(param.Type)
+		params = append(params, &Parameter{Name: param.Name, Type: paramType, Mutable: param.Mutable})
+		paramTypes = append(paramTypes, paramType)
+	}
+	returnType := t.transformType(fn.ReturnType)
+	return &ClosureExpr{
+		Params:     params,
+		ReturnType: returnType,
+		TypeInfo:   NewFuncType(paramTypes, returnType),
+		Position:   fn.Pos(),
+	}
+}
+
+// transformNestedBody преобразует тело вложенной функции с параметрами замыкания c.
+func (t *Transformer) transformNestedBody(fn *ast.Function, c *ClosureExpr) []Statement {
+	savedPending, savedLocals := t.pending, t.locals
+	t.pending, t.locals = nil, maps.Clone(t.locals)
+	defer func() { t.pending, t.locals = savedPending, savedLocals }()
+
+	for _, param := range c.Params {
+		t.locals[param.Name] = param.Type
+	}
+	return t.transformStmts(fn.Body.Stmts)
+}
+
+// nestedFunctionUsed сообщает, ссылаются ли на вложенную функцию fn операторы блока
+// (кроме её собственного объявления).
+func (t *Transformer) nestedFunctionUsed(fn *ast.Function, stmts []ast.Stmt) bool {
+	for _, stmt := range stmts {
+		if fs, ok := stmt.(*ast.FnStmt); ok && fs.Func == fn {
+			continue
+		}
+		if referencesName(stmt, fn.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesName сообщает, встречается ли идентификатор name в поддереве n.
+func referencesName(n ast.Node, name string) bool {
+	found := false
+	ast.Walk(n, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.Literal); ok && lit.Kind == "IDENT" && lit.Val == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// transformBlock преобразует операторы вложенного блока в его собственной области видимости:
+// переменные, объявленные в блоке (в том числе затеняющие внешние), не видны после него.
+// Вынесенные операторы внешнего выражения не должны попасть внутрь блока,
+// поэтому t.pending сохраняется и восстанавливается.
+func (t *Transformer) transformBlock(block *ast.Block) []Statement {
+	savedPending, savedLocals := t.pending, t.locals
+	t.pending, t.locals = nil, maps.Clone(t.locals)
+	stmts := t.transformStmts(block.Stmts)
+	t.pending, t.locals = savedPending, savedLocals
+	return stmts
+}
+
+// blockType возвращает тип значения блока — тип его хвостового выражения.
+// Блок без хвостового выражения или с паникой в хвосте имеет тип ().
+func blockType(stmts []Statement) *Type {
+	if len(stmts) > 0 {
+		switch s := stmts[len(stmts)-1].(type) {
+		case *ExprStmt:
+			if s.Expr != nil && !IsDiverging(s.Expr) && s.Expr.Type() != nil {
+				return s.Expr.Type()
+			}
+		case *If:
+			return s.TypeInfo
+		case *Block:
+			return s.TypeInfo
+		}
+	}
+	return NewType("()", true)
 }
 
 // transformStmt преобразует AST-оператор в IR-оператор.
 func (t *Transformer) transformStmt(stmt ast.Stmt) Statement {
 	switch s := stmt.(type) {
 	case *ast.LetStmt:
-		return &Declaration{
+		if s.Pattern != nil {
+			return t.transformDestructuring(s)
+		}
+		var init Expression
+		if try, ok := s.Init.(*ast.TryExpr); ok {
+			// `let x = f()?;` связывает значение Result напрямую с x, без временной переменной
+			if init = t.transformTry(try, s.Name); init == nil {
+				return nil
+			}
+		} else {
+			init = t.transformExpr(s.Init)
+		}
+		decl := &Declaration{
 			Name:      s.Name,
 			Type:      t.transformType(s.Type),
-			InitValue: t.transformExpr(s.Init),
+			InitValue: init,
+			Mutable:   s.Mutable,
 			Position:  s.Pos(),
 		}
+		// Тип элементов пустого `vec![]` известен только из объявления;
+		// у `vec![0; n]` объявленный тип уточняет тип литерала-заполнителя
+		if lit, ok := init.(*ArrayLit); ok && len(lit.Elems) == 0 && decl.Type.IsArray {
+			lit.TypeInfo = decl.Type
+		}
+		if rep, ok := init.(*ArrayRepeat); ok && decl.Type.IsArray {
+			rep.TypeInfo = decl.Type
+		}
+		// `let o: Option<i64> = None` и `= Some(1)` получают объявленный тип *T
+		if decl.Type.IsPointer {
+			switch init := init.(type) {
+			case *LiteralExpr:
+				if init.Kind == "NIL" {
+					init.TypeInfo = decl.Type
+				}
+			case *SomeExpr:
+				init.TypeInfo = decl.Type
+			}
+		}
+		t.declareLocal(decl.Name, decl.Type, decl.InitValue)
+		return decl
 	case *ast.ExprStmt:
+		// if и вложенные блоки на уровне операторов остаются операторами
+		switch s.Expr.(type) {
+		case *ast.IfExpr, *ast.BlockExpr:
+			if irStmt, ok := t.transformExpr(s.Expr).(Statement); ok {
+				return irStmt
+			}
+			return nil
+		}
+		if loop := t.transformLoop(s.Expr); loop != nil {
+			return loop
+		}
 		return &ExprStmt{
 			Expr:     t.transformExpr(s.Expr),
 			Position: s.Pos(),
 		}
+	case *ast.AssignStmt:
+		return &Assignment{
+			Target:   t.transformExpr(s.Target),
+			Value:    t.transformExpr(s.Value),
+			Position: s.Pos(),
+		}
+	}
+	return nil
+}
+
+// transformDestructuring понижает деструктурирующее объявление до параллельного
+// объявления Go: `let (a, b) = pair;` становится `a, b := pair.Field0, pair.Field1`,
+// а `let (a, b) = (1, 2);` — `a, b := 1, 2`. Кортежи в Go — анонимные структуры с полями
+// Field0, Field1, ... (см. TupleFieldName), структуры раскладываются по именам полей.
+// Значение, которое нельзя безопасно вычислить повторно, сохраняется во временной переменной.
+func (t *Transformer) transformDestructuring(s *ast.LetStmt) Statement {
+	init := t.transformExpr(s.Init)
+	var names []string
+	var values []Expression
+	t.bindPattern(s.Pattern, init, s.Pos(), &names, &values)
+	if len(names) == 0 {
+		// `let _ = f();` вычисляет значение и отбрасывает его
+		blank := &LiteralExpr{Value: "_", Kind: "IDENT", TypeInfo: exprType(init)}
+		return &Assignment{Target: blank, Value: init, Position: s.Pos()}
+	}
+	return &MultiDeclaration{Names: names, Values: values, Position: s.Pos()}
+}
+
+// bindPattern раскладывает значение value по образцу pat, добавляя связываемые
+// имена и их значения в names и values. `_` ничего не связывает.
+func (t *Transformer) bindPattern(pat ast.Pattern, value Expression, pos ast.Position, names *[]string, values *[]Expression) {
+	switch p := pat.(type) {
+	case *ast.IdentPattern:
+		*names = append(*names, p.Name)
+		*values = append(*values, value)
+		t.declareLocal(p.Name, nil, value)
+	case *ast.TuplePattern:
+		if lit, ok := value.(*TupleExpr); ok && len(lit.Elems) == len(p.Elems) {
+			for i, elem := range p.Elems {
+				t.bindPattern(elem, lit.Elems[i], pos, names, values)
+			}
+			return
+		}
+		value = t.reusable(value, pos)
+		for i, elem := range p.Elems {
+			var elemType *Type
+			if typ := exprType(value); typ != nil && typ.IsTuple && i < len(typ.Elements) {
+				elemType = typ.Elements[i]
+			}
+			field := &FieldExpr{Receiver: value, Field: TupleFieldName(i), TypeInfo: elemType, Position: pos}
+			t.bindPattern(elem, field, pos, names, values)
+		}
+	case *ast.StructPattern:
+		value = t.reusable(value, pos)
+		for _, f := range p.Fields {
+			field := &FieldExpr{Receiver: value, Field: f.Name, TypeInfo: t.fieldType(exprType(value), f.Name), Position: pos}
+			t.bindPattern(f.Pattern, field, pos, names, values)
+		}
+	}
+}
+
+// reusable возвращает выражение, которое можно вычислить несколько раз без
+// побочных эффектов: переменную или доступ к её полю. Иначе значение сохраняется
+// во временной переменной, объявляемой перед текущим оператором.
+func (t *Transformer) reusable(value Expression, pos ast.Position) Expression {
+	for expr := value; ; {
+		switch e := expr.(type) {
+		case *LiteralExpr:
+			if e.Kind == "IDENT" {
+				return value
+			}
+		case *FieldExpr:
+			expr = e.Receiver
+			continue
+		}
+		break
+	}
+	name := t.newTemp("tuple")
+	t.pending = append(t.pending, &Declaration{Name: name, Type: exprType(value), InitValue: value, Position: pos})
+	t.locals[name] = exprType(value)
+	return &LiteralExpr{Value: name, Kind: "IDENT", TypeInfo: exprType(value), Position: pos}
+}
+
+// transformLoop преобразует циклы и операторы break/continue, стоящие на уровне операторов.
+// `loop` становится `while true`. Значение `break value` в IR пока не переносится.
+// Метка сохраняется, только если на неё ссылается break или continue: Go не допускает
+// неиспользуемых меток. Для остальных выражений возвращает nil.
+func (t *Transformer) transformLoop(expr ast.Expr) Statement {
+	switch e := expr.(type) {
+	case *ast.WhileExpr:
+		cond := t.transformExpr(e.Cond)
+		body, label := t.transformLoopBody(e.Label, e.Body)
+		return &While{Cond: cond, Body: body, Label: label, Position: e.Pos()}
+	case *ast.LoopExpr:
+		body, label := t.transformLoopBody(e.Label, e.Body)
+		return &While{Body: body, Label: label, Position: e.Pos()}
+	case *ast.ForExpr:
+		iter := derefRef(t.transformExpr(e.Iter))
+		var elemType *Type
+		if iterType := exprType(iter); iterType != nil && iterType.IsArray {
+			elemType = iterType.ElementType
+		}
+		if r, ok := iter.(*RangeExpr); ok {
+			elemType = r.TypeInfo
+		}
+		// Переменная цикла видна только в его теле
+		saved := t.locals
+		t.locals = maps.Clone(saved)
+		t.declareLocal(e.Var, elemType, nil)
+		body, label := t.transformLoopBody(e.Label, e.Body)
+		t.locals = saved
+		return &For{Var: e.Var, Iter: iter, Body: body, Label: label, Position: e.Pos()}
+	case *ast.BreakExpr:
+		t.useLabel(e.Label)
+		return &Break{Label: e.Label, Position: e.Pos()}
+	case *ast.ContinueExpr:
+		t.useLabel(e.Label)
+		return &Continue{Label: e.Label, Position: e.Pos()}
 	}
 	return nil
 }
 
+// transformLoopBody преобразует тело цикла с меткой label и возвращает его вместе
+// с меткой или "", если ни один break или continue тела на неё не ссылается.
+func (t *Transformer) transformLoopBody(label string, body *ast.Block) ([]Statement, string) {
+	if label == "" {
+		return t.transformBlock(body), ""
+	}
+	saved, hadLabel := t.usedLabels[label]
+	delete(t.usedLabels, label)
+	stmts := t.transformBlock(body)
+	used := t.usedLabels[label]
+	if hadLabel {
+		t.usedLabels[label] = saved
+	} else {
+		delete(t.usedLabels, label)
+	}
+	if !used {
+		label = ""
+	}
+	return stmts, label
+}
+
+// useLabel отмечает, что break или continue ссылается на метку label.
+func (t *Transformer) useLabel(label string) {
+	if label != "" {
+		t.usedLabels[label] = true
+	}
+}
+
 // transformExpr преобразует AST-выражение в IR-выражение.
 func (t *Transformer) transformExpr(expr ast.Expr) Expression {
 	if expr == nil {
@@ -102,18 +546,57 @@ func (t *Transformer) transformExpr(expr ast.Expr) Expression {
 
 	switch e := expr.(type) {
 	case *ast.Literal:
+		value := e.Val
+		if e.Kind == "IDENT" && value == "self" {
+			value = ReceiverName
+		}
+		// None — нулевой указатель; тип *T уточняется объявлением (см. transformStmt)
+		if e.Kind == "IDENT" && value == "None" && t.locals[value] == nil {
+			return &LiteralExpr{Value: "nil", Kind: "NIL", Position: e.Pos()}
+		}
+		// Единичная структура `Marker` как значение — пустой литерал `Marker{}`
+		if st, ok := t.structs[value]; ok && e.Kind == "IDENT" && len(st.Fields) == 0 && t.locals[value] == nil {
+			return &StructLit{Name: value, Fields: []*FieldInit{}, TypeInfo: NewType(value, false), Position: e.Pos()}
+		}
+		if e.Kind == "INT" || e.Kind == "FLOAT" {
+			return t.transformNumber(e)
+		}
 		return &LiteralExpr{
-			Value:    e.Val,
+			Value:    value,
 			Kind:     e.Kind,
 			TypeInfo: t.getLiteralType(e),
 			Position: e.Pos(),
 		}
 	case *ast.BlockExpr:
-		// Пока пропускаем block expressions
-		return nil
+		stmts := t.transformBlock(e.Block)
+		return &Block{Stmts: stmts, TypeInfo: blockType(stmts), Position: e.Pos()}
+	case *ast.ClosureExpr:
+		return t.transformClosure(e)
+	case *ast.IfExpr:
+		then := t.transformBlock(e.Then)
+		ifExpr := &If{
+			Cond:     t.transformExpr(e.Cond),
+			Then:     then,
+			TypeInfo: blockType(then),
+			Position: e.Pos(),
+		}
+		switch els := e.Else.(type) {
+		case *ast.BlockExpr:
+			ifExpr.Else = t.transformBlock(els.Block)
+		case *ast.IfExpr:
+			ifExpr.Else = []Statement{t.transformExpr(els).(*If)}
+		}
+		// Если ветвь then не возвращает значение (например, паникует), тип задаёт else
+		if ifExpr.TypeInfo.IsUnit() && len(ifExpr.Else) > 0 {
+			ifExpr.TypeInfo = blockType(ifExpr.Else)
+		}
+		return ifExpr
 	case *ast.BinaryExpr:
 		left := t.transformExpr(e.Left)
 		right := t.transformExpr(e.Right)
+		if eq := t.derivedEquals(left, e.Op, right); eq != nil {
+			return eq
+		}
 		return &BinaryExpr{
 			Left:     left,
 			Op:       e.Op,
@@ -122,17 +605,167 @@ func (t *Transformer) transformExpr(expr ast.Expr) Expression {
 			Position: e.Pos(),
 		}
 	case *ast.UnaryExpr:
-		return &UnaryExpr{
-			Op:       e.Op,
+		return t.transformUnary(e)
+	case *ast.RangeExpr:
+		// Тип диапазона — тип его границ: литерал без суффикса берёт тип другой границы
+		start, end := t.transformExpr(e.Start), t.transformExpr(e.End)
+		typ := exprType(start)
+		if lit, ok := e.Start.(*ast.Literal); ok && lit.Kind == "INT" && exprType(end) != nil {
+			typ = exprType(end)
+		}
+		return &RangeExpr{Start: start, End: end, Inclusive: e.Inclusive, TypeInfo: typ, Position: e.Pos()}
+	case *ast.CastExpr:
+		return &CastExpr{
 			Expr:     t.transformExpr(e.Expr),
-			TypeInfo: t.transformExpr(e.Expr).Type(),
+			TypeInfo: t.transformType(e.Type),
+			Position: e.Pos(),
+		}
+	case *ast.TupleExpr:
+		elems := []Expression{}
+		elemTypes := []*Type{}
+		for _, elem := range e.Elems {
+			irElem := t.transformExpr(elem)
+			elemType := exprType(irElem)
+			if elemType == nil {
+				elemType = NewType("interface{}", false)
+			}
+			elems = append(elems, irElem)
+			elemTypes = append(elemTypes, elemType)
+		}
+		return &TupleExpr{
+			Elems:    elems,
+			TypeInfo: NewTupleType(elemTypes),
+			Position: e.Pos(),
+		}
+	case *ast.ArrayExpr:
+		elems := []Expression{}
+		elemType := NewType("interface{}", false)
+		typed := false
+		for i, elem := range e.Elems {
+			irElem := t.transformExpr(elem)
+			// Тип элементов задаёт первый элемент, кроме литерала без суффикса:
+			// `[1, 2u8]` — срез uint8
+			if irElem != nil && !typed && (i == 0 || !isUntypedNumber(irElem)) {
+				elemType = irElem.Type()
+				typed = !isUntypedNumber(irElem)
+			}
+			elems = append(elems, irElem)
+		}
+		return &ArrayLit{Elems: elems, TypeInfo: NewArrayType(elemType), Position: e.Pos()}
+	case *ast.ArrayRepeatExpr:
+		value := t.transformExpr(e.Value)
+		return &ArrayRepeat{
+			Value:    value,
+			Len:      t.transformExpr(e.Len),
+			TypeInfo: NewArrayType(exprType(value)),
+			Position: e.Pos(),
+		}
+	case *ast.PathExpr:
+		if en := t.enumOf(e.Segments); en != nil {
+			return &VariantExpr{Enum: en.Name, Variant: e.Segments[1], TypeInfo: NewType(en.Name, false), Position: e.Pos()}
+		}
+		return nil
+	case *ast.StructLit:
+		lit := &StructLit{
+			Name:     e.Name,
+			Fields:   []*FieldInit{},
+			TypeInfo: NewType(e.Name, false),
+			Position: e.Pos(),
+		}
+		for _, field := range e.Fields {
+			lit.Fields = append(lit.Fields, &FieldInit{Name: field.Name, Value: t.transformExpr(field.Value)})
+		}
+		return lit
+	case *ast.MatchExpr:
+		scrutinee := t.transformExpr(e.Scrutinee)
+		match := &MatchExpr{
+			Scrutinee: scrutinee,
+			Arms:      []*MatchArm{},
+			Position:  e.Pos(),
+		}
+		for _, arm := range e.Arms {
+			pattern := t.transformPattern(arm.Pattern, exprType(scrutinee))
+			body := t.transformExpr(arm.Body)
+			// Тип match определяется первой ветвью, которая возвращает значение
+			if match.TypeInfo == nil && body != nil && !IsDiverging(body) {
+				match.TypeInfo = body.Type()
+			}
+			match.Arms = append(match.Arms, &MatchArm{Pattern: pattern, Body: body})
+		}
+		return match
+	case *ast.MethodCall:
+		if e.Method == "unwrap" || e.Method == "expect" {
+			recv := t.transformExpr(e.Receiver)
+			unwrap := &UnwrapExpr{
+				Expr:     recv,
+				Method:   e.Method,
+				Position: e.Pos(),
+			}
+			if e.Method == "expect" && len(e.Args) > 0 {
+				unwrap.Message = t.transformExpr(e.Args[0])
+			}
+			if recvType := exprType(recv); recvType != nil && recvType.IsPointer {
+				unwrap.TypeInfo = recvType.ElementType
+			} else {
+				unwrap.TypeInfo = NewType("interface{}", false)
+			}
+			return unwrap
+		}
+
+		recv := t.transformExpr(e.Receiver)
+		args := []Expression{}
+		for _, arg := range e.Args {
+			args = append(args, t.transformExpr(arg))
+		}
+		recvType := derefType(exprType(recv))
+		if recvType != nil {
+			if method, ok := t.methods[recvType.Name][e.Method]; ok {
+				coerceArgs(method.Params, args)
+			}
+		}
+		return &MethodCall{
+			Receiver: recv,
+			Method:   e.Method,
+			Args:     args,
+			TypeInfo: t.methodReturnType(recvType, e.Method),
+			Position: e.Pos(),
+		}
+	case *ast.TryExpr:
+		return t.transformTry(e, "")
+	case *ast.FieldExpr:
+		recv := t.transformExpr(e.Receiver)
+		return &FieldExpr{
+			Receiver: recv,
+			Field:    e.Field,
+			TypeInfo: t.fieldType(derefType(exprType(recv)), e.Field),
+			Position: e.Pos(),
+		}
+	case *ast.IndexExpr:
+		// Срез за изменяемой ссылкой индексируется после разыменования: `(*v)[i]`
+		base := derefRef(t.transformExpr(e.Expr))
+		elemType := NewType("interface{}", false)
+		if baseType := exprType(base); baseType != nil && (baseType.IsArray || baseType.IsMap) {
+			elemType = baseType.ElementType
+		}
+		return &IndexExpr{
+			Expr:     base,
+			Index:    t.transformExpr(e.Index),
+			TypeInfo: elemType,
 			Position: e.Pos(),
 		}
 	case *ast.CallExpr:
 		// Получаем имя функции из литерала
 		var funcName string
-		if lit, ok := e.Func.(*ast.Literal); ok {
-			funcName = lit.Val
+		var assoc *ast.Function
+		switch f := e.Func.(type) {
+		case *ast.Literal:
+			funcName = f.Val
+		case *ast.PathExpr:
+			// Ассоциированная функция `Type::func` вызывается по полному пути
+			funcName = strings.Join(f.Segments, "::")
+			if len(f.Segments) == 2 {
+				assoc = t.methods[f.Segments[0]][f.Segments[1]]
+			}
 		}
 
 		args := []Expression{}
@@ -140,19 +773,57 @@ func (t *Transformer) transformExpr(expr ast.Expr) Expression {
 			args = append(args, t.transformExpr(arg))
 		}
 
+		// Конструктор кортежной структуры `Meters(1.5)` — литерал с полями по номерам
+		if st, ok := t.structs[funcName]; ok && st.Tuple && t.locals[funcName] == nil {
+			lit := &StructLit{Name: funcName, Fields: []*FieldInit{}, TypeInfo: NewType(funcName, false), Position: e.Pos()}
+			for i, arg := range args {
+				lit.Fields = append(lit.Fields, &FieldInit{Name: strconv.Itoa(i), Value: arg})
+			}
+			return lit
+		}
+
+		if funcName == "Some" && len(args) == 1 && t.locals[funcName] == nil {
+			var typ *Type
+			if valueType := exprType(args[0]); valueType != nil {
+				typ = NewPointerType(valueType)
+			}
+			return &SomeExpr{Value: args[0], TypeInfo: typ, Position: e.Pos()}
+		}
+
+		// vec![...] — это литерал массива, который и так переводится в срез Go
+		if funcName == "vec!" && len(args) == 1 {
+			switch args[0].(type) {
+			case *ArrayLit, *ArrayRepeat:
+				return args[0]
+			}
+		}
+
 		isMacro := len(funcName) > 0 && funcName[len(funcName)-1] == '!'
 		var returnType *Type
 
 		// Определяем возвращаемый тип для макросов
 		if isMacro {
+			// Макросы форматирования печатают значение, а не адрес, на который указывает ссылка
+			for i, arg := range args {
+				args[i] = derefRef(arg)
+			}
 			switch funcName {
 			case "format!":
 				returnType = NewType("string", true)
 			default:
 				returnType = NewType("()", true)
 			}
+		} else if typ := t.locals[funcName]; typ != nil && typ.IsFunc {
+			// Вызов замыкания, сохранённого в локальной переменной
+			returnType = typ.ElementType
+		} else if fn, ok := t.functions[funcName]; ok {
+			coerceArgs(fn.Params, args)
+			returnType = t.transformType(fn.ReturnType)
+		} else if assoc != nil {
+			coerceArgs(assoc.Params, args)
+			returnType = t.transformType(assoc.ReturnType)
 		} else {
-			// Для обычных функций пока возвращаем unit
+			// Для неизвестных функций пока возвращаем unit
 			returnType = NewType("()", true)
 		}
 
@@ -167,6 +838,365 @@ func (t *Transformer) transformExpr(expr ast.Expr) Expression {
 	return nil
 }
 
+// transformUnary преобразует унарное выражение. Разделяемая ссылка `&x` стирается:
+// Go сам берёт адрес при вызове методов с указателем-приёмником. Изменяемая ссылка
+// `&mut x` становится адресом `&x`, а разыменование `*p` — разыменованием указателя;
+// `*r` стёртой разделяемой ссылки — просто значение r.
+func (t *Transformer) transformUnary(e *ast.UnaryExpr) Expression {
+	inner := t.transformExpr(e.Expr)
+	innerType := exprType(inner)
+	switch e.Op {
+	case "&":
+		return inner
+	case "&mut":
+		// Повторное заимствование `&mut *p` — тот же указатель p
+		if deref, ok := inner.(*UnaryExpr); ok && deref.Op == "*" {
+			return deref.Expr
+		}
+		var typ *Type
+		if innerType != nil {
+			typ = NewRefType(innerType)
+		}
+		return &UnaryExpr{Op: "&", Expr: inner, TypeInfo: typ, Position: e.Pos()}
+	case "*":
+		if innerType == nil || !innerType.IsPointer {
+			return inner
+		}
+		return &UnaryExpr{Op: "*", Expr: inner, TypeInfo: innerType.ElementType, Position: e.Pos()}
+	}
+	return &UnaryExpr{Op: e.Op, Expr: inner, TypeInfo: innerType, Position: e.Pos()}
+}
+
+// derefRef разыменовывает значение изменяемой ссылки там, где Rust разыменовывает
+// её неявно (форматирование, передача `&mut T` в параметр `&T`): `&x` становится x,
+// ссылка r — `*r`. Остальные выражения возвращаются без изменений.
+func derefRef(expr Expression) Expression {
+	typ := exprType(expr)
+	if typ == nil || !typ.IsRef {
+		return expr
+	}
+	if addr, ok := expr.(*UnaryExpr); ok && addr.Op == "&" {
+		return addr.Expr
+	}
+	return &UnaryExpr{Op: "*", Expr: expr, TypeInfo: typ.ElementType, Position: expr.Pos()}
+}
+
+// coerceArgs приводит аргументы вызова к параметрам params: изменяемая ссылка,
+// переданная в параметр, который в Go принимает значение, разыменовывается.
+func coerceArgs(params []ast.Param, args []Expression) {
+	for i, param := range params {
+		if ref, ok := param.Type.(*ast.RefType); i < len(args) && !(ok && ref.Mutable) {
+			args[i] = derefRef(args[i])
+		}
+	}
+}
+
+// derefType возвращает тип, на который указывает изменяемая ссылка typ,
+// или сам typ: поля и методы Go, как и Rust, доступны через указатель.
+func derefType(typ *Type) *Type {
+	if typ != nil && typ.IsRef {
+		return typ.ElementType
+	}
+	return typ
+}
+
+// transformClosure преобразует замыкание в функциональный литерал. Параметры видны
+// только в теле замыкания; переменные объемлющей функции остаются доступными (захват).
+// Тип параметра без аннотации выводится из его использования в теле (см. inferParamType),
+// возвращаемый тип без аннотации — из хвостового выражения тела.
+func (t *Transformer) transformClosure(e *ast.ClosureExpr) Expression {
+	savedPending, savedLocals := t.pending, t.locals
+	t.pending, t.locals = nil, maps.Clone(t.locals)
+	defer func() { t.pending, t.locals = savedPending, savedLocals }()
+
+	params := make([]*Parameter, 0, len(e.Params))
+	paramTypes := make([]*Type, 0, len(e.Params))
+	for _, param := range e.Params {
+		var paramType *Type
+		if param.Type != nil {
+			paramType = t.transformType(param.Type)
+		} else {
+			paramType = t.inferParamType(param.Name, e.Body)
+		}
+		t.locals[param.Name] = paramType
+		params = append(params, &Parameter{Name: param.Name, Type: paramType, Mutable: param.Mutable})
+		paramTypes = append(paramTypes, paramType)
+	}
+
+	var body []Statement
+	if block, ok := e.Body.(*ast.BlockExpr); ok {
+		body = t.transformBlock(block.Block)
+	} else if e.Body != nil {
+		expr := t.transformExpr(e.Body)
+		body = append(t.pending, &ExprStmt{Expr: expr, Position: e.Body.Pos()})
+	}
+
+	returnType := blockType(body)
+	if e.ReturnType != nil {
+		returnType = t.transformType(e.ReturnType)
+	}
+	return &ClosureExpr{
+		Params:     params,
+		ReturnType: returnType,
+		Body:       body,
+		TypeInfo:   NewFuncType(paramTypes, returnType),
+		Position:   e.Pos(),
+	}
+}
+
+// inferParamType выводит тип параметра замыкания без аннотации по первому бинарному
+// выражению тела, в котором параметр сравнивается или складывается с операндом
+// известного типа: в `|x| x + 1` x имеет тип int. Если тип вывести не удалось,
+// возвращается interface{}.
+func (t *Transformer) inferParamType(name string, body ast.Expr) *Type {
+	var inferred *Type
+	operandType := func(expr ast.Expr) *Type {
+		lit, ok := expr.(*ast.Literal)
+		if !ok || lit.Kind == "IDENT" && lit.Val == name {
+			return nil
+		}
+		if lit.Kind == "IDENT" {
+			return t.locals[lit.Val]
+		}
+		return t.getLiteralType(lit)
+	}
+	isParam := func(expr ast.Expr) bool {
+		lit, ok := expr.(*ast.Literal)
+		return ok && lit.Kind == "IDENT" && lit.Val == name
+	}
+	ast.Walk(body, func(n ast.Node) bool {
+		be, ok := n.(*ast.BinaryExpr)
+		if !ok || inferred != nil {
+			return inferred == nil
+		}
+		switch {
+		case isParam(be.Left):
+			inferred = operandType(be.Right)
+		case isParam(be.Right):
+			inferred = operandType(be.Left)
+		}
+		return true
+	})
+	if inferred == nil {
+		return NewType("interface{}", false)
+	}
+	return inferred
+}
+
+// transformTry раскрывает оператор `expr?` в явную проверку с ранним возвратом.
+// Для Result:
+//
+//	name, err := expr
+//	if err != nil { return <нулевое значение>, err }
+//
+// Для Option (указатель в Go):
+//
+//	tmp := expr
+//	if tmp == nil { return nil }
+//
+// Вынесенные операторы добавляются в t.pending, а на месте `expr?` остаётся успешное значение.
+// Если name пусто, для значения заводится временная переменная.
+// Для Result с заданным name возвращается nil: значение уже связано с name.
+func (t *Transformer) transformTry(e *ast.TryExpr, name string) Expression {
+	inner := t.transformExpr(e.Expr)
+	innerType := exprType(inner)
+
+	if innerType != nil && innerType.IsPointer {
+		tmp := t.newTemp("tryValue")
+		t.locals[tmp] = innerType
+		t.pending = append(t.pending,
+			&Declaration{Name: tmp, Type: innerType, InitValue: inner, Position: e.Pos()},
+			&If{
+				Cond:     t.compareIdent(tmp, innerType, e.Pos(), "==", nilLiteral(e.Pos())),
+				Then:     []Statement{&Return{Value: nilLiteral(e.Pos()), Position: e.Pos()}},
+				Position: e.Pos(),
+			},
+		)
+		return &UnaryExpr{
+			Op:       "*",
+			Expr:     &LiteralExpr{Value: tmp, Kind: "IDENT", TypeInfo: innerType, Position: e.Pos()},
+			TypeInfo: innerType.ElementType,
+			Position: e.Pos(),
+		}
+	}
+
+	valueType := NewType("interface{}", false)
+	if innerType != nil && innerType.IsResult {
+		valueType = innerType.ElementType
+	}
+	bound := name != ""
+	if !bound {
+		name = t.newTemp("tryValue")
+	}
+	t.locals[name] = valueType
+	errType := NewType("error", false)
+	t.pending = append(t.pending,
+		&MultiDeclaration{Names: []string{name, "err"}, InitValue: inner, Position: e.Pos()},
+		&If{
+			Cond: t.compareIdent("err", errType, e.Pos(), "!=", nilLiteral(e.Pos())),
+			Then: []Statement{&Return{
+				Err:      &LiteralExpr{Value: "err", Kind: "IDENT", TypeInfo: errType, Position: e.Pos()},
+				Position: e.Pos(),
+			}},
+			Position: e.Pos(),
+		},
+	)
+	if bound {
+		return nil
+	}
+	return &LiteralExpr{Value: name, Kind: "IDENT", TypeInfo: valueType, Position: e.Pos()}
+}
+
+// compareIdent строит сравнение идентификатора name с выражением rhs: `name op rhs`.
+func (t *Transformer) compareIdent(name string, typ *Type, pos ast.Position, op string, rhs Expression) Expression {
+	return &BinaryExpr{
+		Left:     &LiteralExpr{Value: name, Kind: "IDENT", TypeInfo: typ, Position: pos},
+		Op:       op,
+		Right:    rhs,
+		TypeInfo: NewType("bool", true),
+		Position: pos,
+	}
+}
+
+// nilLiteral создаёт литерал nil.
+func nilLiteral(pos ast.Position) Expression {
+	return &LiteralExpr{Value: "nil", Kind: "NIL", TypeInfo: NewType("nil", false), Position: pos}
+}
+
+// newTemp возвращает имя новой временной переменной текущей функции с префиксом prefix.
+func (t *Transformer) newTemp(prefix string) string {
+	t.tempCount++
+	return fmt.Sprintf("%s%d", prefix, t.tempCount)
+}
+
+// transformPattern преобразует AST-образец в IR-образец.
+// Переменные, связанные образцом, регистрируются как локальные с типом соответствующей части значения.
+// Для образцов, которые IR не выражает (варианты с данными отвергает семантический анализ),
+// возвращается nil: такой образец нельзя молча заменить на `_`.
+func (t *Transformer) transformPattern(pat ast.Pattern, typ *Type) Pattern {
+	switch p := pat.(type) {
+	case *ast.WildcardPattern:
+		return &WildcardPattern{}
+	case *ast.IdentPattern:
+		t.locals[p.Name] = typ
+		return &BindingPattern{Name: p.Name}
+	case *ast.LiteralPattern:
+		return &LiteralPattern{Value: p.Val, Kind: p.Kind}
+	case *ast.TuplePattern:
+		elems := []Pattern{}
+		for i, elem := range p.Elems {
+			var elemType *Type
+			if typ != nil && typ.IsTuple && i < len(typ.Elements) {
+				elemType = typ.Elements[i]
+			}
+			elems = append(elems, t.transformPattern(elem, elemType))
+		}
+		return &TuplePattern{Elems: elems}
+	case *ast.StructPattern:
+		st := &StructPattern{Name: p.Name}
+		for _, f := range p.Fields {
+			st.Fields = append(st.Fields, &FieldPattern{
+				Name:    f.Name,
+				Pattern: t.transformPattern(f.Pattern, t.fieldType(NewType(p.Name, false), f.Name)),
+			})
+		}
+		return st
+	case *ast.VariantPattern:
+		if en := t.enumOf(p.Segments); en != nil && len(p.Elems) == 0 {
+			return &VariantPattern{Enum: en.Name, Variant: p.Segments[1]}
+		}
+	}
+	return nil
+}
+
+// enumOf возвращает C-подобное перечисление, к которому относится путь `Enum::Variant`,
+// или nil, если путь не указывает на вариант такого перечисления.
+func (t *Transformer) enumOf(segments []string) *Enum {
+	if len(segments) != 2 {
+		return nil
+	}
+	en := t.enums[segments[0]]
+	if en == nil || !slices.Contains(en.Variants, segments[1]) {
+		return nil
+	}
+	return en
+}
+
+// methodReturnType возвращает тип результата метода из блока impl
+// или interface{}, если метод неизвестен (например, методы стандартной библиотеки).
+func (t *Transformer) methodReturnType(recvType *Type, method string) *Type {
+	if recvType != nil {
+		if fn, ok := t.methods[recvType.Name][method]; ok {
+			savedSelf := t.selfType
+			t.selfType = recvType.Name
+			defer func() { t.selfType = savedSelf }()
+			return t.transformType(fn.ReturnType)
+		}
+	}
+	return NewType("interface{}", false)
+}
+
+// fieldType возвращает тип поля структуры (или элемента кортежа по номеру) или interface{},
+// если структура или поле неизвестны.
+func (t *Transformer) fieldType(recvType *Type, field string) *Type {
+	if recvType != nil && recvType.IsTuple {
+		if i, err := strconv.Atoi(field); err == nil && i < len(recvType.Elements) {
+			return recvType.Elements[i]
+		}
+	}
+	if recvType != nil {
+		if st, ok := t.structs[recvType.Name]; ok {
+			for _, f := range st.Fields {
+				if f.Name == field {
+					return f.Type
+				}
+			}
+		}
+	}
+	return NewType("interface{}", false)
+}
+
+// declareLocal запоминает тип локальной переменной.
+// Если тип не указан явно (infer), используется тип инициализатора.
+func (t *Transformer) declareLocal(name string, declType *Type, init Expression) {
+	if declType != nil && declType.Name != "" && declType.Name != "infer" {
+		t.locals[name] = declType
+		return
+	}
+	t.locals[name] = exprType(init)
+}
+
+// derivedEquals понижает `a == b` и `a != b` над структурами с #[derive(PartialEq)]
+// до вызова сгенерированного метода Equals: оператор == Go не сравнивает срезы
+// и карты в полях. Возвращает nil, если операнды не такие структуры.
+func (t *Transformer) derivedEquals(left Expression, op string, right Expression) Expression {
+	if op != "==" && op != "!=" {
+		return nil
+	}
+	leftType := exprType(left)
+	if leftType == nil {
+		return nil
+	}
+	st, ok := t.structs[leftType.Name]
+	if !ok || !slices.Contains(st.Derives, "PartialEq") {
+		return nil
+	}
+	boolType := NewType("bool", true)
+	var call Expression = &MethodCall{Receiver: left, Method: "Equals", Args: []Expression{right}, TypeInfo: boolType, Position: left.Pos()}
+	if op == "!=" {
+		call = &UnaryExpr{Op: "!", Expr: call, TypeInfo: boolType, Position: left.Pos()}
+	}
+	return call
+}
+
+// exprType возвращает тип выражения или nil, если выражение отсутствует.
+func exprType(expr Expression) *Type {
+	if expr == nil {
+		return nil
+	}
+	return expr.Type()
+}
+
 // transformType преобразует AST-тип в IR-тип.
 func (t *Transformer) transformType(astType ast.Type) *Type {
 	if astType == nil {
@@ -175,17 +1205,62 @@ func (t *Transformer) transformType(astType ast.Type) *Type {
 
 	switch typ := astType.(type) {
 	case *ast.PathType:
-		typeName := MapRustToGoType(typ.Path)
+		if typ.Path == "Self" && t.selfType != "" {
+			return NewType(t.selfType, false)
+		}
+		typeName := t.mapType(typ.Path)
 		return NewType(typeName, true)
+	case *ast.RefType:
+		// Изменяемая ссылка становится указателем; разделяемая передаётся
+		// в Go по значению того же типа: через неё значение не меняется
+		if typ.Mutable {
+			return NewRefType(t.transformType(typ.Elem))
+		}
+		return t.transformType(typ.Elem)
+	case *ast.GenericType:
+		return t.transformGenericType(typ)
+	case *ast.TupleType:
+		elems := make([]*Type, 0, len(typ.Elems))
+		for _, elem := range typ.Elems {
+			elems = append(elems, t.transformType(elem))
+		}
+		return NewTupleType(elems)
+	case *ast.ArrayType:
+		// Массивы фиксированной длины и срезы переводятся в срезы Go
+		return NewArrayType(t.transformType(typ.Elem))
 	}
 	return NewType("interface{}", false)
 }
 
+// transformGenericType преобразует обобщённый тип Rust в эквивалентный тип Go.
+// Vec<T> -> []T, Option<T> и Box<T> -> *T, HashMap<K, V> -> map[K]V, Result<T, E> -> (T, error).
+// Аргументы типа рекурсивно преобразуются через transformType.
+func (t *Transformer) transformGenericType(typ *ast.GenericType) *Type {
+	args := make([]*Type, 0, len(typ.Args))
+	for _, arg := range typ.Args {
+		args = append(args, t.transformType(arg))
+	}
+
+	switch {
+	case typ.Path == "Vec" && len(args) == 1:
+		return NewArrayType(args[0])
+	case (typ.Path == "Option" || typ.Path == "Box") && len(args) == 1:
+		return NewPointerType(args[0])
+	case typ.Path == "Result" && len(args) == 2:
+		return NewResultType(args[0], args[1])
+	case typ.Path == "HashMap" && len(args) == 2:
+		return NewMapType(args[0], args[1])
+	}
+	// Неизвестные обобщённые типы оставляем по имени (пользовательские типы)
+	return NewType(t.mapType(typ.Path), false)
+}
+
 // getLiteralType определяет тип литерала.
 func (t *Transformer) getLiteralType(lit *ast.Literal) *Type {
 	switch lit.Kind {
 	case "INT":
-		return NewType("int", true)
+		// Целый литерал без суффикса в Rust имеет тип i32
+		return NewType(t.mapType("i32"), true)
 	case "FLOAT":
 		return NewType("float64", true)
 	case "STRING":
@@ -193,13 +1268,58 @@ func (t *Transformer) getLiteralType(lit *ast.Literal) *Type {
 	case "BOOL":
 		return NewType("bool", true)
 	case "IDENT":
-		// Для идентификаторов - возвращаем тип с именем
+		// Для известных локальных переменных возвращаем их тип
+		if typ, ok := t.locals[lit.Val]; ok && typ != nil {
+			return typ
+		}
+		// Для остальных идентификаторов - возвращаем тип с именем
 		return NewType(lit.Val, false)
 	default:
 		return NewType("interface{}", false)
 	}
 }
 
+// transformNumber преобразует числовой литерал: суффикс типа (`5u8`, `2.5f32`)
+// и разделители `_` отбрасываются, а тип литерала задаётся суффиксом.
+// Целый литерал с дробным суффиксом (`1f32`) становится дробным.
+func (t *Transformer) transformNumber(lit *ast.Literal) Expression {
+	digits, suffix := ast.NumberSuffix(lit.Val)
+	num := &LiteralExpr{
+		Value:    strings.ReplaceAll(digits, "_", ""),
+		Kind:     lit.Kind,
+		Suffix:   suffix,
+		TypeInfo: t.getLiteralType(lit),
+		Position: lit.Pos(),
+	}
+	if suffix != "" {
+		num.TypeInfo = NewType(t.mapType(suffix), true)
+	}
+	if suffix == "f32" || suffix == "f64" {
+		num.Kind = "FLOAT"
+	}
+	return num
+}
+
+// isUntypedNumber сообщает, является ли выражение числовым литералом без суффикса,
+// тип которого определяется окружением.
+func isUntypedNumber(expr Expression) bool {
+	lit, ok := expr.(*LiteralExpr)
+	return ok && lit.Suffix == "" && (lit.Kind == "INT" || lit.Kind == "FLOAT")
+}
+
+// transformEnum преобразует перечисление без данных в вариантах. Перечисления
+// с кортежными или структурными вариантами пока не поддерживаются: возвращается nil.
+func (t *Transformer) transformEnum(en *ast.Enum) *Enum {
+	irEnum := &Enum{Name: en.Name, Public: en.Public, Pos: en.Pos()}
+	for _, variant := range en.Variants {
+		if len(variant.Types) > 0 || len(variant.Fields) > 0 {
+			return nil
+		}
+		irEnum.Variants = append(irEnum.Variants, variant.Name)
+	}
+	return irEnum
+}
+
 // transformStruct преобразует AST-структуру в IR-структуру.
 func (t *Transformer) transformStruct(st *ast.Struct) *Struct {
 	if st == nil {
@@ -207,15 +1327,19 @@ func (t *Transformer) transformStruct(st *ast.Struct) *Struct {
 	}
 
 	irStruct := &Struct{
-		Name:   st.Name,
-		Fields: []*Field{},
-		Pos:    st.Pos(),
+		Name:    st.Name,
+		Public:  st.Public,
+		Fields:  []*Field{},
+		Pos:     st.Pos(),
+		Tuple:   st.Kind == ast.StructTuple,
+		Derives: ast.Derives(st.Attrs),
 	}
 
 	for _, field := range st.Fields {
 		irStruct.Fields = append(irStruct.Fields, &Field{
-			Name: field.Name,
-			Type: t.transformType(field.Type),
+			Name:   field.Name,
+			Type:   t.transformType(field.Type),
+			Public: field.Public,
 		})
 	}
 
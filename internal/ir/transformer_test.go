@@ -0,0 +1,463 @@
+package ir_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/semetekare/rust2go/internal/ast"
+	"github.com/semetekare/rust2go/internal/ir"
+	"github.com/semetekare/rust2go/internal/lexer"
+	"github.com/semetekare/rust2go/internal/parser"
+)
+
+// parseCode разбирает исходный код и возвращает AST.
+func parseCode(code string, t *testing.T) *ast.Crate {
+	t.Helper()
+	lx := lexer.NewLexer()
+	toks, err := lx.Lex(code)
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+
+	p := parser.NewParser(toks)
+	crate, errs := p.ParseFile()
+	if len(errs) > 0 {
+		t.Fatalf("Parse errors: %v", errs)
+	}
+
+	return crate
+}
+
+// transformCode разбирает исходный код и преобразует его в IR.
+func transformCode(code string, t *testing.T) *ir.Module {
+	t.Helper()
+	return ir.NewTransformer().Transform(parseCode(code, t))
+}
+
+func TestTransformCollectionTypes(t *testing.T) {
+	code := `
+fn f(v: Vec<i32>, o: Option<String>, m: HashMap<String, i32>, b: Box<f64>) {}
+`
+	module := transformCode(code, t)
+	if len(module.Functions) != 1 {
+		t.Fatalf("Expected 1 function, got %d", len(module.Functions))
+	}
+
+	expected := []string{"[]int", "*string", "map[string]int", "*float64"}
+	params := module.Functions[0].Params
+	if len(params) != len(expected) {
+		t.Fatalf("Expected %d params, got %d", len(expected), len(params))
+	}
+	for i, exp := range expected {
+		if got := params[i].Type.String(); got != exp {
+			t.Errorf("Param %s: expected type %q, got %q", params[i].Name, exp, got)
+		}
+	}
+
+	if !params[0].Type.IsArray || params[0].Type.ElementType.Name != "int" {
+		t.Errorf("Expected Vec<i32> to be an array of int, got %+v", params[0].Type)
+	}
+	if !params[2].Type.IsMap || params[2].Type.KeyType.Name != "string" {
+		t.Errorf("Expected HashMap<String, i32> to be a map keyed by string, got %+v", params[2].Type)
+	}
+}
+
+func TestTransformNestedCollectionTypes(t *testing.T) {
+	code := `
+fn f() -> Vec<Option<i64>> {}
+`
+	module := transformCode(code, t)
+	if got := module.Functions[0].ReturnType.String(); got != "[]*int64" {
+		t.Errorf("Expected return type %q, got %q", "[]*int64", got)
+	}
+}
+
+func TestTransformOptionValues(t *testing.T) {
+	code := `
+fn f() {
+    let o: Option<i64> = None;
+    let p = Some(5);
+}
+`
+	body := transformCode(code, t).Functions[0].Body
+	none, ok := body[0].(*ir.Declaration).InitValue.(*ir.LiteralExpr)
+	if !ok || none.Kind != "NIL" || none.Type().String() != "*int64" {
+		t.Errorf("Expected None to be nil of type *int64, got %v", body[0].(*ir.Declaration).InitValue)
+	}
+	some, ok := body[1].(*ir.Declaration).InitValue.(*ir.SomeExpr)
+	if !ok || some.Type().String() != "*int" {
+		t.Fatalf("Expected Some(5) of type *int, got %v", body[1].(*ir.Declaration).InitValue)
+	}
+	if lit, ok := some.Value.(*ir.LiteralExpr); !ok || lit.Value != "5" {
+		t.Errorf("Expected Some value 5, got %v", some.Value)
+	}
+}
+
+func TestTransformMethodCall(t *testing.T) {
+	code := `
+fn main() {
+    v.push(x);
+}
+`
+	module := transformCode(code, t)
+	stmt, ok := module.Functions[0].Body[0].(*ir.ExprStmt)
+	if !ok {
+		t.Fatalf("Expected ExprStmt, got %T", module.Functions[0].Body[0])
+	}
+
+	call, ok := stmt.Expr.(*ir.MethodCall)
+	if !ok {
+		t.Fatalf("Expected MethodCall, got %T", stmt.Expr)
+	}
+	if recv, ok := call.Receiver.(*ir.LiteralExpr); !ok || recv.Value != "v" {
+		t.Errorf("Expected receiver v, got %#v", call.Receiver)
+	}
+	if call.Method != "push" {
+		t.Errorf("Expected method push, got %q", call.Method)
+	}
+	if len(call.Args) != 1 {
+		t.Fatalf("Expected 1 argument, got %d", len(call.Args))
+	}
+	if arg, ok := call.Args[0].(*ir.LiteralExpr); !ok || arg.Value != "x" {
+		t.Errorf("Expected argument x, got %#v", call.Args[0])
+	}
+}
+
+func TestTransformAssignment(t *testing.T) {
+	code := `
+struct Point {
+    x: i64,
+}
+
+fn main(p: Point) {
+    let mut x: i32 = 1;
+    x = 5;
+    p.x = 7;
+}
+`
+	module := transformCode(code, t)
+	body := module.Functions[0].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(body))
+	}
+
+	assign, ok := body[1].(*ir.Assignment)
+	if !ok {
+		t.Fatalf("Expected Assignment, got %T", body[1])
+	}
+	if target, ok := assign.Target.(*ir.LiteralExpr); !ok || target.Value != "x" {
+		t.Errorf("Expected target x, got %#v", assign.Target)
+	}
+	if value, ok := assign.Value.(*ir.LiteralExpr); !ok || value.Value != "5" {
+		t.Errorf("Expected value 5, got %#v", assign.Value)
+	}
+
+	fieldAssign, ok := body[2].(*ir.Assignment)
+	if !ok {
+		t.Fatalf("Expected Assignment, got %T", body[2])
+	}
+	field, ok := fieldAssign.Target.(*ir.FieldExpr)
+	if !ok {
+		t.Fatalf("Expected FieldExpr target, got %T", fieldAssign.Target)
+	}
+	if field.Field != "x" || field.Type().String() != "int64" {
+		t.Errorf("Expected field x of type int64, got %s of type %s", field.Field, field.Type())
+	}
+}
+
+func TestTransformTryOperator(t *testing.T) {
+	code := `
+fn parse() -> Result<i32, ParseError> {
+    Ok(1)
+}
+
+fn double() -> Result<i32, ParseError> {
+    let x = parse()?;
+    Ok(x * 2)
+}
+`
+	module := transformCode(code, t)
+	body := module.Functions[1].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements (declaration, early return, tail), got %d", len(body))
+	}
+
+	decl, ok := body[0].(*ir.MultiDeclaration)
+	if !ok {
+		t.Fatalf("Expected MultiDeclaration, got %T", body[0])
+	}
+	if len(decl.Names) != 2 || decl.Names[0] != "x" || decl.Names[1] != "err" {
+		t.Errorf("Expected names [x err], got %v", decl.Names)
+	}
+	if call, ok := decl.InitValue.(*ir.CallExpr); !ok || call.FuncName != "parse" || !call.Type().IsResult {
+		t.Errorf("Expected Result-typed call to parse, got %#v", decl.InitValue)
+	}
+
+	check, ok := body[1].(*ir.If)
+	if !ok {
+		t.Fatalf("Expected If, got %T", body[1])
+	}
+	if cond, ok := check.Cond.(*ir.BinaryExpr); !ok || cond.Op != "!=" {
+		t.Errorf("Expected err != nil condition, got %#v", check.Cond)
+	}
+	if len(check.Then) != 1 {
+		t.Fatalf("Expected single early return, got %d statements", len(check.Then))
+	}
+	ret, ok := check.Then[0].(*ir.Return)
+	if !ok {
+		t.Fatalf("Expected Return, got %T", check.Then[0])
+	}
+	if errExpr, ok := ret.Err.(*ir.LiteralExpr); !ok || errExpr.Value != "err" {
+		t.Errorf("Expected early return of err, got %#v", ret.Err)
+	}
+}
+
+func TestDump(t *testing.T) {
+	code := `
+fn add(a: i32, b: i32) -> i32 {
+    let c = a * 2;
+    c + b
+}
+`
+	dump := ir.Dump(transformCode(code, t))
+	expected := `Module lib
+  Function add(a int, b int) int
+    Declaration c infer
+      Binary * : int
+        Literal a : int
+        Literal 2 : int
+    ExprStmt
+      Binary + : int
+        Literal c : int
+        Literal b : int
+`
+	if dump != expected {
+		t.Errorf("Unexpected dump:\n%s\nwant:\n%s", dump, expected)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		node any
+		want string
+	}{
+		{&ir.Declaration{}, "Declaration"},
+		{&ir.BinaryExpr{}, "Binary"},
+		{&ir.If{}, "If"},
+		{&ir.Break{}, "Break"},
+		{nil, "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := ir.KindOf(tt.node).String(); got != tt.want {
+			t.Errorf("KindOf(%T) = %s, want %s", tt.node, got, tt.want)
+		}
+	}
+}
+
+func TestTransformNestedBlockScope(t *testing.T) {
+	code := `
+fn f() -> i32 {
+    let x: i32 = 1;
+    {
+        let x: bool = true;
+        {
+            let y = x;
+        }
+    }
+    x
+}
+`
+	body := transformCode(code, t).Functions[0].Body
+	if len(body) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(body))
+	}
+	outer, ok := body[1].(*ir.Block)
+	if !ok || len(outer.Stmts) != 2 {
+		t.Fatalf("Expected nested block with 2 statements, got %T", body[1])
+	}
+	inner, ok := outer.Stmts[1].(*ir.Block)
+	if !ok || len(inner.Stmts) != 1 {
+		t.Fatalf("Expected block nested in block, got %T", outer.Stmts[1])
+	}
+	// Внутренний x затеняет внешний только внутри блока
+	if y := inner.Stmts[0].(*ir.Declaration).InitValue; y.Type().Name != "bool" {
+		t.Errorf("Expected inner x to be bool, got %s", y.Type())
+	}
+	tail := body[2].(*ir.ExprStmt).Expr
+	if tail.Type().Name != "int" {
+		t.Errorf("Expected outer x to stay int after the block, got %s", tail.Type())
+	}
+}
+
+func TestTransformPackageName(t *testing.T) {
+	if name := transformCode("fn main() {}", t).PackageName; name != "main" {
+		t.Errorf("Expected package main for a crate with fn main, got %s", name)
+	}
+	if name := transformCode("pub fn add(a: i32) -> i32 { a }", t).PackageName; name != "lib" {
+		t.Errorf("Expected package lib for a library crate, got %s", name)
+	}
+
+	tr := ir.NewTransformer()
+	tr.SetPackageName("mylib")
+	if name := tr.Transform(parseCode("fn main() {}", t)).PackageName; name != "mylib" {
+		t.Errorf("Expected explicit package name to win, got %s", name)
+	}
+}
+
+func TestTransformStrictIntWidths(t *testing.T) {
+	code := "fn add(a: i32, b: u32) -> i32 { let c = 1; a + c }"
+
+	fn := transformCode(code, t).Functions[0]
+	if got := fn.Params[0].Type.String(); got != "int" {
+		t.Errorf("Expected i32 to map to int by default, got %s", got)
+	}
+	if got := fn.ReturnType.String(); got != "int" {
+		t.Errorf("Expected i32 return type to map to int by default, got %s", got)
+	}
+
+	tr := ir.NewTransformer()
+	tr.SetStrictIntWidths(true)
+	fn = tr.Transform(parseCode(code, t)).Functions[0]
+	for i, want := range []string{"int32", "uint32"} {
+		if got := fn.Params[i].Type.String(); got != want {
+			t.Errorf("Param %d: expected %s in strict mode, got %s", i, want, got)
+		}
+	}
+	if got := fn.ReturnType.String(); got != "int32" {
+		t.Errorf("Expected i32 return type to map to int32 in strict mode, got %s", got)
+	}
+	decl, ok := fn.Body[0].(*ir.Declaration)
+	if !ok {
+		t.Fatalf("Expected Declaration, got %T", fn.Body[0])
+	}
+	if got := decl.InitValue.Type().String(); got != "int32" {
+		t.Errorf("Expected unsuffixed literal to have type int32 in strict mode, got %s", got)
+	}
+}
+
+func TestTransformMutability(t *testing.T) {
+	code := `
+fn f(mut n: i32, m: i32) {
+    let mut x = 0;
+    let y = 0;
+}
+`
+	fn := transformCode(code, t).Functions[0]
+	if !fn.Params[0].Mutable || fn.Params[1].Mutable {
+		t.Errorf("Expected only n to be mutable, got n=%v m=%v", fn.Params[0].Mutable, fn.Params[1].Mutable)
+	}
+	for i, want := range []bool{true, false} {
+		decl, ok := fn.Body[i].(*ir.Declaration)
+		if !ok {
+			t.Fatalf("Statement %d: expected Declaration, got %T", i, fn.Body[i])
+		}
+		if decl.Mutable != want {
+			t.Errorf("Declaration %s: expected Mutable == %v", decl.Name, want)
+		}
+	}
+}
+
+func TestTransformClosure(t *testing.T) {
+	module := transformCode(`
+fn main() {
+    let inc = |x| x + 1;
+    let y = inc(2);
+}
+`, t)
+	body := module.Functions[0].Body
+	closure, ok := body[0].(*ir.Declaration).InitValue.(*ir.ClosureExpr)
+	if !ok {
+		t.Fatalf("Expected ClosureExpr, got %T", body[0].(*ir.Declaration).InitValue)
+	}
+	if got := closure.Params[0].Type.String(); got != "int" {
+		t.Errorf("Expected parameter type inferred from x + 1 to be int, got %s", got)
+	}
+	if got := closure.Type().String(); got != "func(int) int" {
+		t.Errorf("Expected closure type func(int) int, got %s", got)
+	}
+	if got := body[1].(*ir.Declaration).InitValue.Type().String(); got != "int" {
+		t.Errorf("Expected call of the closure to have its return type int, got %s", got)
+	}
+}
+
+func TestTransformCast(t *testing.T) {
+	module := transformCode(`
+fn half(x: i32) -> f64 {
+    let y = x as f64;
+    (1 + 2) as u8
+}
+`, t)
+	body := module.Functions[0].Body
+	cast, ok := body[0].(*ir.Declaration).InitValue.(*ir.CastExpr)
+	if !ok {
+		t.Fatalf("Expected CastExpr, got %T", body[0].(*ir.Declaration).InitValue)
+	}
+	if got := cast.Type().String(); got != "float64" {
+		t.Errorf("Expected cast target float64, got %s", got)
+	}
+	if v, ok := cast.Expr.(*ir.LiteralExpr); !ok || v.Value != "x" {
+		t.Errorf("Expected cast of x, got %#v", cast.Expr)
+	}
+
+	// Операнд приведения сворачивается, само приведение остаётся
+	ir.Fold(module)
+	tail := module.Functions[0].Body[1].(*ir.ExprStmt)
+	cast, ok = tail.Expr.(*ir.CastExpr)
+	if !ok {
+		t.Fatalf("Expected CastExpr in tail expression, got %T", tail.Expr)
+	}
+	if lit, ok := cast.Expr.(*ir.LiteralExpr); !ok || lit.Value != "3" || cast.Type().String() != "uint8" {
+		t.Errorf("Expected uint8(3), got %#v", cast)
+	}
+	if dump := ir.Dump(module); !strings.Contains(dump, "Cast : uint8") {
+		t.Errorf("Expected Cast node in dump, got:\n%s", dump)
+	}
+}
+
+func TestTransformNumberSuffixes(t *testing.T) {
+	tests := []struct {
+		literal string
+		value   string
+		kind    string
+		typ     string
+	}{
+		{"5u8", "5", "INT", "uint8"},
+		{"1_000i64", "1000", "INT", "int64"},
+		{"1_000_i64", "1000", "INT", "int64"},
+		{"0xffu8", "0xff", "INT", "uint8"},
+		{"7usize", "7", "INT", "uint"},
+		{"5i32", "5", "INT", "int"},
+		{"2.5f32", "2.5", "FLOAT", "float32"},
+		{"1e3f64", "1e3", "FLOAT", "float64"},
+		{"1f32", "1", "FLOAT", "float32"},
+		// В шестнадцатеричном литерале f32 — цифры
+		{"0x1f32", "0x1f32", "INT", "int"},
+		{"1_000", "1000", "INT", "int"},
+	}
+	for _, tt := range tests {
+		module := transformCode("fn main() { let x = "+tt.literal+"; }", t)
+		lit, ok := module.Functions[0].Body[0].(*ir.Declaration).InitValue.(*ir.LiteralExpr)
+		if !ok || lit.Value != tt.value || lit.Kind != tt.kind || lit.Type().Name != tt.typ {
+			t.Errorf("%s: expected %s %s of type %s, got %#v", tt.literal, tt.kind, tt.value, tt.typ, lit)
+		}
+	}
+}
+
+func TestTransformArrayLiteralType(t *testing.T) {
+	tests := []struct {
+		array string
+		typ   string
+	}{
+		{"[1, 2]", "[]int"},
+		{"[1u8, 2]", "[]uint8"},
+		{"[1, 2, 3u8]", "[]uint8"},
+		{"[1.5, 2.5f32]", "[]float32"},
+	}
+	for _, tt := range tests {
+		module := transformCode("fn main() { let x = "+tt.array+"; }", t)
+		lit, ok := module.Functions[0].Body[0].(*ir.Declaration).InitValue.(*ir.ArrayLit)
+		if !ok || lit.Type().String() != tt.typ {
+			t.Errorf("%s: expected array of type %s, got %#v", tt.array, tt.typ, lit)
+		}
+	}
+}
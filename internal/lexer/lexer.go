@@ -3,7 +3,10 @@ package lexer
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/semetekare/rust2go/internal/token"
 )
@@ -19,7 +22,6 @@ type Lexer struct {
 	ch            rune            // текущая просматриваемая руна
 	line          int             // текущая строка (1-based)
 	col           int             // текущая колонка (1-based)
-	tokens        []token.Token   // накопленные токены
 	err           error           // первая возникшая ошибка
 	keywords      map[string]bool // таблица ключевых слов
 	operators     map[string]bool // таблица операторов (включая многосимвольные)
@@ -40,28 +42,49 @@ func NewLexer() *Lexer {
 }
 
 // Lex запускает разбор входной строки и возвращает слайс токенов.
-// Основная точка входа для использования лексера.
+// Основная точка входа для использования лексера; реализован поверх NextToken.
 func (l *Lexer) Lex(input string) ([]token.Token, error) {
+	l.Reset(input)
+	var tokens []token.Token
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			return tokens, nil
+		}
+	}
+}
+
+// Reset подготавливает лексер к потоковому чтению входной строки через NextToken.
+func (l *Lexer) Reset(input string) {
 	l.input = input
 	l.runes = []rune(input) // переводим в runes, чтобы корректно работать с UTF-8
 	l.length = len(l.runes)
 	l.pos = 0
 	l.readPos = 0
-	l.tokens = nil
+	l.line = 1
+	l.col = 0
 	l.err = nil
 	l.ch = 0
 	l.readChar()
+}
 
-	for l.ch != 0 {
-		l.nextToken()
-		if l.err != nil {
-			return nil, l.err
+// NextToken возвращает следующий токен входной строки, заданной через Reset,
+// не накапливая уже прочитанные. В конце ввода возвращается EOF, в том числе
+// при повторных вызовах; после ошибки каждый вызов возвращает ту же ошибку.
+func (l *Lexer) NextToken() (token.Token, error) {
+	for l.err == nil {
+		if l.ch == 0 {
+			return token.Token{Type: token.EOF, Line: l.line, Col: l.col}, nil
+		}
+		if tok, ok := l.nextToken(); ok && l.err == nil {
+			return tok, nil
 		}
 	}
-
-	// Добавляем EOF токен в конец
-	l.tokens = append(l.tokens, token.Token{Type: token.EOF, Line: l.line, Col: l.col})
-	return l.tokens, nil
+	return token.Token{}, l.err
 }
 
 // readChar читает следующую руну в поток и обновляет позицию, строку и колонку.
@@ -164,14 +187,29 @@ func (l *Lexer) readIdentifier() string {
 	return string(l.runes[start:l.pos])
 }
 
-// readLifetimeOrChar различает lifetime ('a) и char ('a').
+// readLifetimeOrChar различает lifetime ('a) и char ('a) и возвращает текст вместе с типом
+// токена: token.CHAR или token.LIFETIME.
 // Логика: если после имени идёт закрывающий апостроф — это символьный литерал.
-func (l *Lexer) readLifetimeOrChar() (string, token.TokenType, string) {
+func (l *Lexer) readLifetimeOrChar() (string, token.TokenType) {
 	// at '\''
 	// if pattern is '\'x\'' -> char (single rune possibly escaped)
 	// else it's lifetime: '\'name'
 	start := l.pos
 	l.readChar() // skip '
+	// экранированный символ '\n', '\u{41}' — всегда CHAR
+	if l.ch == '\\' {
+		line, col := l.line, l.col
+		l.readEscape(false)
+		if l.err != nil {
+			return "", token.CHAR
+		}
+		if l.ch != '\'' {
+			l.err = fmt.Errorf("unterminated character literal at line %d, col %d", line, col)
+			return "", token.CHAR
+		}
+		l.readChar()
+		return string(l.runes[start:l.pos]), token.CHAR
+	}
 	// собираем буквы/цифры/подчёркивания (имя lifetime)
 	for unicode.IsLetter(l.ch) || unicode.IsDigit(l.ch) || l.ch == '_' {
 		l.readChar()
@@ -179,16 +217,42 @@ func (l *Lexer) readLifetimeOrChar() (string, token.TokenType, string) {
 	// если следующий символ — апостроф, то это формат 'x' -> CHAR
 	if l.ch == '\'' {
 		l.readChar()
-		return string(l.runes[start:l.pos]), token.TYPE, "CHAR"
+		return string(l.runes[start:l.pos]), token.CHAR
 	}
 	// иначе — lifetime (без завершающего апострофа)
-	return string(l.runes[start:l.pos]), token.LIFETIME, ""
+	return string(l.runes[start:l.pos]), token.LIFETIME
+}
+
+// readByteChar читает байтовый символ b'x' после префикса b. В отличие от 'x'
+// допускается ровно один ASCII-символ или байтовое экранирование (\xff, но не \u{...}).
+func (l *Lexer) readByteChar() string {
+	start := l.pos - 1 // префикс b
+	line, col := l.line, l.col
+	l.readChar() // skip '
+	switch {
+	case l.ch == '\\':
+		l.readEscape(true)
+		if l.err != nil {
+			return ""
+		}
+	case l.ch == '\'' || l.ch == '\n' || l.ch == 0 || l.ch > unicode.MaxASCII:
+		l.err = fmt.Errorf("invalid byte literal at line %d, col %d", line, col)
+		return ""
+	default:
+		l.readChar()
+	}
+	if l.ch != '\'' {
+		l.err = fmt.Errorf("unterminated byte literal at line %d, col %d", line, col)
+		return ""
+	}
+	l.readChar()
+	return string(l.runes[start:l.pos])
 }
 
 // readNumber читает целые и дробные литералы, учитывает префиксы 0b/0o/0x,
 // экспоненты, подчёркивания для разделения разрядов и суффиксы типов (u32, f64 и т.д.).
-func (l *Lexer) readNumber() (string, string) {
-	// возвращаем (literal, subtype) где subtype = "INT" или "FLOAT"
+// Возвращает текст литерала и тип токена: token.INT или token.FLOAT.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	start := l.pos
 	base := 10
 
@@ -242,13 +306,14 @@ func (l *Lexer) readNumber() (string, string) {
 
 	lit := string(l.runes[start:l.pos])
 	if isFloat {
-		return lit, "FLOAT"
+		return lit, token.FLOAT
 	}
-	return lit, "INT"
+	return lit, token.INT
 }
 
-func (l *Lexer) readString(prefix string) (string, string) {
-	// возвращаем (literal, subtype) где subtype == "STRING" (или "CHAR" для byte char handled separately)
+// readString читает строковый литерал с префиксом prefix ("", "r", "b", "br") вместе
+// с префиксом.
+func (l *Lexer) readString(prefix string) string {
 	start := l.pos - len([]rune(prefix))
 	hashCount := 0
 
@@ -259,7 +324,7 @@ func (l *Lexer) readString(prefix string) (string, string) {
 		}
 		if l.ch != '"' {
 			l.err = fmt.Errorf("invalid raw string literal at line %d, col %d", l.line, l.col)
-			return "", ""
+			return ""
 		}
 	}
 
@@ -282,40 +347,88 @@ func (l *Lexer) readString(prefix string) (string, string) {
 			}
 		}
 	} else {
-		for l.ch != '"' && l.ch != 0 {
+		for l.ch != '"' && l.ch != 0 && l.err == nil {
 			if l.ch == '\\' {
-				l.readChar() // Escape char
-				if l.ch == '\n' || l.ch == '\r' {
-					if l.ch == '\r' && l.peek() == '\n' {
-						l.readChar()
-					}
-					l.readChar()
-					continue
-				}
+				l.readEscape(prefix == "b")
+				continue
 			}
 			l.readChar()
 		}
 		if l.ch == '"' {
 			l.readChar()
-		} else {
+		} else if l.err == nil {
 			l.err = fmt.Errorf("unterminated string literal at line %d, col %d", l.line, l.col)
 		}
 	}
 
-	return string(l.runes[start:l.pos]), "STRING"
+	return string(l.runes[start:l.pos])
+}
+
+// readEscape читает escape-последовательность, начинающуюся с '\\', вместе с экранированным
+// символом. Формы \xNN и \u{NNNN} проверяются сразу: \x принимает ровно две
+// шестнадцатеричные цифры (в обычных литералах не больше 0x7F), \u — от одной до шести
+// цифр в фигурных скобках, задающих допустимую руну; в байтовых литералах \u запрещён.
+// Ошибка записывается в l.err.
+func (l *Lexer) readEscape(inByteLit bool) {
+	line, col := l.line, l.col
+	l.readChar() // потребляем '\\'
+	switch l.ch {
+	case 'x':
+		l.readChar()
+		start := l.pos
+		for l.pos-start < 2 && isDigitInBase(l.ch, 16) {
+			l.readChar()
+		}
+		digits := string(l.runes[start:l.pos])
+		code, err := strconv.ParseUint(digits, 16, 8)
+		if len(digits) != 2 || err != nil || (code > 0x7f && !inByteLit) {
+			l.err = fmt.Errorf("invalid \\x escape at line %d, col %d", line, col)
+		}
+	case 'u':
+		l.readChar()
+		if l.ch != '{' || inByteLit {
+			l.err = fmt.Errorf("invalid \\u escape at line %d, col %d", line, col)
+			return
+		}
+		l.readChar()
+		start := l.pos
+		for l.ch != '}' && l.ch != '"' && l.ch != '\'' && l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+		digits := strings.ReplaceAll(string(l.runes[start:l.pos]), "_", "")
+		code, err := strconv.ParseUint(digits, 16, 32)
+		if l.ch != '}' || err != nil || len(digits) == 0 || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+			l.err = fmt.Errorf("invalid \\u escape at line %d, col %d", line, col)
+			return
+		}
+		l.readChar() // потребляем '}'
+	case '\r':
+		// Продолжение строки с переводом \r\n
+		l.readChar()
+		if l.ch == '\n' {
+			l.readChar()
+		}
+	case 0:
+		// Конец ввода: о незакрытом литерале сообщит вызывающий
+	default:
+		l.readChar()
+	}
 }
 
-// readAttr читает атрибуты Rust: #[...] и #![...]
+// readAttr читает атрибуты Rust: внешние #[...] и внутренние #![...], которые относятся
+// к объемлющему модулю или крейту. Возвращает литерал и подтип "OUTER" или "INNER".
 // Поддерживает вложенные квадратные скобки внутри атрибута.
-func (l *Lexer) readAttr() string {
+func (l *Lexer) readAttr() (string, string) {
 	start := l.pos
+	subtype := "OUTER"
 	l.readChar() // #
 	if l.ch == '!' {
 		l.readChar() // Consume #!
+		subtype = "INNER"
 	}
 	if l.ch != '[' {
 		l.err = fmt.Errorf("invalid attribute syntax: expected '[' at line %d, col %d", l.line, l.col)
-		return ""
+		return "", subtype
 	}
 	l.readChar() // [
 	depth := 1
@@ -330,7 +443,7 @@ func (l *Lexer) readAttr() string {
 	if depth > 0 {
 		l.err = fmt.Errorf("unterminated attribute at line %d, col %d", l.line, l.col)
 	}
-	return string(l.runes[start:l.pos])
+	return string(l.runes[start:l.pos]), subtype
 }
 
 // readOpOrPunct читает операторы и пунктуацию, пытаясь сначала матчить
@@ -378,12 +491,13 @@ func containsDotOrExp(s string) bool {
 
 // nextToken — центральная функция, которая анализирует текущую руну и формирует токен.
 // Ведёт себя итеративно: пропускает пробелы/комментарии, затем вызывает соответствующие читатели.
-func (l *Lexer) nextToken() {
+// Возвращает false, если токен не сформирован (комментарий или конец ввода).
+func (l *Lexer) nextToken() (token.Token, bool) {
 	l.skipWhitespace()
 
 	if l.ch == '/' && (l.peek() == '/' || l.peek() == '*') {
 		l.skipComment()
-		return
+		return token.Token{}, false
 	}
 
 	var tok token.Token
@@ -392,42 +506,19 @@ func (l *Lexer) nextToken() {
 
 	switch {
 	case l.ch == 0:
-		return
+		return token.Token{}, false
 	case l.ch == '\'' && (unicode.IsLetter(l.peek()) || l.peek() == '_'):
 		// need to distinguish lifetime vs char: check next-next char for closing '
-		// use helper that returns subtype for CHAR
-		lit, ttype, subtype := l.readLifetimeOrChar()
-		tok.Literal = lit
-		if ttype == token.TYPE {
-			tok.Type = token.TYPE
-			tok.Subtype = subtype // "CHAR"
-		} else {
-			tok.Type = token.LIFETIME
-		}
+		tok.Literal, tok.Type = l.readLifetimeOrChar()
 	case unicode.IsLetter(l.ch) || l.ch == '_':
 		prefix := l.readIdentifier()
 		switch {
-		case prefix == "r" && (l.ch == '"' || l.ch == '#'):
-			lit, subtype := l.readString("r")
-			tok.Literal = lit
-			tok.Type = token.TYPE
-			tok.Subtype = subtype // "STRING"
-		case prefix == "br" && (l.ch == '"' || l.ch == '#'):
-			lit, subtype := l.readString("br")
-			tok.Literal = lit
-			tok.Type = token.TYPE
-			tok.Subtype = subtype
-		case prefix == "b" && l.ch == '"':
-			lit, subtype := l.readString("b")
-			tok.Literal = lit
-			tok.Type = token.TYPE
-			tok.Subtype = subtype
+		case (prefix == "r" || prefix == "br") && (l.ch == '"' || l.ch == '#'), prefix == "b" && l.ch == '"':
+			tok.Literal = l.readString(prefix)
+			tok.Type = token.STRING
 		case prefix == "b" && l.ch == '\'':
-			// byte char literal
-			lit, _ := l.readString("b")
-			tok.Literal = lit
-			tok.Type = token.TYPE
-			tok.Subtype = "CHAR"
+			tok.Literal = l.readByteChar()
+			tok.Type = token.CHAR
 		default:
 			tok.Literal = prefix
 			if l.keywords[tok.Literal] {
@@ -441,26 +532,14 @@ func (l *Lexer) nextToken() {
 			}
 		}
 	case unicode.IsDigit(l.ch):
-		lit, subtype := l.readNumber()
-		tok.Literal = lit
-		tok.Type = token.TYPE
-		tok.Subtype = subtype // "INT" or "FLOAT"
+		tok.Literal, tok.Type = l.readNumber()
 	case l.ch == '"':
-		lit, subtype := l.readString("")
-		tok.Literal = lit
+		tok.Literal = l.readString("")
 		tok.Type = token.STRING
-		tok.Subtype = subtype // "STRING"
 	case l.ch == '\'':
-		lit, ttype, subtype := l.readLifetimeOrChar()
-		tok.Literal = lit
-		if ttype == token.TYPE {
-			tok.Type = token.TYPE
-			tok.Subtype = subtype
-		} else {
-			tok.Type = token.LIFETIME
-		}
+		tok.Literal, tok.Type = l.readLifetimeOrChar()
 	case l.ch == '#':
-		tok.Literal = l.readAttr()
+		tok.Literal, tok.Subtype = l.readAttr()
 		tok.Type = token.ATTRIBUTE
 	default:
 		// операторы и пунктуация
@@ -481,7 +560,5 @@ func (l *Lexer) nextToken() {
 		}
 	}
 
-	if l.err == nil {
-		l.tokens = append(l.tokens, tok)
-	}
+	return tok, true
 }
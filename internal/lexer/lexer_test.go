@@ -60,18 +60,53 @@ func TestLexIdentifiers(t *testing.T) {
 	}
 }
 
+func TestLexUnderscore(t *testing.T) {
+	toks, err := lexer.NewLexer().Lex("_ _x __ _1 (_, y)")
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+
+	expected := []struct {
+		literal  string
+		wildcard bool
+	}{
+		{"_", true},
+		{"_x", false},
+		{"__", false},
+		{"_1", false},
+		{"(", false},
+		{"_", true},
+		{",", false},
+		{"y", false},
+		{")", false},
+	}
+	if len(toks) != len(expected)+1 { // +1 for EOF
+		t.Fatalf("Expected %d tokens, got %d: %v", len(expected)+1, len(toks), toks)
+	}
+	for i, exp := range expected {
+		if toks[i].Literal != exp.literal {
+			t.Errorf("Token %d: expected %q, got %q", i, exp.literal, toks[i].Literal)
+		}
+		if toks[i].IsWildcard() != exp.wildcard {
+			t.Errorf("Token %d (%q): expected IsWildcard() == %v", i, toks[i].Literal, exp.wildcard)
+		}
+		if strings.HasPrefix(exp.literal, "_") && toks[i].Type != token.IDENT {
+			t.Errorf("Token %d (%q): expected IDENT, got %v", i, exp.literal, toks[i].Type)
+		}
+	}
+}
+
 func TestLexIntLiterals(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
-		subtype  string
 	}{
-		{"42", "42", "INT"},
-		{"0b1010", "0b1010", "INT"},
-		{"0o755", "0o755", "INT"},
-		{"0xFF", "0xFF", "INT"},
-		{"42i32", "42i32", "INT"},
-		{"1_000_000", "1_000_000", "INT"},
+		{"42", "42"},
+		{"0b1010", "0b1010"},
+		{"0o755", "0o755"},
+		{"0xFF", "0xFF"},
+		{"42i32", "42i32"},
+		{"1_000_000", "1_000_000"},
 	}
 
 	lx := lexer.NewLexer()
@@ -88,11 +123,11 @@ func TestLexIntLiterals(t *testing.T) {
 		}
 
 		tok := toks[0]
-		if tok.Type != token.TYPE {
-			t.Errorf("Token type: expected TYPE, got %v", tok.Type)
+		if tok.Type != token.INT {
+			t.Errorf("Token type: expected INT, got %s", tok)
 		}
-		if tok.Subtype != tt.subtype {
-			t.Errorf("Subtype: expected %q, got %q", tt.subtype, tok.Subtype)
+		if tok.Subtype != "" {
+			t.Errorf("Subtype: expected none, got %q", tok.Subtype)
 		}
 		if tok.Literal != tt.expected {
 			t.Errorf("Literal: expected %q, got %q", tt.expected, tok.Literal)
@@ -126,11 +161,8 @@ func TestLexFloatLiterals(t *testing.T) {
 		}
 
 		tok := toks[0]
-		if tok.Type != token.TYPE {
-			t.Errorf("Token type: expected TYPE, got %v", tok.Type)
-		}
-		if tok.Subtype != "FLOAT" {
-			t.Errorf("Subtype: expected FLOAT, got %q", tok.Subtype)
+		if tok.Type != token.FLOAT {
+			t.Errorf("Token type: expected FLOAT, got %s", tok)
 		}
 		if tok.Literal != tt.expected {
 			t.Errorf("Literal: expected %q, got %q", tt.expected, tok.Literal)
@@ -189,7 +221,7 @@ func TestLexOperators(t *testing.T) {
 
 func TestLexPunctuation(t *testing.T) {
 	lx := lexer.NewLexer()
-	toks, err := lx.Lex("() [] {} , ; : :: . ..")
+	toks, err := lx.Lex("() [] {} , ; : :: . .. ..=")
 	if err != nil {
 		t.Fatalf("Lex failed: %v", err)
 	}
@@ -210,11 +242,12 @@ func TestLexPunctuation(t *testing.T) {
 		{token.PUNCT, "::"},
 		{token.PUNCT, "."},
 		{token.PUNCT, ".."},
+		{token.PUNCT, "..="},
 	}
 
 	for i, exp := range expected {
 		if toks[i].Type != exp.typ {
-			t.Errorf("Token %d type: expected %v, got %v", i, exp.typ, toks[i].Type)
+			t.Errorf("Token %d type: expected %s, got %s", i, token.Token{Type: exp.typ}, toks[i])
 		}
 		if toks[i].Literal != exp.lit {
 			t.Errorf("Token %d: expected %q, got %q", i, exp.lit, toks[i].Literal)
@@ -275,9 +308,9 @@ func TestLexFunctionCall(t *testing.T) {
 		{token.PUNCT, ")"},
 		{token.IDENT, "bar"},
 		{token.PUNCT, "("},
-		{token.TYPE, "1"},
+		{token.INT, "1"},
 		{token.PUNCT, ","},
-		{token.TYPE, "2"},
+		{token.INT, "2"},
 		{token.PUNCT, ")"},
 	}
 
@@ -400,11 +433,8 @@ func TestLexRawString(t *testing.T) {
 		}
 
 		tok := toks[0]
-		if tok.Type != token.TYPE {
-			t.Errorf("Expected TYPE token, got %v", tok.Type)
-		}
-		if tok.Subtype != "STRING" {
-			t.Errorf("Expected STRING subtype, got %q", tok.Subtype)
+		if tok.Type != token.STRING {
+			t.Errorf("Expected STRING token, got %s", tok)
 		}
 	}
 }
@@ -421,11 +451,8 @@ func TestLexByteString(t *testing.T) {
 	}
 
 	tok := toks[0]
-	if tok.Type != token.TYPE {
-		t.Errorf("Expected TYPE token, got %v", tok.Type)
-	}
-	if tok.Subtype != "STRING" {
-		t.Errorf("Expected STRING subtype, got %q", tok.Subtype)
+	if tok.Type != token.STRING {
+		t.Errorf("Expected STRING token, got %s", tok)
 	}
 }
 
@@ -465,22 +492,41 @@ func TestLexCharLiteral(t *testing.T) {
 		}
 
 		tok := toks[0]
-		if tok.Type != token.TYPE {
-			t.Errorf("Expected TYPE token for %q, got %v", input, tok.Type)
+		if tok.Type != token.CHAR {
+			t.Errorf("Expected CHAR token for %q, got %s", input, tok)
+		}
+	}
+}
+
+func TestLexByteChar(t *testing.T) {
+	lx := lexer.NewLexer()
+	for _, input := range []string{`b'a'`, `b' '`, `b'\n'`, `b'\''`, `b'\xff'`} {
+		toks, err := lx.Lex(input + ` + 1`)
+		if err != nil {
+			t.Errorf("Lex(%s) failed: %v", input, err)
+			continue
 		}
-		if tok.Subtype != "CHAR" {
-			t.Errorf("Expected CHAR subtype for %q, got %q", input, tok.Subtype)
+		if toks[0].Type != token.CHAR || toks[0].Literal != input || toks[1].Literal != "+" {
+			t.Errorf("Lex(%s): expected a CHAR token followed by +, got %v", input, toks)
+		}
+	}
+
+	for _, input := range []string{`b'ab'`, `b''`, `b'é'`, `b'\u{41}'`, `b'a`} {
+		if _, err := lx.Lex(input); err == nil {
+			t.Errorf("Lex(%s): expected error", input)
 		}
 	}
 }
 
 func TestLexAttributes(t *testing.T) {
 	tests := []struct {
-		input string
+		input   string
+		subtype string
 	}{
-		{`#[derive(Debug)]`},
-		{`#![no_std]`},
-		{`#[cfg(feature = "foo")]`},
+		{`#[derive(Debug)]`, "OUTER"},
+		{`#![no_std]`, "INNER"},
+		{`#[cfg(feature = "foo")]`, "OUTER"},
+		{`#![allow(dead_code, unused)]`, "INNER"},
 	}
 
 	lx := lexer.NewLexer()
@@ -491,16 +537,15 @@ func TestLexAttributes(t *testing.T) {
 			continue
 		}
 
-		hasAttr := false
-		for _, tok := range toks {
-			if tok.Type == token.ATTRIBUTE {
-				hasAttr = true
-				break
-			}
+		if len(toks) != 2 || toks[0].Type != token.ATTRIBUTE {
+			t.Errorf("Expected a single ATTRIBUTE token in %q, got %v", tt.input, toks)
+			continue
 		}
-
-		if !hasAttr {
-			t.Errorf("Expected ATTRIBUTE token in %q", tt.input)
+		if toks[0].Literal != tt.input {
+			t.Errorf("Expected literal %q, got %q", tt.input, toks[0].Literal)
+		}
+		if toks[0].Subtype != tt.subtype {
+			t.Errorf("Expected subtype %s for %q, got %q", tt.subtype, tt.input, toks[0].Subtype)
 		}
 	}
 }
@@ -537,6 +582,69 @@ func TestLexStringEscape(t *testing.T) {
 	}
 }
 
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		lit  string
+		want string
+	}{
+		{`"plain"`, "plain"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"line\n\ttab"`, "line\n\ttab"},
+		{`"back\\slash"`, `back\slash`},
+		{`"quote at end\""`, `quote at end"`},
+		{`"\x41\u{1F600}"`, "A\U0001F600"},
+		{"\"a\\\n    b\"", "ab"},
+		{`r"C:\path"`, `C:\path`},
+		{`r#"say "hi""#`, `say "hi"`},
+		{`b"bytes"`, "bytes"},
+	}
+
+	for _, tt := range tests {
+		got, err := lexer.Unquote(tt.lit)
+		if err != nil {
+			t.Errorf("Unquote(%s) failed: %v", tt.lit, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Unquote(%s): expected %q, got %q", tt.lit, tt.want, got)
+		}
+	}
+
+	for _, lit := range []string{`"bad \q"`, `"\x80"`, `"\u{110000}"`, `unquoted`} {
+		if _, err := lexer.Unquote(lit); err == nil {
+			t.Errorf("Unquote(%s): expected error", lit)
+		}
+	}
+}
+
+func TestLexEscapes(t *testing.T) {
+	lx := lexer.NewLexer()
+	for _, input := range []string{`"\u{41}"`, `"\x41"`, `"\u{4_1}"`} {
+		toks, err := lx.Lex(input)
+		if err != nil {
+			t.Errorf("Lex(%s) failed: %v", input, err)
+			continue
+		}
+		if got, err := lexer.Unquote(toks[0].Literal); err != nil || got != "A" {
+			t.Errorf("Lex(%s): expected to decode to \"A\", got %q (%v)", input, got, err)
+		}
+	}
+
+	for _, input := range []string{`'\n'`, `'\u{1F600}'`, `'\''`} {
+		toks, err := lx.Lex(input)
+		if err != nil || toks[0].Type != token.CHAR || toks[0].Literal != input {
+			t.Errorf("Lex(%s): expected a single CHAR token, got %v (%v)", input, toks, err)
+		}
+	}
+
+	malformed := []string{`"\u{ZZ}"`, `"\u41"`, `"\u{41"`, `"\u{}"`, `"\u{1234567}"`, `"\x4"`, `"\xFF"`, `b"\u{41}"`, `'\u{ZZ}'`, `'\n`}
+	for _, input := range malformed {
+		if _, err := lx.Lex(input); err == nil {
+			t.Errorf("Lex(%s): expected error", input)
+		}
+	}
+}
+
 func TestLexComplexExpressions(t *testing.T) {
 	tests := []string{
 		`(1 + 2) * 3`,
@@ -602,3 +710,42 @@ func itoa(n int) string {
 	}
 	return string(b)
 }
+
+func TestNextTokenMatchesLex(t *testing.T) {
+	const src = "fn main(){}"
+	want, err := lexer.NewLexer().Lex(src)
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+
+	lx := lexer.NewLexer()
+	lx.Reset(src)
+	var got []token.Token
+	for len(got) <= len(want) {
+		tok, err := lx.NextToken()
+		if err != nil {
+			t.Fatalf("NextToken failed: %v", err)
+		}
+		got = append(got, tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d tokens, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Token %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+	if tok, err := lx.NextToken(); err != nil || tok.Type != token.EOF {
+		t.Errorf("Expected EOF to repeat after end of input, got %+v, %v", tok, err)
+	}
+
+	lx.Reset(`"unterminated`)
+	if _, err := lx.NextToken(); err == nil {
+		t.Error("Expected an error for an unterminated string")
+	}
+}
@@ -0,0 +1,91 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Unquote возвращает значение строкового литерала Rust в том виде, в каком он записан
+// в исходном коде (с кавычками и префиксами r/b/br): `"a\"b"` -> `a"b`.
+// Обычные строки декодируют экранирования \n, \r, \t, \\, \0, \', \", \xNN и \u{NNNN},
+// а также продолжение строки (`\` перед переводом строки пропускает ведущие пробелы
+// следующей строки). Сырые строки (`r"..."`, `r#"..."#`) возвращаются без изменений.
+func Unquote(lit string) (string, error) {
+	raw := false
+	switch {
+	case strings.HasPrefix(lit, "br"):
+		lit, raw = lit[2:], true
+	case strings.HasPrefix(lit, "r"):
+		lit, raw = lit[1:], true
+	case strings.HasPrefix(lit, "b"):
+		lit = lit[1:]
+	}
+
+	if raw {
+		hashes := len(lit) - len(strings.TrimLeft(lit, "#"))
+		lit = lit[hashes : len(lit)-hashes]
+	}
+	if len(lit) < 2 || lit[0] != '"' || lit[len(lit)-1] != '"' {
+		return "", fmt.Errorf("invalid string literal %s", lit)
+	}
+	body := lit[1 : len(lit)-1]
+	if raw {
+		return body, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			sb.WriteByte(body[i])
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", fmt.Errorf("unterminated escape in string literal")
+		}
+		switch ch := body[i]; ch {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '0':
+			sb.WriteByte(0)
+		case '\\', '\'', '"':
+			sb.WriteByte(ch)
+		case '\n', '\r':
+			// Продолжение строки: пропускаем перевод строки и ведущие пробелы
+			for i+1 < len(body) && strings.ContainsRune(" \t\r\n", rune(body[i+1])) {
+				i++
+			}
+		case 'x':
+			if i+2 >= len(body) {
+				return "", fmt.Errorf("invalid \\x escape in string literal")
+			}
+			code, err := strconv.ParseUint(body[i+1:i+3], 16, 8)
+			if err != nil || code > 0x7f {
+				return "", fmt.Errorf("invalid \\x escape in string literal")
+			}
+			sb.WriteByte(byte(code))
+			i += 2
+		case 'u':
+			end := strings.IndexByte(body[i:], '}')
+			if i+1 >= len(body) || body[i+1] != '{' || end < 0 {
+				return "", fmt.Errorf("invalid \\u escape in string literal")
+			}
+			digits := strings.ReplaceAll(body[i+2:i+end], "_", "")
+			code, err := strconv.ParseUint(digits, 16, 32)
+			if err != nil || len(digits) == 0 || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid \\u escape in string literal")
+			}
+			sb.WriteRune(rune(code))
+			i += end
+		default:
+			return "", fmt.Errorf("unknown character escape \\%c in string literal", ch)
+		}
+	}
+	return sb.String(), nil
+}
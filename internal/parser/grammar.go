@@ -6,14 +6,24 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/semetekare/rust2go/internal/ast"
 	"github.com/semetekare/rust2go/internal/token"
 )
 
-// leftAssoc — флаг, указывающий, что операторы левоассоциативны.
-// Используется при построении бинарных выражений.
-const leftAssoc = true
+// Precedence — таблица приоритетов бинарных операторов: чем больше значение, тем
+// сильнее оператор связывает операнды (`a + b * c` — это `a + (b * c)`). Уровни
+// повторяют порядок из справочника Rust; операторы одного уровня левоассоциативны.
+// Оператор, которого нет в таблице, завершает бинарное выражение.
+var Precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, ">": 3, "<=": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
 
 // ParseCrate парсит корневой узел AST — единицу компиляции (crate).
 // Грамматика: Crate ::= InnerAttribute* Item*
@@ -23,7 +33,17 @@ const leftAssoc = true
 func (p *Parser) ParseCrate() *ast.Crate {
 	pos := p.stream.Pos()
 	items := []ast.Item{}
+	last := -1
 	for !p.stream.IsEOF() {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
+		// Лишняя `;` между элементами (`fn a() {};`) допустима и ничего не значит
+		if p.stream.Peek().Type == token.TERMINATOR {
+			p.stream.Next()
+			continue
+		}
 		item := p.ParseItem()
 		if item != nil {
 			items = append(items, item)
@@ -42,67 +62,71 @@ func (p *Parser) ParseCrate() *ast.Crate {
 // ParseItem парсит элемент верхнего уровня (item): функцию, структуру и т.д.
 // Грамматика: Item ::= OuterAttribute* (Function | Struct | ... )?
 // Поддерживает пропуск атрибутов (например, #[derive(...)]).
-// На данный момент реализованы только "fn" и "struct".
+// На данный момент реализованы "fn", "struct", "enum" и "impl".
 // В случае неизвестного элемента возвращает nil и регистрирует ошибку.
 func (p *Parser) ParseItem() ast.Item {
-	// Пропускаем все атрибуты перед элементом
+	// Внешние атрибуты относятся к элементу; внутренние (`#![...]`) — к крейту и пропускаются
+	var attrs []string
 	for p.stream.Peek().Type == token.ATTRIBUTE {
-		p.stream.Next() // пропускаем атрибут
+		if attr := p.stream.Next(); attr.Subtype != "INNER" {
+			attrs = append(attrs, attr.Literal)
+		}
 	}
+	public := p.acceptPub()
 	tok := p.stream.Peek()
 	pos := tok.Pos()
 	if tok.Type == token.KEYWORD {
 		switch tok.Literal {
 		case "fn":
-			p.stream.Next() // потребляем "fn"
-			nameTok := p.expect(token.IDENT, "", "identifier after fn")
-			name := nameTok.Literal
-			// Парсим параметры функции
-			params := []ast.Param{}
-			p.expect(token.PUNCT, "(", "(")
-			// Обрабатываем пустой список параметров
-			for !p.stream.IsEOF() && !(p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == ")") {
-				paramNameTok := p.expect(token.IDENT, "", "param name")
-				paramName := paramNameTok.Literal
-				p.expect(token.PUNCT, ":", ":")
-				paramType := p.ParseType()
-				params = append(params, *ast.NewParam(paramNameTok.Pos(), paramName, paramType))
-				if p.stream.Peek().Literal == "," {
+			fn := p.parseFunction()
+			fn.Public = public
+			return fn
+		case "impl":
+			p.stream.Next() // потребляем "impl"
+			nameTok := p.expect(token.IDENT, "", "type name after impl")
+			p.expect(token.PUNCT, "{", "{")
+			methods := []*ast.Function{}
+			last := -1
+			for !p.stream.IsEOF() && !(p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "}") {
+				if p.stalled(&last) {
 					p.stream.Next()
 					continue
 				}
-				break
-			}
-			p.expect(token.PUNCT, ")", ")")
-			// Необязательный возвращаемый тип
-			var retType ast.Type
-			if p.stream.Peek().Literal == "->" {
-				p.stream.Next()
-				retType = p.ParseType()
-			} else {
-				retType = ast.NewPathType(pos, "()") // тип по умолчанию — unit
+				methodPublic := p.acceptPub()
+				if p.stream.Peek().Literal != "fn" {
+					p.error("expected fn in impl block", p.stream.Peek())
+					return nil
+				}
+				method := p.parseFunction()
+				method.Public = methodPublic
+				methods = append(methods, method)
 			}
-			body := p.ParseBlock()
-			return ast.NewFunction(pos, name, params, retType, body)
+			p.expect(token.PUNCT, "}", "}")
+			return ast.NewImpl(pos, nameTok.Literal, methods)
 		case "struct":
 			p.stream.Next()
 			nameTok := p.expect(token.IDENT, "", "struct name")
 			name := nameTok.Literal
-			p.expect(token.PUNCT, "{", "{")
-			fields := []ast.Field{}
-			for !p.stream.IsEOF() && !(p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "}") {
-				fieldNameTok := p.expect(token.IDENT, "", "field name")
-				p.expect(token.PUNCT, ":", ":")
-				fieldType := p.ParseType()
-				fields = append(fields, *ast.NewField(fieldNameTok.Pos(), fieldNameTok.Literal, fieldType))
-				if p.stream.Peek().Literal == "," {
-					p.stream.Next()
-					continue
-				}
-				break
+			var st *ast.Struct
+			switch next := p.stream.Peek(); {
+			case next.Type == token.TERMINATOR:
+				p.stream.Next()
+				st = ast.NewStruct(pos, name, []ast.Field{})
+				st.Kind = ast.StructUnit
+			case next.Type == token.PUNCT && next.Literal == "(":
+				st = ast.NewStruct(pos, name, p.parseTupleFields())
+				st.Kind = ast.StructTuple
+			default:
+				st = ast.NewStruct(pos, name, p.parseStructFields())
 			}
-			p.expect(token.PUNCT, "}", "}")
-			return ast.NewStruct(pos, name, fields)
+			st.Public = public
+			st.Attrs = attrs
+			return st
+		case "enum":
+			en := p.parseEnum()
+			en.Public = public
+			en.Attrs = attrs
+			return en
 		}
 	}
 	// Не распознан элемент верхнего уровня
@@ -110,65 +134,544 @@ func (p *Parser) ParseItem() ast.Item {
 	return nil
 }
 
-// ParseExpr парсит выражение с учётом приоритетов операторов.
-// Использует рекурсивный спуск и вспомогательный метод parseBinary для обработки
-// бинарных операций. Поддерживаемые операторы: сравнения, арифметика, логические.
-func (p *Parser) ParseExpr() ast.Expr {
-	return p.parseBinary(p.parseUnary, []string{"==", "!=", "<", ">", "+", "-", "*", "/", "%", "&&", "||"}, leftAssoc)
+// parseEnum парсит определение перечисления, начиная с ключевого слова "enum".
+// Грамматика: Enum ::= "enum" IDENTIFIER "{" Variant ("," Variant)* [","] "}"
+func (p *Parser) parseEnum() *ast.Enum {
+	pos := p.stream.Next().Pos() // потребляем "enum"
+	nameTok := p.expect(token.IDENT, "", "enum name")
+	p.expect(token.PUNCT, "{", "{")
+	variants := []ast.Variant{}
+	last := -1
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != "}" {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
+		variants = append(variants, *p.parseVariant())
+		if !p.listSeparator("}") {
+			break
+		}
+	}
+	p.expect(token.PUNCT, "}", "}")
+	return ast.NewEnum(pos, nameTok.Literal, variants)
 }
 
-// parseBinary — обобщённый метод для парсинга бинарных выражений.
-// Принимает:
-//   - nextParser: функцию для парсинга подвыражения более высокого приоритета,
-//   - ops: список операторов текущего приоритета,
-//   - assoc: ассоциативность (в текущей реализации всегда левая).
-//
-// Возвращает построенное бинарное выражение или nil в случае ошибки.
-func (p *Parser) parseBinary(nextParser func() ast.Expr, ops []string, assoc bool) ast.Expr {
-	expr := nextParser()
-	for {
-		if expr == nil {
-			return nil
+// parseStructFields парсит поля структуры в фигурных скобках: `{ pub x: i32, y: i32 }`.
+func (p *Parser) parseStructFields() []ast.Field {
+	p.expect(token.PUNCT, "{", "{")
+	fields := []ast.Field{}
+	last := -1
+	for !p.stream.IsEOF() && !(p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "}") {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
 		}
-		opTok := p.stream.Peek()
-		if !(opTok.Type == token.OPERATOR || opTok.Type == token.PUNCT) {
+		fieldPublic := p.acceptPub()
+		fieldNameTok := p.expect(token.IDENT, "", "field name")
+		p.expect(token.PUNCT, ":", ":")
+		fieldType := p.ParseType()
+		field := ast.NewField(fieldNameTok.Pos(), fieldNameTok.Literal, fieldType)
+		field.Public = fieldPublic
+		fields = append(fields, *field)
+		if !p.listSeparator("}") {
 			break
 		}
-		op := opTok.Literal
-		found := false
-		for _, o := range ops {
-			if op == o {
-				found = true
+	}
+	p.expect(token.PUNCT, "}", "}")
+	return fields
+}
+
+// parseTupleFields парсит поля кортежной структуры `(pub f64, i32);` вместе с завершающей `;`.
+// Поле получает имя по своей позиции: "0", "1", ...
+// Грамматика: TupleField ::= ["pub"] Type
+func (p *Parser) parseTupleFields() []ast.Field {
+	p.expect(token.PUNCT, "(", "(")
+	fields := []ast.Field{}
+	last := -1
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != ")" {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
+		fieldPublic := p.acceptPub()
+		pos := p.stream.Pos()
+		field := ast.NewField(pos, strconv.Itoa(len(fields)), p.ParseType())
+		field.Public = fieldPublic
+		fields = append(fields, *field)
+		if !p.listSeparator(")") {
+			break
+		}
+	}
+	p.expect(token.PUNCT, ")", ")")
+	p.expect(token.TERMINATOR, ";", ";")
+	return fields
+}
+
+// parseVariant парсит вариант перечисления: `Red`, `Rgb(u8, u8, u8)` или `Move { x: i32 }`.
+// Грамматика: Variant ::= IDENTIFIER [ "(" Type ("," Type)* ")" | "{" Field ("," Field)* "}" ]
+func (p *Parser) parseVariant() *ast.Variant {
+	nameTok := p.expect(token.IDENT, "", "variant name")
+	var types []ast.Type
+	var fields []ast.Field
+	switch p.stream.Peek().Literal {
+	case "(":
+		p.stream.Next()
+		for !p.stream.IsEOF() && p.stream.Peek().Literal != ")" {
+			typ := p.ParseType()
+			if typ == nil {
+				break
+			}
+			types = append(types, typ)
+			if !p.listSeparator(")") {
+				break
+			}
+		}
+		p.expect(token.PUNCT, ")", ")")
+	case "{":
+		p.stream.Next()
+		for !p.stream.IsEOF() && p.stream.Peek().Literal != "}" {
+			fields = append(fields, *p.ParseField())
+			if !p.listSeparator("}") {
 				break
 			}
 		}
-		if !found {
+		p.expect(token.PUNCT, "}", "}")
+	}
+	return ast.NewVariant(nameTok.Pos(), nameTok.Literal, types, fields)
+}
+
+// parseFunction парсит определение функции, начиная с ключевого слова "fn".
+// Грамматика: Function ::= "fn" IDENTIFIER [GenericParams] "(" [SelfParam ","] Param* ")" ["->" Type] [WhereClause] Block
+// Используется как для свободных функций, так и для методов в блоках impl.
+func (p *Parser) parseFunction() *ast.Function {
+	pos := p.stream.Next().Pos() // потребляем "fn"
+	nameTok := p.expect(token.IDENT, "", "identifier after fn")
+	name := nameTok.Literal
+	typeParams, bounds := p.parseGenericParams()
+	// Парсим параметры функции
+	params := []ast.Param{}
+	p.expect(token.PUNCT, "(", "(")
+	// Первым параметром метода может быть self
+	receiver := p.parseReceiver()
+	// Обрабатываем пустой список параметров
+	last := -1
+	for !p.stream.IsEOF() && !(p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == ")") {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
+		mutable := p.acceptMut()
+		paramNameTok := p.expect(token.IDENT, "", "param name")
+		paramName := paramNameTok.Literal
+		p.expect(token.PUNCT, ":", ":")
+		paramType := p.ParseType()
+		param := ast.NewParam(paramNameTok.Pos(), paramName, paramType)
+		param.Mutable = mutable
+		params = append(params, *param)
+		if !p.listSeparator(")") {
+			break
+		}
+	}
+	p.expect(token.PUNCT, ")", ")")
+	// Необязательный возвращаемый тип
+	var retType ast.Type
+	if p.stream.Peek().Literal == "->" {
+		p.stream.Next()
+		retType = p.ParseType()
+	} else {
+		retType = ast.NewPathType(pos, "()") // тип по умолчанию — unit
+	}
+	bounds = p.parseWhereClause(bounds)
+	body := p.ParseBlock()
+	fn := ast.NewFunction(pos, name, params, retType, body)
+	fn.TypeParams = typeParams
+	fn.Bounds = bounds
+	fn.Receiver = receiver
+	return fn
+}
+
+// parseGenericParams парсит необязательный список обобщённых параметров: `<'a, T: Bound, U>`.
+// Грамматика: GenericParams ::= "<" (LIFETIME [":" LIFETIME] | IDENTIFIER [":" Bounds]) ("," ...)* ">"
+// Lifetime'ы в Go не нужны и отбрасываются.
+// Возвращает имена параметров типа в порядке объявления и их ограничения-трейты.
+func (p *Parser) parseGenericParams() ([]string, map[string][]string) {
+	if !(p.stream.Peek().Type == token.OPERATOR && p.stream.Peek().Literal == "<") {
+		return nil, nil
+	}
+	p.stream.Next() // потребляем '<'
+
+	typeParams := []string{}
+	var bounds map[string][]string
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != ">" {
+		tok := p.stream.Peek()
+		switch tok.Type {
+		case token.LIFETIME:
+			p.stream.Next()
+			if p.stream.Peek().Literal == ":" {
+				p.stream.Next()
+				p.expect(token.LIFETIME, "", "lifetime bound")
+			}
+		case token.IDENT:
+			p.stream.Next()
+			typeParams = append(typeParams, tok.Literal)
+			if p.stream.Peek().Literal == ":" {
+				p.stream.Next()
+				bounds = addBounds(bounds, tok.Literal, p.parseBounds())
+			}
+		default:
+			p.error("expected lifetime or type parameter", tok)
+			return typeParams, bounds
+		}
+		if p.stream.Peek().Literal == "," {
+			p.stream.Next()
+			continue
+		}
+		break
+	}
+	p.expect(token.OPERATOR, ">", ">")
+	return typeParams, bounds
+}
+
+// parseWhereClause парсит необязательное предложение where перед телом функции
+// и добавляет его ограничения к bounds. Ключом служит ограничиваемый тип в записи Rust.
+// Грамматика: WhereClause ::= "where" Type ":" Bounds ("," Type ":" Bounds)* [","]
+func (p *Parser) parseWhereClause(bounds map[string][]string) map[string][]string {
+	if tok := p.stream.Peek(); !(tok.Type == token.KEYWORD && tok.Literal == "where") {
+		return bounds
+	}
+	p.stream.Next() // потребляем "where"
+
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != "{" {
+		bounded := typeString(p.ParseType())
+		p.expect(token.PUNCT, ":", ":")
+		bounds = addBounds(bounds, bounded, p.parseBounds())
+		if p.stream.Peek().Literal != "," {
+			break
+		}
+		p.stream.Next()
+	}
+	return bounds
+}
+
+// addBounds добавляет ограничения параметра name, создавая карту при первом ограничении.
+func addBounds(bounds map[string][]string, name string, traits []string) map[string][]string {
+	if len(traits) == 0 {
+		return bounds
+	}
+	if bounds == nil {
+		bounds = map[string][]string{}
+	}
+	bounds[name] = append(bounds[name], traits...)
+	return bounds
+}
+
+// parseBounds парсит список ограничений `A + B + 'a` и возвращает трейты;
+// lifetime-ограничения отбрасываются.
+// Грамматика: Bounds ::= Bound ("+" Bound)*
+func (p *Parser) parseBounds() []string {
+	traits := []string{}
+	for {
+		if p.stream.Peek().Type == token.LIFETIME {
+			p.stream.Next()
+		} else {
+			traits = append(traits, typeString(p.ParseType()))
+		}
+		if !(p.stream.Peek().Type == token.OPERATOR && p.stream.Peek().Literal == "+") {
+			return traits
+		}
+		p.stream.Next()
+	}
+}
+
+// typeString возвращает запись типа в синтаксисе Rust: `Into<String>`, `&mut T`, `[u8; 4]`.
+func typeString(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.PathType:
+		return t.Path
+	case *ast.RefType:
+		if t.Mutable {
+			return "&mut " + typeString(t.Elem)
+		}
+		return "&" + typeString(t.Elem)
+	case *ast.GenericType:
+		args := make([]string, 0, len(t.Args))
+		for _, arg := range t.Args {
+			args = append(args, typeString(arg))
+		}
+		return t.Path + "<" + strings.Join(args, ", ") + ">"
+	case *ast.TupleType:
+		elems := make([]string, 0, len(t.Elems))
+		for _, elem := range t.Elems {
+			elems = append(elems, typeString(elem))
+		}
+		return "(" + strings.Join(elems, ", ") + ")"
+	case *ast.ArrayType:
+		if lit, ok := t.Len.(*ast.Literal); ok {
+			return "[" + typeString(t.Elem) + "; " + lit.Val + "]"
+		}
+		return "[" + typeString(t.Elem) + "]"
+	case nil:
+		return ""
+	}
+	return t.String()
+}
+
+// parseReceiver парсит необязательный параметр self метода: `self`, `mut self`, `&self` или `&mut self`.
+// Тип параметра записывается через `Self`; для &self — как ссылка на `Self`.
+// Возвращает nil, если список параметров не начинается с self.
+func (p *Parser) parseReceiver() *ast.Param {
+	// Определяем длину префикса (&, mut) перед self, не потребляя токены
+	n := 0
+	isRef := p.stream.PeekN(n).Type == token.OPERATOR && p.stream.PeekN(n).Literal == "&"
+	if isRef {
+		n++
+	}
+	mutable := p.stream.PeekN(n).Type == token.KEYWORD && p.stream.PeekN(n).Literal == "mut"
+	if mutable {
+		n++
+	}
+	if selfTok := p.stream.PeekN(n); !(selfTok.Type == token.KEYWORD && selfTok.Literal == "self") {
+		return nil
+	}
+
+	refPos := p.stream.Pos()
+	for i := 0; i < n; i++ {
+		p.stream.Next() // потребляем '&' и "mut"
+	}
+	selfTok := p.stream.Next() // потребляем "self"
+
+	var typ ast.Type = ast.NewPathType(selfTok.Pos(), "Self")
+	param := ast.NewParam(selfTok.Pos(), "self", typ)
+	if isRef {
+		param.Type = ast.NewRefType(refPos, mutable, typ)
+	} else {
+		param.Mutable = mutable
+	}
+	if p.stream.Peek().Literal == "," {
+		p.stream.Next()
+	}
+	return param
+}
+
+// ParseExpr парсит выражение с учётом приоритетов операторов из таблицы Precedence.
+// Диапазон `a..b` (`a..=b`) связывает слабее всех бинарных операторов.
+// Грамматика: Expr ::= BinaryExpr [(".." | "..=") BinaryExpr]
+func (p *Parser) ParseExpr() ast.Expr {
+	expr := p.parseBinary(1)
+	if expr == nil {
+		return nil
+	}
+	opTok := p.stream.Peek()
+	if opTok.Type != token.PUNCT || opTok.Literal != ".." && opTok.Literal != "..=" {
+		return expr
+	}
+	p.stream.Next()
+	errCount := len(p.errors)
+	end := p.parseBinary(1)
+	if end == nil {
+		if len(p.errors) == errCount {
+			p.error("expected range end", p.stream.Peek())
+		}
+		return nil
+	}
+	return ast.NewRangeExpr(expr.Pos(), expr, end, opTok.Literal == "..=")
+}
+
+// parseBinary разбирает бинарное выражение, операторы которого имеют приоритет
+// не ниже minPrec (метод подъёма по приоритетам). Правый операнд разбирается
+// с приоритетом на единицу выше, поэтому операторы одного уровня группируются слева.
+// Возвращает nil в случае ошибки.
+func (p *Parser) parseBinary(minPrec int) ast.Expr {
+	expr := p.parseCast()
+	for expr != nil {
+		opTok := p.stream.Peek()
+		if !(opTok.Type == token.OPERATOR || opTok.Type == token.PUNCT) {
+			break
+		}
+		prec, ok := Precedence[opTok.Literal]
+		if !ok || prec < minPrec {
 			break
 		}
 		p.stream.Next()
-		right := nextParser()
+		errCount := len(p.errors)
+		right := p.parseBinary(prec + 1)
 		if right == nil {
-			p.error("expected expression after operator", p.stream.Peek())
+			// Ошибку правого операнда уже мог сообщить его парсер
+			if len(p.errors) == errCount {
+				p.error("expected expression after operator", p.stream.Peek())
+			}
+			return nil
+		}
+		expr = ast.NewBinaryExpr(expr.Pos(), expr, opTok.Literal, right)
+	}
+	return expr
+}
+
+// parseCast парсит приведения `expr as Type`. Оператор as связывает сильнее бинарных
+// операторов, но слабее унарных: `-x as i64 * 2` — это `((-x) as i64) * 2`.
+// Приведения левоассоциативны: `x as i32 as f64`.
+func (p *Parser) parseCast() ast.Expr {
+	expr := p.parseUnary()
+	for expr != nil && p.stream.Peek().Type == token.KEYWORD && p.stream.Peek().Literal == "as" {
+		p.stream.Next() // потребляем "as"
+		typ := p.ParseType()
+		if typ == nil {
 			return nil
 		}
-		expr = ast.NewBinaryExpr(expr.Pos(), expr, op, right)
+		expr = ast.NewCastExpr(expr.Pos(), expr, typ)
 	}
 	return expr
 }
 
-// parseUnary парсит унарные выражения: `-x`, `!flag`, `~bits`.
-// Если унарный оператор отсутствует, делегирует парсинг primary-выражениям.
+// parseUnary парсит унарные выражения: `-x`, `!flag`, `~bits`, разыменование `*p`.
+// Если унарный оператор отсутствует, делегирует парсинг постфиксным выражениям.
 func (p *Parser) parseUnary() ast.Expr {
 	tok := p.stream.Peek()
-	if tok.Type == token.OPERATOR && (tok.Literal == "-" || tok.Literal == "!" || tok.Literal == "~") {
+	if tok.Type == token.OPERATOR && tok.Literal == "&" {
+		// Взятие ссылки: `&x` или `&mut x`
+		p.stream.Next()
+		op := "&"
+		if p.acceptMut() {
+			op = "&mut"
+		}
+		operand := p.parseUnary()
+		if operand == nil {
+			return nil
+		}
+		return ast.NewUnaryExpr(tok.Pos(), op, operand)
+	}
+	if tok.Type == token.OPERATOR && (tok.Literal == "-" || tok.Literal == "!" || tok.Literal == "~" || tok.Literal == "*") {
 		p.stream.Next()
-		primary := p.parsePrimary()
+		primary := p.parsePostfix()
 		if primary == nil {
 			return nil
 		}
 		return ast.NewUnaryExpr(tok.Pos(), tok.Literal, primary)
 	}
-	return p.parsePrimary()
+	return p.parsePostfix()
+}
+
+// parsePostfix парсит постфиксные операции над первичным выражением:
+// вызовы методов `recv.method(args)`, в том числе цепочки `a.b().c()`,
+// доступ к полям `p.x` и элементам кортежа `t.0`, индексирование `v[i]`
+// и распространение ошибки `f()?`.
+func (p *Parser) parsePostfix() ast.Expr {
+	expr := p.parsePrimary()
+	for expr != nil {
+		tok := p.stream.Peek()
+		if tok.Type == token.PUNCT && tok.Literal == "." && isTupleIndex(p.stream.PeekN(1)) {
+			p.stream.Next() // потребляем '.'
+			expr = tupleFieldExpr(expr, p.stream.Next())
+			continue
+		}
+		if tok.Type == token.PUNCT && tok.Literal == "." {
+			p.stream.Next() // потребляем '.'
+			nameTok := p.expect(token.IDENT, "", "field or method name after '.'")
+			if nameTok.Type != token.IDENT {
+				return nil
+			}
+			// Без '(' это доступ к полю, а не вызов метода
+			if !(p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "(") {
+				expr = ast.NewFieldExpr(nameTok.Pos(), expr, nameTok.Literal)
+				continue
+			}
+			p.stream.Next() // потребляем '('
+			args := p.parseCallArgs()
+			expr = ast.NewMethodCall(nameTok.Pos(), expr, nameTok.Literal, args)
+			continue
+		}
+		if tok.Type == token.PUNCT && tok.Literal == "?" {
+			p.stream.Next() // потребляем '?'
+			expr = ast.NewTryExpr(tok.Pos(), expr)
+			continue
+		}
+		if tok.Type == token.PUNCT && tok.Literal == "[" {
+			p.stream.Next() // потребляем '['
+			index := p.ParseExpr()
+			if index == nil {
+				return nil
+			}
+			if p.expect(token.PUNCT, "]", "]").Literal != "]" {
+				return nil
+			}
+			expr = ast.NewIndexExpr(tok.Pos(), expr, index)
+			continue
+		}
+		break
+	}
+	return expr
+}
+
+// isTupleIndex сообщает, является ли токен после '.' номером элемента кортежа: `0` в `t.0`
+// или `0.1` в `t.0.1` (лексер читает два номера подряд как дробное число).
+func isTupleIndex(tok token.Token) bool {
+	switch tok.Type {
+	case token.INT:
+		return isDecimal(tok.Literal)
+	case token.FLOAT:
+		first, second, ok := strings.Cut(tok.Literal, ".")
+		return ok && isDecimal(first) && isDecimal(second)
+	}
+	return false
+}
+
+// isDecimal сообщает, состоит ли строка только из десятичных цифр.
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// tupleFieldExpr строит доступ к элементу кортежа по номеру из токена tok;
+// токен `0.1` даёт два вложенных доступа: `(t.0).1`.
+func tupleFieldExpr(recv ast.Expr, tok token.Token) ast.Expr {
+	first, second, nested := strings.Cut(tok.Literal, ".")
+	expr := ast.NewFieldExpr(tok.Pos(), recv, first)
+	if nested {
+		pos := tok.Pos()
+		pos.Col += len(first) + 1
+		expr = ast.NewFieldExpr(pos, expr, second)
+	}
+	return expr
+}
+
+// parseCallArgs парсит аргументы вызова после открывающей '(' вплоть до ')' включительно.
+// При ошибке в аргументе восстанавливается до ближайшей ',' или ')';
+// если итерация не потребила ни одного токена, разбор списка завершается.
+func (p *Parser) parseCallArgs() []ast.Expr {
+	args := []ast.Expr{}
+
+	// Пустой список аргументов
+	if p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == ")" {
+		p.stream.Next()
+		return args
+	}
+
+	// Парсим аргументы
+	last := -1
+	for !p.stream.IsEOF() && !p.stalled(&last) {
+		arg := p.ParseExpr()
+		if arg != nil {
+			args = append(args, arg)
+		} else {
+			// Ошибка в аргументе: восстанавливаемся до ',' или ')'
+			for !p.stream.IsEOF() && !(p.stream.Peek().Literal == "," || p.stream.Peek().Literal == ")") {
+				p.stream.Next()
+			}
+		}
+		if !p.listSeparator(")") {
+			break
+		}
+	}
+
+	p.expect(token.PUNCT, ")", ")")
+	return args
 }
 
 // parsePrimary парсит первичные (атомарные) выражения:
@@ -179,74 +682,116 @@ func (p *Parser) parseUnary() ast.Expr {
 func (p *Parser) parsePrimary() ast.Expr {
 	tok := p.stream.Peek()
 	pos := tok.Pos()
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > p.maxDepth {
+		p.error("maximum expression depth exceeded", tok)
+		p.skipNested()
+		return nil
+	}
 	switch tok.Type {
-	case token.TYPE: // Для числовых литералов с подтипом (например, INT, FLOAT)
-		p.stream.Next()
-		return ast.NewLiteral(pos, tok.Subtype, tok.Literal)
-	case token.CHAR:
-		p.stream.Next()
-		return ast.NewLiteral(pos, "CHAR", tok.Literal)
-	case token.INT, token.FLOAT:
-		p.stream.Next()
-		return ast.NewLiteral(pos, tok.Type.String(), tok.Literal)
-	case token.STRING:
+	case token.INT, token.FLOAT, token.STRING, token.CHAR:
+		// Вид литерала в AST совпадает с именем типа токена: "INT", "FLOAT", "STRING", "CHAR"
 		p.stream.Next()
-		return ast.NewLiteral(pos, "STRING", tok.Literal)
+		return ast.NewLiteral(pos, tok.String(), tok.Literal)
 	case token.KEYWORD:
 		if tok.Literal == "true" || tok.Literal == "false" {
 			p.stream.Next()
 			return ast.NewLiteral(pos, "BOOL", tok.Literal)
 		}
+		if tok.Literal == "match" {
+			return p.parseMatch()
+		}
+		if tok.Literal == "if" {
+			return p.parseIf()
+		}
+		if tok.Literal == "while" || tok.Literal == "loop" || tok.Literal == "for" {
+			return p.parseLoop()
+		}
+		if tok.Literal == "break" {
+			p.stream.Next()
+			label := p.parseLabel()
+			var value ast.Expr
+			if next := p.stream.Peek(); !isSyncToken(next) && next.Literal != "," {
+				value = p.ParseExpr()
+			}
+			brk := ast.NewBreakExpr(pos, value)
+			brk.Label = label
+			return brk
+		}
+		if tok.Literal == "continue" {
+			p.stream.Next()
+			cont := ast.NewContinueExpr(pos)
+			cont.Label = p.parseLabel()
+			return cont
+		}
+		if tok.Literal == "self" {
+			p.stream.Next()
+			return ast.NewLiteral(pos, "IDENT", tok.Literal)
+		}
+		if tok.Literal == "move" && isClosureStart(p.stream.PeekN(1)) {
+			p.stream.Next() // потребляем "move"
+			closure := p.parseClosure()
+			closure.Move = true
+			closure.SetPos(pos)
+			return closure
+		}
+	case token.LIFETIME:
+		// Метка цикла: `'outer: loop { ... }`
+		if next := p.stream.PeekN(1); next.Type == token.PUNCT && next.Literal == ":" {
+			if kw := p.stream.PeekN(2); kw.Type == token.KEYWORD && (kw.Literal == "loop" || kw.Literal == "while" || kw.Literal == "for") {
+				p.stream.Next() // потребляем метку
+				p.stream.Next() // потребляем ':'
+				return p.parseLabeledLoop(tok)
+			}
+		}
+	case token.OPERATOR:
+		if isClosureStart(tok) {
+			return p.parseClosure()
+		}
 	case token.IDENT:
 		idTok := p.stream.Next()
-		isMacro := false
+		name := idTok.Literal
+		isMacro := idTok.Subtype == "MACRO"
 		if p.stream.Peek().Literal == "!" {
+			// Пользовательский макрос: имя хранится вместе с '!', как у встроенных
 			isMacro = true
+			name += "!"
 			p.stream.Next() // потребляем '!'
 		}
 
+		// `A::B::C` — путь; после него возможен только вызов `A::B(...)`
+		if !isMacro && p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "::" {
+			path := p.parsePath(idTok)
+			if p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "(" {
+				p.stream.Next() // потребляем '('
+				return ast.NewCallExpr(idTok.Pos(), path, p.parseCallArgs())
+			}
+			return path
+		}
+
 		// Проверяем, идёт ли после идентификатора '(' — тогда это вызов
 		if p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "(" {
 			p.stream.Next() // потребляем '('
-			args := []ast.Expr{}
-
-			// Пустой список аргументов
-			if p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == ")" {
-				p.stream.Next()
-				fnLit := ast.NewLiteral(idTok.Pos(), "IDENT", idTok.Literal)
-				call := ast.NewCallExpr(idTok.Pos(), fnLit, args)
-				_ = isMacro // зарезервировано для будущей обработки макросов
-				return call
-			}
-
-			// Парсим аргументы
-			for {
-				arg := p.ParseExpr()
-				if arg != nil {
-					args = append(args, arg)
-				} else {
-					// Ошибка в аргументе: восстанавливаемся до ',' или ')'
-					for !p.stream.IsEOF() && !(p.stream.Peek().Literal == "," || p.stream.Peek().Literal == ")") {
-						p.stream.Next()
-					}
-					if p.stream.Peek().Literal == "," {
-						p.stream.Next()
-						continue
-					}
-				}
+			args := p.parseCallArgs()
+			fnLit := ast.NewLiteral(idTok.Pos(), "IDENT", name)
+			return ast.NewCallExpr(idTok.Pos(), fnLit, args)
+		}
 
-				if p.stream.Peek().Literal == "," {
-					p.stream.Next()
-					continue
-				}
-				break
+		// Макрос с квадратными скобками (`vec![1, 2]`, `vec![0; n]`) получает
+		// единственный аргумент — литерал массива
+		if isMacro && p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "[" {
+			array := p.parseArray()
+			if array == nil {
+				return nil
 			}
+			fnLit := ast.NewLiteral(idTok.Pos(), "IDENT", name)
+			return ast.NewCallExpr(idTok.Pos(), fnLit, []ast.Expr{array})
+		}
 
-			p.expect(token.PUNCT, ")", ")")
-			fnLit := ast.NewLiteral(idTok.Pos(), "IDENT", idTok.Literal)
-			call := ast.NewCallExpr(idTok.Pos(), fnLit, args)
-			_ = isMacro
-			return call
+		// `Name {` — литерал структуры, если он допустим в этом контексте
+		if !p.noStructLit && p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "{" {
+			return p.parseStructLit(idTok)
 		}
 
 		// Иначе — просто переменная или путь
@@ -256,23 +801,434 @@ func (p *Parser) parsePrimary() ast.Expr {
 			block := p.ParseBlock()
 			return ast.NewBlockExpr(pos, block)
 		}
+		if tok.Literal == "[" {
+			return p.parseArray()
+		}
 		if tok.Literal == "(" {
 			p.stream.Next()
 			inner := p.ParseExpr()
+			if inner != nil && p.stream.Peek().Literal == "," {
+				// Запятая после первого элемента означает кортеж: (a, b, ...)
+				elems := []ast.Expr{inner}
+				for p.listSeparator(")") {
+					elem := p.ParseExpr()
+					if elem == nil {
+						break
+					}
+					elems = append(elems, elem)
+				}
+				p.expect(token.PUNCT, ")", ")")
+				return ast.NewTupleExpr(pos, elems)
+			}
 			p.expect(token.PUNCT, ")", ")")
 			return inner
 		}
 	}
 
 	p.error("expected primary expression", tok)
-	p.stream.Next() // ВАЖНО: потребляем токен, вызвавший ошибку
+	// Потребляем токен, вызвавший ошибку, чтобы избежать зацикливания. Закрывающие
+	// скобки и ';' остаются в потоке как точки синхронизации для вызывающих правил
+	if !isSyncToken(tok) {
+		p.stream.Next()
+	}
 	return nil
 }
 
+// isClosureStart сообщает, начинает ли токен замыкание: `|` или `||` (без параметров).
+func isClosureStart(tok token.Token) bool {
+	return tok.Type == token.OPERATOR && (tok.Literal == "|" || tok.Literal == "||")
+}
+
+// parseClosure парсит замыкание, начиная с `|` или `||`.
+// Грамматика: ClosureExpr ::= "|" [["mut"] IDENTIFIER [":" Type] ("," ...)*] "|" ("->" Type Block | Expr)
+// При объявленном возвращаемом типе тело обязано быть блоком, как в Rust.
+func (p *Parser) parseClosure() *ast.ClosureExpr {
+	open := p.stream.Next() // потребляем "|" или "||"
+	params := []ast.Param{}
+	if open.Literal == "|" {
+		last := -1
+		for !p.stream.IsEOF() && p.stream.Peek().Literal != "|" && !p.stalled(&last) {
+			mutable := p.acceptMut()
+			nameTok := p.expect(token.IDENT, "", "closure parameter name")
+			if nameTok.Type != token.IDENT {
+				break
+			}
+			param := ast.NewParam(nameTok.Pos(), nameTok.Literal, nil)
+			param.Mutable = mutable
+			if p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == ":" {
+				p.stream.Next()
+				param.Type = p.ParseType()
+			}
+			params = append(params, *param)
+			if !p.listSeparator("|") {
+				break
+			}
+		}
+		p.expect(token.OPERATOR, "|", "'|' after closure parameters")
+	}
+
+	var retType ast.Type
+	var body ast.Expr
+	if p.stream.Peek().Literal == "->" {
+		p.stream.Next()
+		retType = p.ParseType()
+		blockPos := p.stream.Peek().Pos()
+		if p.stream.Peek().Literal != "{" {
+			p.error("expected block after closure return type", p.stream.Peek())
+			return ast.NewClosureExpr(open.Pos(), params, retType, nil)
+		}
+		body = ast.NewBlockExpr(blockPos, p.ParseBlock())
+	} else {
+		body = p.ParseExpr()
+	}
+	return ast.NewClosureExpr(open.Pos(), params, retType, body)
+}
+
+// parsePath парсит путь, первый сегмент которого first уже потреблён.
+// Грамматика: PathExpr ::= IDENTIFIER ("::" IDENTIFIER)+
+func (p *Parser) parsePath(first token.Token) *ast.PathExpr {
+	segments := []string{first.Literal}
+	for p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "::" {
+		p.stream.Next() // потребляем "::"
+		segTok := p.expect(token.IDENT, "", "identifier after ::")
+		if segTok.Type != token.IDENT {
+			break
+		}
+		segments = append(segments, segTok.Literal)
+	}
+	return ast.NewPathExpr(first.Pos(), segments)
+}
+
+// parseArray парсит литерал массива `[a, b, c]` или повторение `[value; len]`.
+func (p *Parser) parseArray() ast.Expr {
+	pos := p.stream.Next().Pos() // потребляем '['
+	if p.stream.Peek().Literal == "]" {
+		p.stream.Next()
+		return ast.NewArrayExpr(pos, []ast.Expr{})
+	}
+	first := p.ParseExpr()
+	if first == nil {
+		return nil
+	}
+	if p.stream.Peek().Literal == ";" {
+		p.stream.Next()
+		length := p.ParseExpr()
+		p.expect(token.PUNCT, "]", "]")
+		return ast.NewArrayRepeatExpr(pos, first, length)
+	}
+	elems := []ast.Expr{first}
+	for p.listSeparator("]") {
+		elem := p.ParseExpr()
+		if elem == nil {
+			break
+		}
+		elems = append(elems, elem)
+	}
+	p.expect(token.PUNCT, "]", "]")
+	return ast.NewArrayExpr(pos, elems)
+}
+
+// parseIf парсит условное выражение, начиная с ключевого слова "if".
+// Грамматика: IfExpr ::= "if" Expr Block ["else" (Block | IfExpr)]
+func (p *Parser) parseIf() ast.Expr {
+	ifTok := p.stream.Next() // потребляем "if"
+	// `{` после условия открывает блок, а не литерал структуры
+	saved := p.noStructLit
+	p.noStructLit = true
+	cond := p.ParseExpr()
+	p.noStructLit = saved
+	if cond == nil {
+		return nil
+	}
+	then := p.ParseBlock()
+
+	var els ast.Expr
+	if tok := p.stream.Peek(); tok.Type == token.KEYWORD && tok.Literal == "else" {
+		p.stream.Next() // потребляем "else"
+		if next := p.stream.Peek(); next.Type == token.KEYWORD && next.Literal == "if" {
+			els = p.parseIf()
+		} else {
+			els = ast.NewBlockExpr(next.Pos(), p.ParseBlock())
+		}
+	}
+	return ast.NewIfExpr(ifTok.Pos(), cond, then, els)
+}
+
+// parseLoop парсит цикл, начиная с ключевого слова "while", "loop" или "for".
+// Грамматика:
+//
+//	WhileExpr ::= "while" Expr Block
+//	LoopExpr  ::= "loop" Block
+//	ForExpr   ::= "for" IDENTIFIER "in" Expr Block
+func (p *Parser) parseLoop() ast.Expr {
+	kwTok := p.stream.Next() // потребляем ключевое слово цикла
+	if kwTok.Literal == "loop" {
+		return ast.NewLoopExpr(kwTok.Pos(), p.ParseBlock())
+	}
+
+	var varTok token.Token
+	if kwTok.Literal == "for" {
+		varTok = p.expect(token.IDENT, "", "loop variable")
+		if p.expect(token.KEYWORD, "in", "in").Literal != "in" {
+			return nil
+		}
+	}
+	// `{` после условия или обходимого выражения открывает тело цикла
+	saved := p.noStructLit
+	p.noStructLit = true
+	head := p.ParseExpr()
+	p.noStructLit = saved
+	if head == nil {
+		return nil
+	}
+	body := p.ParseBlock()
+	if kwTok.Literal == "for" {
+		return ast.NewForExpr(kwTok.Pos(), varTok.Literal, head, body)
+	}
+	return ast.NewWhileExpr(kwTok.Pos(), head, body)
+}
+
+// parseLabeledLoop парсит цикл после метки label и записывает её в узел цикла.
+func (p *Parser) parseLabeledLoop(label token.Token) ast.Expr {
+	loop := p.parseLoop()
+	switch l := loop.(type) {
+	case *ast.LoopExpr:
+		l.Label = label.Literal
+	case *ast.WhileExpr:
+		l.Label = label.Literal
+	case *ast.ForExpr:
+		l.Label = label.Literal
+	}
+	if loop != nil {
+		loop.SetPos(label.Pos())
+	}
+	return loop
+}
+
+// parseLabel потребляет необязательную метку после break или continue
+// и возвращает её или "".
+func (p *Parser) parseLabel() string {
+	if p.stream.Peek().Type != token.LIFETIME {
+		return ""
+	}
+	return p.stream.Next().Literal
+}
+
+// parseStructLit парсит литерал структуры после её имени nameTok.
+// Грамматика: StructLit ::= IDENTIFIER "{" (IDENTIFIER [":" Expr] ","?)* "}"
+func (p *Parser) parseStructLit(nameTok token.Token) ast.Expr {
+	p.expect(token.PUNCT, "{", "{")
+	// Внутри фигурных скобок литералы структур снова допустимы
+	saved := p.noStructLit
+	p.noStructLit = false
+	defer func() { p.noStructLit = saved }()
+
+	fields := []ast.FieldInit{}
+	last := -1
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != "}" {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
+		fieldTok := p.expect(token.IDENT, "", "field name")
+		var value ast.Expr
+		if p.stream.Peek().Literal == ":" {
+			p.stream.Next()
+			value = p.ParseExpr()
+		} else {
+			// Сокращённая запись: `Point { x }` эквивалентно `Point { x: x }`
+			value = ast.NewLiteral(fieldTok.Pos(), "IDENT", fieldTok.Literal)
+		}
+		fields = append(fields, ast.FieldInit{Name: fieldTok.Literal, Value: value})
+		if p.stream.Peek().Literal != "," {
+			break
+		}
+		p.stream.Next()
+	}
+	p.expect(token.PUNCT, "}", "}")
+	return ast.NewStructLit(nameTok.Pos(), nameTok.Literal, fields)
+}
+
+// parseMatch парсит выражение сопоставления с образцом.
+// Грамматика: MatchExpr ::= "match" Expr "{" (Pattern "=>" Expr ","?)* "}"
+// Запятая после ветви обязательна, если за ней следует другая ветвь и тело ветви — не блок.
+func (p *Parser) parseMatch() ast.Expr {
+	matchTok := p.stream.Next() // потребляем "match"
+	// `{` после сопоставляемого выражения открывает ветви, а не литерал структуры
+	saved := p.noStructLit
+	p.noStructLit = true
+	scrutinee := p.ParseExpr()
+	p.noStructLit = saved
+	if scrutinee == nil {
+		return nil
+	}
+	p.expect(token.PUNCT, "{", "{")
+	arms := []ast.MatchArm{}
+	last := -1
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != "}" {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
+		pattern := p.parsePattern()
+		var body ast.Expr
+		if pattern != nil && p.expect(token.OPERATOR, "=>", "=>").Literal == "=>" {
+			body = p.ParseExpr()
+		}
+		if body == nil {
+			// Ошибка в ветви: пропускаем токены до следующей ветви или конца match
+			for !p.stream.IsEOF() && p.stream.Peek().Literal != "," && p.stream.Peek().Literal != "}" {
+				p.stream.Next()
+			}
+		} else {
+			arms = append(arms, *ast.NewMatchArm(pattern.Pos(), pattern, body))
+		}
+		if p.stream.Peek().Literal == "," {
+			p.stream.Next()
+			continue
+		}
+		if _, isBlock := body.(*ast.BlockExpr); isBlock {
+			continue
+		}
+		break
+	}
+	p.expect(token.PUNCT, "}", "}")
+	return ast.NewMatchExpr(matchTok.Pos(), scrutinee, arms)
+}
+
+// parsePattern парсит образец ветви match.
+// Грамматика: Pattern ::= "_" | IDENTIFIER | Literal | "(" Pattern ("," Pattern)* ")" | VariantPattern | StructPattern
+// В случае ошибки регистрирует её и возвращает nil.
+func (p *Parser) parsePattern() ast.Pattern {
+	tok := p.stream.Peek()
+	pos := tok.Pos()
+	switch {
+	case tok.IsWildcard():
+		p.stream.Next()
+		return ast.NewWildcardPattern(pos)
+	case tok.Type == token.IDENT && p.stream.PeekN(1).Literal == "::":
+		return p.parseVariantPattern()
+	case tok.Type == token.IDENT && p.stream.PeekN(1).Literal == "{":
+		return p.parseStructPattern()
+	case tok.Type == token.IDENT:
+		p.stream.Next()
+		return ast.NewIdentPattern(pos, tok.Literal)
+	case tok.Type == token.INT, tok.Type == token.FLOAT, tok.Type == token.STRING, tok.Type == token.CHAR:
+		p.stream.Next()
+		return ast.NewLiteralPattern(pos, tok.String(), tok.Literal)
+	case tok.Type == token.KEYWORD && (tok.Literal == "true" || tok.Literal == "false"):
+		p.stream.Next()
+		return ast.NewLiteralPattern(pos, "BOOL", tok.Literal)
+	case tok.Type == token.OPERATOR && tok.Literal == "-":
+		// Отрицательный числовой литерал: -1
+		p.stream.Next()
+		numTok := p.stream.Peek()
+		if numTok.Type != token.INT && numTok.Type != token.FLOAT {
+			p.error("expected number after '-' in pattern", numTok)
+			return nil
+		}
+		p.stream.Next()
+		return ast.NewLiteralPattern(pos, numTok.String(), "-"+numTok.Literal)
+	case tok.Type == token.PUNCT && tok.Literal == "(":
+		p.stream.Next()
+		elems := []ast.Pattern{}
+		for !p.stream.IsEOF() && p.stream.Peek().Literal != ")" {
+			elem := p.parsePattern()
+			if elem == nil {
+				return nil
+			}
+			elems = append(elems, elem)
+			if p.stream.Peek().Literal == "," {
+				p.stream.Next()
+				continue
+			}
+			break
+		}
+		p.expect(token.PUNCT, ")", ")")
+		return ast.NewTuplePattern(pos, elems)
+	}
+	p.error("expected pattern", tok)
+	return nil
+}
+
+// parseStructPattern парсит образец структуры.
+// Грамматика: StructPattern ::= IDENTIFIER "{" (IDENTIFIER [":" Pattern] ",")* [".."] "}"
+func (p *Parser) parseStructPattern() ast.Pattern {
+	nameTok := p.stream.Next()
+	p.expect(token.PUNCT, "{", "{")
+	fields := []ast.FieldPattern{}
+	rest := false
+	last := -1
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != "}" {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
+		if p.stream.Peek().Literal == ".." {
+			p.stream.Next()
+			rest = true
+			break
+		}
+		fieldTok := p.expect(token.IDENT, "", "field name")
+		var pattern ast.Pattern = ast.NewIdentPattern(fieldTok.Pos(), fieldTok.Literal)
+		if p.stream.Peek().Literal == ":" {
+			p.stream.Next()
+			if pattern = p.parsePattern(); pattern == nil {
+				return nil
+			}
+		}
+		fields = append(fields, ast.FieldPattern{Name: fieldTok.Literal, Pattern: pattern})
+		if !p.listSeparator("}") {
+			break
+		}
+	}
+	p.expect(token.PUNCT, "}", "}")
+	return ast.NewStructPattern(nameTok.Pos(), nameTok.Literal, fields, rest)
+}
+
+// parseVariantPattern парсит образец варианта перечисления.
+// Грамматика: VariantPattern ::= IDENTIFIER ("::" IDENTIFIER)+ [ "(" Pattern ("," Pattern)* [","] ")" ]
+func (p *Parser) parseVariantPattern() ast.Pattern {
+	first := p.stream.Next()
+	segments := []string{first.Literal}
+	for p.stream.Peek().Type == token.PUNCT && p.stream.Peek().Literal == "::" {
+		p.stream.Next() // потребляем "::"
+		segments = append(segments, p.expect(token.IDENT, "", "identifier after ::").Literal)
+	}
+	if p.stream.Peek().Literal != "(" {
+		return ast.NewVariantPattern(first.Pos(), segments, nil)
+	}
+	p.stream.Next()
+	elems := []ast.Pattern{}
+	for !p.stream.IsEOF() && p.stream.Peek().Literal != ")" {
+		elem := p.parsePattern()
+		if elem == nil {
+			return nil
+		}
+		elems = append(elems, elem)
+		if !p.listSeparator(")") {
+			break
+		}
+	}
+	p.expect(token.PUNCT, ")", ")")
+	return ast.NewVariantPattern(first.Pos(), segments, elems)
+}
+
+// isBlockLike сообщает, оканчивается ли выражение блоком (`match`, `if`, циклы, `{ ... }`).
+// Такие выражения могут использоваться как операторы без завершающей ';'.
+func isBlockLike(expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.MatchExpr, *ast.BlockExpr, *ast.IfExpr, *ast.WhileExpr, *ast.LoopExpr, *ast.ForExpr:
+		return true
+	}
+	return false
+}
+
 // ParseStmt парсит оператор (statement).
 // Поддерживает:
-//   - объявления переменных: `let x: i32 = 42;`
+//   - объявления переменных: `let x: i32 = 42;`, в том числе с деструктуризацией: `let (a, b) = pair;`
 //   - выражения с точкой с запятой: `foo();`
+//   - вложенные функции: `fn helper() { ... }`
 //   - tail-выражения в блоках (без ';').
 //
 // В случае синтаксической ошибки возвращает nil и полагается на восстановление в вызывающем коде.
@@ -280,7 +1236,11 @@ func (p *Parser) ParseStmt() ast.Stmt {
 	tok := p.stream.Peek()
 	if tok.Literal == "let" {
 		p.stream.Next()
-		nameTok := p.expect(token.IDENT, "", "let binding name")
+		mutable := p.acceptMut()
+		pattern := p.parsePattern()
+		if pattern == nil {
+			return nil
+		}
 		var typ ast.Type
 		if p.stream.Peek().Literal == ":" {
 			p.stream.Next()
@@ -300,7 +1260,19 @@ func (p *Parser) ParseStmt() ast.Stmt {
 		if typ == nil {
 			typ = ast.NewPathType(token.Position{}, "infer") // тип будет выведен позже
 		}
-		return ast.NewLetStmt(tok.Pos(), nameTok.Literal, typ, init)
+		let := ast.NewLetStmt(tok.Pos(), "", typ, init)
+		if ident, ok := pattern.(*ast.IdentPattern); ok {
+			let.Name = ident.Name
+		} else {
+			let.Pattern = pattern
+		}
+		let.Mutable = mutable
+		return let
+	}
+
+	// Вложенная функция: `fn helper() { ... }`
+	if tok.Type == token.KEYWORD && tok.Literal == "fn" {
+		return ast.NewFnStmt(tok.Pos(), p.parseFunction())
 	}
 
 	expr := p.ParseExpr()
@@ -308,10 +1280,29 @@ func (p *Parser) ParseStmt() ast.Stmt {
 		return nil
 	}
 
+	// Присваивание: `target = value;`
+	if next := p.stream.Peek(); next.Type == token.OPERATOR && next.Literal == "=" {
+		if !isAssignable(expr) {
+			p.error("invalid left-hand side of assignment", next)
+			return nil
+		}
+		p.stream.Next() // потребляем '='
+		value := p.ParseExpr()
+		if value == nil {
+			return nil
+		}
+		if p.expect(token.TERMINATOR, ";", ";").Type == token.EOF {
+			return nil
+		}
+		return ast.NewAssignStmt(expr.Pos(), expr, value)
+	}
+
 	// Выражение с точкой с запятой
 	if p.stream.Peek().Type == token.TERMINATOR {
 		p.stream.Next()
-		return ast.NewExprStmt(expr.Pos(), expr)
+		stmt := ast.NewExprStmt(expr.Pos(), expr)
+		stmt.Semi = true
+		return stmt
 	}
 
 	// Tail-выражение в блоке (например, последнее выражение функции)
@@ -319,44 +1310,156 @@ func (p *Parser) ParseStmt() ast.Stmt {
 		return ast.NewExprStmt(expr.Pos(), expr)
 	}
 
+	// Выражения, оканчивающиеся блоком, не требуют ';'
+	if isBlockLike(expr) {
+		return ast.NewExprStmt(expr.Pos(), expr)
+	}
+
 	// Нет ни ';', ни '}' — ошибка
 	p.error("expected ';' after expression", p.stream.Peek())
 	return nil
 }
 
+// acceptMut потребляет необязательное ключевое слово "mut" и сообщает, было ли оно.
+func (p *Parser) acceptMut() bool {
+	if tok := p.stream.Peek(); tok.Type == token.KEYWORD && tok.Literal == "mut" {
+		p.stream.Next()
+		return true
+	}
+	return false
+}
+
+// acceptPub потребляет необязательный модификатор видимости и сообщает, был ли он.
+// Ограниченная видимость (`pub(crate)`, `pub(super)`) считается публичной.
+func (p *Parser) acceptPub() bool {
+	if tok := p.stream.Peek(); tok.Type != token.KEYWORD || tok.Literal != "pub" {
+		return false
+	}
+	p.stream.Next()
+	if tok := p.stream.Peek(); tok.Type == token.PUNCT && tok.Literal == "(" {
+		p.stream.Next()
+		for !p.stream.IsEOF() && p.stream.Peek().Literal != ")" {
+			p.stream.Next()
+		}
+		p.expect(token.PUNCT, ")", ")")
+	}
+	return true
+}
+
+// isAssignable сообщает, может ли выражение стоять в левой части присваивания:
+// идентификатор, доступ к полю, индексирование или разыменование.
+func isAssignable(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Literal:
+		return e.Kind == "IDENT"
+	case *ast.FieldExpr, *ast.IndexExpr:
+		return true
+	case *ast.UnaryExpr:
+		// Присваивание через ссылку: `*p = value`
+		return e.Op == "*"
+	}
+	return false
+}
+
 // ParseBlock парсит блок кода, ограниченный фигурными скобками.
 // Грамматика: Block ::= "{" Stmt* "}"
-// При ошибке в одном из операторов вызывает метод восстановления `recover`,
+// При ошибке в одном из операторов вызывает метод восстановления `synchronize`,
 // чтобы продолжить парсинг последующих операторов.
 func (p *Parser) ParseBlock() *ast.Block {
 	pos := p.stream.Pos()
-	p.expect(token.PUNCT, "{", "{")
+	open := p.expect(token.PUNCT, "{", "{")
 	stmts := []ast.Stmt{}
 
+	last := -1
 	for !p.stream.IsEOF() && p.stream.Peek().Literal != "}" {
+		if p.stalled(&last) {
+			p.stream.Next()
+			continue
+		}
 		stmt := p.ParseStmt()
 		if stmt != nil {
 			stmts = append(stmts, stmt)
 		} else {
-			// Ошибка в операторе — восстанавливаемся до точки с запятой
-			p.recover(";")
+			// Ошибка в операторе — пропускаем его остаток до границы оператора
+			p.synchronize()
 		}
 	}
+	// Без парной `}` ошибка на EOF бесполезна: указываем, где блок открыт
+	if p.stream.IsEOF() && open.Literal == "{" {
+		p.error(fmt.Sprintf("unclosed block opened at line %d:%d", open.Line, open.Col), open)
+		return ast.NewBlock(pos, stmts)
+	}
 	p.expect(token.PUNCT, "}", "}")
 	return ast.NewBlock(pos, stmts)
 }
 
-// ParseType парсит простой тип по имени (например, `i32`, `String`).
+// ParseType парсит тип по имени (например, `i32`, `String`) и обобщённые типы (`Vec<i32>`).
 // Поддерживает ссылки (`&T`), но без обработки lifetime'ов.
-// Грамматика: Type ::= Path | &Type | ...
+// Грамматика: Type ::= Path [ "<" Type ("," Type)* ">" ] | "(" Type ("," Type)* ")" | &Type | ...
 // В текущей реализации `&` просто игнорируется, и парсится базовый тип.
 func (p *Parser) ParseType() ast.Type {
 	if p.stream.Peek().Literal == "&" {
-		p.stream.Next() // потребляем '&'
-		// TODO: добавить поддержку lifetime'ов, например, &'a T
-		return p.ParseType()
+		pos := p.stream.Next().Pos() // потребляем '&'
+		// Lifetime ссылки (&'a T) в Go не нужен и отбрасывается
+		if p.stream.Peek().Type == token.LIFETIME {
+			p.stream.Next()
+		}
+		mutable := p.acceptMut()
+		return ast.NewRefType(pos, mutable, p.ParseType())
+	}
+	if p.stream.Peek().Literal == "[" {
+		// Массив [T; N] или срез [T]
+		pos := p.stream.Next().Pos()
+		elem := p.ParseType()
+		var length ast.Expr
+		if p.stream.Peek().Literal == ";" {
+			p.stream.Next()
+			length = p.ParseExpr()
+		}
+		p.expect(token.PUNCT, "]", "]")
+		return ast.NewArrayType(pos, elem, length)
+	}
+	if p.stream.Peek().Literal == "(" {
+		// Кортежный тип (T1, T2, ...) или unit-тип ()
+		pos := p.stream.Next().Pos()
+		elems := []ast.Type{}
+		for !p.stream.IsEOF() && p.stream.Peek().Literal != ")" {
+			elems = append(elems, p.ParseType())
+			if p.stream.Peek().Literal == "," {
+				p.stream.Next()
+				continue
+			}
+			break
+		}
+		p.expect(token.PUNCT, ")", ")")
+		if len(elems) == 0 {
+			return ast.NewPathType(pos, "()")
+		}
+		return ast.NewTupleType(pos, elems)
 	}
 	tok := p.expect(token.IDENT, "", "type")
+	if p.stream.Peek().Type == token.OPERATOR && p.stream.Peek().Literal == "<" {
+		p.stream.Next() // потребляем '<'
+		args := []ast.Type{}
+		for !p.stream.IsEOF() && p.stream.Peek().Literal != ">" {
+			// Lifetime-аргументы (Foo<'a, T>) отбрасываются
+			if p.stream.Peek().Type == token.LIFETIME {
+				p.stream.Next()
+				if p.stream.Peek().Literal == "," {
+					p.stream.Next()
+				}
+				continue
+			}
+			args = append(args, p.ParseType())
+			if p.stream.Peek().Literal == "," {
+				p.stream.Next()
+				continue
+			}
+			break
+		}
+		p.expect(token.OPERATOR, ">", ">")
+		return ast.NewGenericType(tok.Pos(), tok.Literal, args)
+	}
 	return ast.NewPathType(tok.Pos(), tok.Literal)
 }
 
@@ -370,6 +1473,28 @@ func (p *Parser) ParseField() *ast.Field {
 	return ast.NewField(nameTok.Pos(), nameTok.Literal, typ)
 }
 
+// listSeparator потребляет ',' между элементами списка и сообщает, следует ли за ней
+// очередной элемент. Одна завершающая запятая перед закрывающим close допускается:
+// `f(a, b,)`, `[1, 2,]`, `struct S { x: i32, }`.
+func (p *Parser) listSeparator(close string) bool {
+	if p.stream.Peek().Literal != "," {
+		return false
+	}
+	p.stream.Next()
+	return p.stream.Peek().Literal != close
+}
+
+// stalled сообщает, что цикл разбора не продвинулся с начала предыдущей итерации,
+// и запоминает в last текущую позицию потока. Застрявший цикл (ошибка о токене уже
+// сообщена, но восстановление его не потребило) пропускает токен или завершается,
+// иначе он повторялся бы бесконечно.
+func (p *Parser) stalled(last *int) bool {
+	offset := p.stream.Offset()
+	stuck := offset == *last
+	*last = offset
+	return stuck
+}
+
 // expect проверяет, что следующий токен соответствует ожидаемому типу и/или литералу.
 // Если нет — регистрирует ошибку и возвращает текущий токен.
 // Если да — потребляет токен и возвращает его.
@@ -16,8 +16,18 @@ import (
 type Parser struct {
 	stream TokenStream  // Поток токенов, полученный от лексического анализатора.
 	errors []ParseError // Список накопленных ошибок парсинга.
+
+	// noStructLit запрещает литералы структур там, где `{` начинает блок
+	// (например, в сопоставляемом выражении match).
+	noStructLit bool
+
+	depth    int // Текущая вложенность первичных выражений
+	maxDepth int // Предельная вложенность (см. SetMaxDepth)
 }
 
+// DefaultMaxDepth — предельная вложенность выражений по умолчанию.
+const DefaultMaxDepth = 500
+
 // ParseError представляет ошибку синтаксического анализа.
 // Содержит диагностическое сообщение, токен, вызвавший ошибку, и его позицию в исходном коде.
 type ParseError struct {
@@ -34,7 +44,14 @@ func (pe ParseError) String() string {
 // NewParser создаёт новый экземпляр парсера из списка токенов.
 // Токены должны быть получены от лексического анализатора (lexer).
 func NewParser(tokens []token.Token) *Parser {
-	return &Parser{stream: NewTokenStream(tokens)}
+	return &Parser{stream: NewTokenStream(tokens), maxDepth: DefaultMaxDepth}
+}
+
+// SetMaxDepth задаёт предельную вложенность выражений. Рекурсивный спуск по
+// выражению вроде `((((...))))` глубже предела сообщает об ошибке вместо
+// переполнения стека.
+func (p *Parser) SetMaxDepth(depth int) {
+	p.maxDepth = depth
 }
 
 // ParseFile запускает полный синтаксический анализ входного потока токенов.
@@ -51,32 +68,78 @@ func (p *Parser) error(msg string, tok token.Token) {
 	p.errors = append(p.errors, ParseError{Msg: msg, Tok: tok, Pos: tok.Pos()})
 }
 
-// recover реализует базовую стратегию восстановления после ошибки (error recovery).
-// Пропускает токены до тех пор, пока не встретит один из указанных синхронизирующих токенов
-// (например, ";", "}", или другие разделители), чтобы позволить парсеру продолжить работу.
-// Возвращает true, если восстановление было выполнено (в том числе при достижении EOF).
-// Если ошибок нет, восстановление не требуется и функция возвращает false.
-func (p *Parser) recover(syncs ...string) bool {
-	// Если ошибок нет — восстановление не нужно
-	if len(p.errors) == 0 {
+// statementKeywords — ключевые слова, с которых начинается новый оператор или элемент.
+// После ошибки синхронизация останавливается перед ними.
+var statementKeywords = map[string]bool{
+	"let": true, "return": true, "if": true, "while": true, "for": true,
+	"loop": true, "match": true, "fn": true, "struct": true, "impl": true,
+}
+
+// isSyncToken сообщает, является ли токен закрывающей скобкой или ';' —
+// точкой, на которой правила разбора синхронизируются после ошибки.
+func isSyncToken(tok token.Token) bool {
+	if tok.Type != token.PUNCT && tok.Type != token.TERMINATOR {
 		return false
 	}
+	switch tok.Literal {
+	case ";", ")", "]", "}":
+		return true
+	}
+	return false
+}
+
+// skipNested пропускает вложенное выражение, начинающееся с текущего токена,
+// вместе с его парными скобками. Останавливается перед закрывающей скобкой, ',' или ';'
+// объемлющего выражения, чтобы ожидающие их правила завершились без новых ошибок.
+func (p *Parser) skipNested() {
+	depth := 0
 	for !p.stream.IsEOF() {
-		tok := p.stream.Peek()
-		// Если текущий токен — один из заданных синхронизирующих литералов,
-		// останавливаемся и оставляем его в потоке для последующей обработки
-		for _, s := range syncs {
-			if tok.Literal == s {
-				return true
+		switch tok := p.stream.Peek(); tok.Literal {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth == 0 {
+				return
+			}
+			depth--
+		case ",", ";":
+			if depth == 0 {
+				return
 			}
 		}
-		// Если встретили явный конец оператора или блока — потребляем токен и завершаем восстановление
-		if tok.Type == token.TERMINATOR || (tok.Type == token.PUNCT && (tok.Literal == "}" || tok.Literal == ";")) {
-			p.stream.Next()
-			return true
+		p.stream.Next()
+	}
+}
+
+// synchronize реализует восстановление после ошибки (error recovery) на границах операторов:
+// пропускает остаток ошибочного оператора, потребляя завершающую его ';', и останавливается
+// перед '}' объемлющего блока или ключевым словом, начинающим новый оператор.
+// Скобки внутри пропускаемого участка учитываются, чтобы не остановиться на '}' или ';'
+// вложенного блока. Так ошибка в одном операторе не поглощает следующие за ним.
+func (p *Parser) synchronize() {
+	depth := 0
+	for !p.stream.IsEOF() {
+		tok := p.stream.Peek()
+		switch tok.Literal {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth == 0 && tok.Literal == "}" {
+				return
+			}
+			if depth > 0 {
+				depth--
+			}
+		case ";":
+			if depth == 0 {
+				p.stream.Next()
+				return
+			}
+		default:
+			if depth == 0 && tok.Type == token.KEYWORD && statementKeywords[tok.Literal] {
+				return
+			}
 		}
-		// Иначе пропускаем текущий токен и продолжаем поиск точки синхронизации
 		p.stream.Next()
 	}
-	return true
 }
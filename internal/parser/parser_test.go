@@ -4,7 +4,10 @@ package parser_test
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/semetekare/rust2go/internal/ast"
 	"github.com/semetekare/rust2go/internal/lexer"
@@ -105,6 +108,7 @@ func TestNegativeSyntax(t *testing.T) {
 		{"Missing Semicolon", "negative/missing_semi.rs", 1},
 		{"Missing Closing Parenthesis", "negative/missing_paren.rs", 1},
 		{"Bad Binary Operator", "negative/bad_operator.rs", 1},
+		{"Unclosed Block", "negative/unclosed_block.rs", 1},
 		// Остальные негативные тесты проверяются семантическим анализатором
 	}
 
@@ -127,3 +131,871 @@ func TestNegativeSyntax(t *testing.T) {
 		})
 	}
 }
+
+// parseSource токенизирует и парсит исходный код, переданный строкой.
+func parseSource(t *testing.T, code string) (*ast.Crate, []parser.ParseError) {
+	t.Helper()
+
+	lx := lexer.NewLexer()
+	toks, err := lx.Lex(code)
+	if err != nil {
+		t.Fatalf("Lexing failed: %v", err)
+	}
+
+	p := parser.NewParser(toks)
+	return p.ParseFile()
+}
+
+func TestParseMatchTuplePatterns(t *testing.T) {
+	code := `
+fn classify(point: (i32, i32)) -> i32 {
+    match point {
+        (0, 0) => 0,
+        (x, _) => x,
+    }
+}
+`
+	crate, errs := parseSource(t, code)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	fn := crate.Items[0].(*ast.Function)
+	if _, ok := fn.Params[0].Type.(*ast.TupleType); !ok {
+		t.Errorf("Expected tuple param type, got %T", fn.Params[0].Type)
+	}
+
+	stmt := fn.Body.Stmts[0].(*ast.ExprStmt)
+	match, ok := stmt.Expr.(*ast.MatchExpr)
+	if !ok {
+		t.Fatalf("Expected MatchExpr, got %T", stmt.Expr)
+	}
+	if len(match.Arms) != 2 {
+		t.Fatalf("Expected 2 arms, got %d", len(match.Arms))
+	}
+
+	origin, ok := match.Arms[0].Pattern.(*ast.TuplePattern)
+	if !ok || len(origin.Elems) != 2 {
+		t.Fatalf("Expected 2-element tuple pattern, got %s", match.Arms[0].Pattern)
+	}
+	if lit, ok := origin.Elems[0].(*ast.LiteralPattern); !ok || lit.Val != "0" {
+		t.Errorf("Expected literal 0 pattern, got %s", origin.Elems[0])
+	}
+
+	binding := match.Arms[1].Pattern.(*ast.TuplePattern)
+	if ident, ok := binding.Elems[0].(*ast.IdentPattern); !ok || ident.Name != "x" {
+		t.Errorf("Expected binding x, got %s", binding.Elems[0])
+	}
+	if _, ok := binding.Elems[1].(*ast.WildcardPattern); !ok {
+		t.Errorf("Expected wildcard, got %s", binding.Elems[1])
+	}
+}
+
+func TestParseMatchVariantPatterns(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn area(s: Shape) -> f64 {
+    match s {
+        Shape::Circle(r) => r,
+        Shape::Rect(w, _,) => w,
+        Shape::Empty => 0.0,
+    }
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+	match := crate.Items[0].(*ast.Function).Body.Stmts[0].(*ast.ExprStmt).Expr.(*ast.MatchExpr)
+	want := []struct {
+		path  string
+		elems int
+		paren bool
+	}{
+		{"Shape::Circle", 1, true},
+		{"Shape::Rect", 2, true},
+		{"Shape::Empty", 0, false},
+	}
+	for i, w := range want {
+		vp, ok := match.Arms[i].Pattern.(*ast.VariantPattern)
+		if !ok {
+			t.Fatalf("Expected variant pattern in arm %d, got %s", i, match.Arms[i].Pattern)
+		}
+		if got := strings.Join(vp.Segments, "::"); got != w.path || len(vp.Elems) != w.elems || (vp.Elems != nil) != w.paren {
+			t.Errorf("Arm %d: expected %s with %d fields, got %s with %d", i, w.path, w.elems, got, len(vp.Elems))
+		}
+	}
+}
+
+func TestParseStraySemicolonsBetweenItems(t *testing.T) {
+	crate, errs := parseSource(t, `; fn a() {}; fn b() {};; struct S { x: i32 };`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+	if len(crate.Items) != 3 {
+		t.Errorf("Expected 3 items, got %d", len(crate.Items))
+	}
+}
+
+func TestParseTupleExpr(t *testing.T) {
+	crate, errs := parseSource(t, `fn main() { let p = (1, 2); }`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	let := crate.Items[0].(*ast.Function).Body.Stmts[0].(*ast.LetStmt)
+	tuple, ok := let.Init.(*ast.TupleExpr)
+	if !ok || len(tuple.Elems) != 2 {
+		t.Errorf("Expected 2-element tuple, got %s", let.Init)
+	}
+}
+
+func TestParseTupleFieldAccess(t *testing.T) {
+	crate, errs := parseSource(t, `fn main() { let a = p.0; let b = p.0.1; }`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	first, ok := stmts[0].(*ast.LetStmt).Init.(*ast.FieldExpr)
+	if !ok || first.Field != "0" {
+		t.Errorf("Expected p.0, got %s", stmts[0].(*ast.LetStmt).Init)
+	}
+	// `p.0.1` лексируется как IDENT и FLOAT "0.1", но означает (p.0).1
+	outer, ok := stmts[1].(*ast.LetStmt).Init.(*ast.FieldExpr)
+	if !ok || outer.Field != "1" {
+		t.Fatalf("Expected (p.0).1, got %s", stmts[1].(*ast.LetStmt).Init)
+	}
+	if inner, ok := outer.Receiver.(*ast.FieldExpr); !ok || inner.Field != "0" {
+		t.Errorf("Expected p.0 receiver, got %s", outer.Receiver)
+	}
+}
+
+func TestParseMethodCallChain(t *testing.T) {
+	crate, errs := parseSource(t, `fn main() { let v = a.get(1).unwrap(); }`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	let := crate.Items[0].(*ast.Function).Body.Stmts[0].(*ast.LetStmt)
+	outer, ok := let.Init.(*ast.MethodCall)
+	if !ok || outer.Method != "unwrap" || len(outer.Args) != 0 {
+		t.Fatalf("Expected unwrap() call, got %s", let.Init)
+	}
+	inner, ok := outer.Receiver.(*ast.MethodCall)
+	if !ok || inner.Method != "get" || len(inner.Args) != 1 {
+		t.Fatalf("Expected get(1) receiver, got %s", outer.Receiver)
+	}
+}
+
+func TestParseAssignment(t *testing.T) {
+	crate, errs := parseSource(t, `fn main() { let mut v = a; v[0] = 1; p.x = 2; *r = *r + 1; }`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	if let := stmts[0].(*ast.LetStmt); !let.Mutable {
+		t.Errorf("Expected mutable let binding")
+	}
+	if assign, ok := stmts[1].(*ast.AssignStmt); !ok {
+		t.Errorf("Expected AssignStmt, got %s", stmts[1])
+	} else if _, ok := assign.Target.(*ast.IndexExpr); !ok {
+		t.Errorf("Expected index target, got %s", assign.Target)
+	}
+	if assign, ok := stmts[2].(*ast.AssignStmt); !ok {
+		t.Errorf("Expected AssignStmt, got %s", stmts[2])
+	} else if _, ok := assign.Target.(*ast.FieldExpr); !ok {
+		t.Errorf("Expected field target, got %s", assign.Target)
+	}
+	if assign, ok := stmts[3].(*ast.AssignStmt); !ok {
+		t.Errorf("Expected AssignStmt, got %s", stmts[3])
+	} else if deref, ok := assign.Target.(*ast.UnaryExpr); !ok || deref.Op != "*" {
+		t.Errorf("Expected dereference target, got %s", assign.Target)
+	} else if sum, ok := assign.Value.(*ast.BinaryExpr); !ok || sum.Op != "+" {
+		t.Errorf("Expected `*r + 1` value, got %s", assign.Value)
+	}
+}
+
+func TestParseInvalidAssignmentTarget(t *testing.T) {
+	_, errs := parseSource(t, `fn main() { 1 + 2 = 3; }`)
+	if len(errs) == 0 {
+		t.Fatal("Expected error for invalid assignment target")
+	}
+}
+
+func TestParseGenericParamsWithLifetime(t *testing.T) {
+	crate, errs := parseSource(t, `fn f<'a, T>(x: &'a T) -> &'a T { x }`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	fn := crate.Items[0].(*ast.Function)
+	if len(fn.TypeParams) != 1 || fn.TypeParams[0] != "T" {
+		t.Errorf("Expected type params [T], got %v", fn.TypeParams)
+	}
+	ref, ok := fn.Params[0].Type.(*ast.RefType)
+	if !ok {
+		t.Fatalf("Expected reference param type, got %s", fn.Params[0].Type)
+	}
+	if path, ok := ref.Elem.(*ast.PathType); !ok || path.Path != "T" {
+		t.Errorf("Expected &T with lifetime dropped, got %s", ref)
+	}
+}
+
+func TestParseTraitBounds(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn f<'a, T: Clone + Debug + 'a, U>(x: &'a T, u: U) {}
+fn g<T>() where T: Ord, Vec<T>: Into<String>, {}
+fn h<T>(x: T) -> T where T: Copy { x }
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	tests := []struct {
+		params []string
+		bounds map[string][]string
+	}{
+		{[]string{"T", "U"}, map[string][]string{"T": {"Clone", "Debug"}}},
+		{[]string{"T"}, map[string][]string{"T": {"Ord"}, "Vec<T>": {"Into<String>"}}},
+		{[]string{"T"}, map[string][]string{"T": {"Copy"}}},
+	}
+	for i, tt := range tests {
+		fn := crate.Items[i].(*ast.Function)
+		if !reflect.DeepEqual(fn.TypeParams, tt.params) {
+			t.Errorf("%s: expected type params %v, got %v", fn.Name, tt.params, fn.TypeParams)
+		}
+		if !reflect.DeepEqual(fn.Bounds, tt.bounds) {
+			t.Errorf("%s: expected bounds %v, got %v", fn.Name, tt.bounds, fn.Bounds)
+		}
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	crate, errs := parseSource(t, `
+pub struct P { pub x: i32, y: i32 }
+impl P { pub(crate) fn get(&self) -> i32 { self.x } fn hidden(&self) {} }
+pub fn f() {}
+fn g() {}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	st := crate.Items[0].(*ast.Struct)
+	if !st.Public || !st.Fields[0].Public || st.Fields[1].Public {
+		t.Errorf("Expected pub struct with pub x and private y, got %+v", st)
+	}
+	impl := crate.Items[1].(*ast.Impl)
+	if !impl.Methods[0].Public || impl.Methods[1].Public {
+		t.Errorf("Expected pub(crate) get and private hidden")
+	}
+	if !crate.Items[2].(*ast.Function).Public || crate.Items[3].(*ast.Function).Public {
+		t.Errorf("Expected pub f and private g")
+	}
+}
+
+func TestParseTupleAndUnitStructs(t *testing.T) {
+	crate, errs := parseSource(t, `
+struct Meters(f64);
+pub struct Pair(pub i32, String,);
+struct Marker;
+struct Point { x: i32 }
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	tests := []struct {
+		kind   ast.StructKind
+		fields []string
+	}{
+		{ast.StructTuple, []string{"0"}},
+		{ast.StructTuple, []string{"0", "1"}},
+		{ast.StructUnit, nil},
+		{ast.StructNamed, []string{"x"}},
+	}
+	for i, tt := range tests {
+		st := crate.Items[i].(*ast.Struct)
+		if st.Kind != tt.kind {
+			t.Errorf("%s: expected kind %d, got %d", st.Name, tt.kind, st.Kind)
+		}
+		var names []string
+		for _, f := range st.Fields {
+			names = append(names, f.Name)
+		}
+		if !reflect.DeepEqual(names, tt.fields) {
+			t.Errorf("%s: expected fields %v, got %v", st.Name, tt.fields, names)
+		}
+	}
+
+	meters := crate.Items[0].(*ast.Struct)
+	if typ, ok := meters.Fields[0].Type.(*ast.PathType); !ok || typ.Path != "f64" {
+		t.Errorf("Expected Meters.0 to be f64, got %s", meters.Fields[0].Type)
+	}
+	pair := crate.Items[1].(*ast.Struct)
+	if !pair.Public || !pair.Fields[0].Public || pair.Fields[1].Public {
+		t.Errorf("Expected pub Pair with pub field 0 and private field 1")
+	}
+}
+
+func TestParseTupleStructErrors(t *testing.T) {
+	for _, src := range []string{"struct Meters(f64)", "struct Marker", "struct Meters(f64 struct X;"} {
+		if _, errs := parseSource(t, src); len(errs) == 0 {
+			t.Errorf("Expected error for %q", src)
+		}
+	}
+}
+
+func TestParseStructLiteral(t *testing.T) {
+	crate, errs := parseSource(t, `fn main() { let p = Point { x: 1, y }; match p { _ => 0, }; }`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	let := crate.Items[0].(*ast.Function).Body.Stmts[0].(*ast.LetStmt)
+	lit, ok := let.Init.(*ast.StructLit)
+	if !ok {
+		t.Fatalf("Expected StructLit, got %T", let.Init)
+	}
+	if lit.Name != "Point" || len(lit.Fields) != 2 || lit.Fields[0].Name != "x" || lit.Fields[1].Name != "y" {
+		t.Errorf("Expected Point { x, y }, got %s", lit)
+	}
+	if y, ok := lit.Fields[1].Value.(*ast.Literal); !ok || y.Val != "y" {
+		t.Errorf("Expected shorthand field y to be initialized with y, got %v", lit.Fields[1].Value)
+	}
+}
+
+func TestParseEnum(t *testing.T) {
+	crate, errs := parseSource(t, `
+pub enum Shape {
+    Empty,
+    Circle(f64),
+    Rect { w: i32, h: i32 },
+}
+
+fn main() { let s = Shape::Circle(1.0); let e = Shape::Empty; }
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	en, ok := crate.Items[0].(*ast.Enum)
+	if !ok {
+		t.Fatalf("Expected Enum, got %T", crate.Items[0])
+	}
+	if en.Name != "Shape" || !en.Public || len(en.Variants) != 3 {
+		t.Fatalf("Expected pub enum Shape with 3 variants, got %s", en)
+	}
+	if v := en.Variants[1]; v.Name != "Circle" || len(v.Types) != 1 {
+		t.Errorf("Expected tuple variant Circle(f64), got %s with %d types", v.Name, len(v.Types))
+	}
+	if v := en.Variants[2]; v.Name != "Rect" || len(v.Fields) != 2 || v.Fields[1].Name != "h" {
+		t.Errorf("Expected struct variant Rect { w, h }, got %s with %d fields", v.Name, len(v.Fields))
+	}
+
+	stmts := crate.Items[1].(*ast.Function).Body.Stmts
+	call, ok := stmts[0].(*ast.LetStmt).Init.(*ast.CallExpr)
+	if !ok {
+		t.Fatalf("Expected CallExpr, got %T", stmts[0].(*ast.LetStmt).Init)
+	}
+	if path, ok := call.Func.(*ast.PathExpr); !ok || path.String() != "PathExpr{Shape::Circle}" {
+		t.Errorf("Expected call of Shape::Circle, got %v", call.Func)
+	}
+	if path, ok := stmts[1].(*ast.LetStmt).Init.(*ast.PathExpr); !ok || len(path.Segments) != 2 {
+		t.Errorf("Expected path Shape::Empty, got %v", stmts[1].(*ast.LetStmt).Init)
+	}
+}
+
+func TestParsePaths(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn main() {
+    let x = a::b::c;
+    let v = Vec::new();
+    let c = Color::Red;
+    let n = Vec::new().len();
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	init := func(i int) ast.Expr { return stmts[i].(*ast.LetStmt).Init }
+
+	if path, ok := init(0).(*ast.PathExpr); !ok || strings.Join(path.Segments, " ") != "a b c" {
+		t.Errorf("Expected path a::b::c, got %v", init(0))
+	}
+	call, ok := init(1).(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		t.Fatalf("Expected call without arguments, got %v", init(1))
+	}
+	if path, ok := call.Func.(*ast.PathExpr); !ok || path.String() != "PathExpr{Vec::new}" {
+		t.Errorf("Expected call of Vec::new, got %v", call.Func)
+	}
+	if path, ok := init(2).(*ast.PathExpr); !ok || path.String() != "PathExpr{Color::Red}" {
+		t.Errorf("Expected path Color::Red, got %v", init(2))
+	}
+	// Постфиксные операции применяются к вызову по пути
+	mc, ok := init(3).(*ast.MethodCall)
+	if !ok || mc.Method != "len" {
+		t.Fatalf("Expected method call len, got %v", init(3))
+	}
+	if _, ok := mc.Receiver.(*ast.CallExpr); !ok {
+		t.Errorf("Expected receiver Vec::new(), got %v", mc.Receiver)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	tighter := [][2]string{
+		{"*", "+"}, {"%", "-"}, {"+", "=="}, {"-", "<="}, {">", "&&"}, {"&&", "||"},
+	}
+	for _, pair := range tighter {
+		if parser.Precedence[pair[0]] <= parser.Precedence[pair[1]] {
+			t.Errorf("Expected %s to bind tighter than %s", pair[0], pair[1])
+		}
+	}
+	if parser.Precedence["<"] != parser.Precedence[">="] || parser.Precedence["*"] != parser.Precedence["/"] {
+		t.Errorf("Expected operators of one group to share a level: %v", parser.Precedence)
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"a + b * c", "(a + (b * c))"},
+		{"a * b + c", "((a * b) + c)"},
+		{"a - b - c", "((a - b) - c)"},
+		{"a < b + 1 && c || d", "(((a < (b + 1)) && c) || d)"},
+		{"a == b >= c", "((a == b) >= c)"},
+		{"-a * b", "((-a) * b)"},
+		{"-a as i64 * b", "(((-a) as Type{i64}) * b)"},
+		{"a + b as f64 as f32", "(a + ((b as Type{f64}) as Type{f32}))"},
+	}
+	for _, tt := range tests {
+		crate, errs := parseSource(t, "fn main() { let x = "+tt.expr+"; }")
+		if len(errs) > 0 {
+			t.Errorf("%s: unexpected errors %v", tt.expr, errs)
+			continue
+		}
+		init := crate.Items[0].(*ast.Function).Body.Stmts[0].(*ast.LetStmt).Init
+		if got := grouping(init); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+}
+
+// grouping печатает выражение со скобками вокруг каждой операции.
+func grouping(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.BinaryExpr:
+		return "(" + grouping(e.Left) + " " + e.Op + " " + grouping(e.Right) + ")"
+	case *ast.UnaryExpr:
+		return "(" + e.Op + grouping(e.Expr) + ")"
+	case *ast.CastExpr:
+		return "(" + grouping(e.Expr) + " as " + e.Type.String() + ")"
+	case *ast.Literal:
+		return e.Val
+	}
+	return e.String()
+}
+
+func TestParseLoops(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn main() {
+    while i < n { i = i + 1; continue; }
+    let v = loop { break 5; };
+    for x in xs { break }
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	if len(stmts) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(stmts))
+	}
+	while, ok := stmts[0].(*ast.ExprStmt).Expr.(*ast.WhileExpr)
+	if !ok || len(while.Body.Stmts) != 2 {
+		t.Fatalf("Expected while with 2 statements, got %v", stmts[0].(*ast.ExprStmt).Expr)
+	}
+	if _, ok := while.Body.Stmts[1].(*ast.ExprStmt).Expr.(*ast.ContinueExpr); !ok {
+		t.Errorf("Expected continue, got %v", while.Body.Stmts[1])
+	}
+	loop, ok := stmts[1].(*ast.LetStmt).Init.(*ast.LoopExpr)
+	if !ok {
+		t.Fatalf("Expected loop, got %v", stmts[1].(*ast.LetStmt).Init)
+	}
+	if brk, ok := loop.Body.Stmts[0].(*ast.ExprStmt).Expr.(*ast.BreakExpr); !ok || brk.Value == nil {
+		t.Errorf("Expected break with value, got %v", loop.Body.Stmts[0])
+	}
+	forExpr, ok := stmts[2].(*ast.ExprStmt).Expr.(*ast.ForExpr)
+	if !ok || forExpr.Var != "x" {
+		t.Fatalf("Expected for over x, got %v", stmts[2].(*ast.ExprStmt).Expr)
+	}
+	if brk, ok := forExpr.Body.Stmts[0].(*ast.ExprStmt).Expr.(*ast.BreakExpr); !ok || brk.Value != nil {
+		t.Errorf("Expected break without value, got %v", forExpr.Body.Stmts[0])
+	}
+}
+
+func TestParseRangeLoops(t *testing.T) {
+	crate, errs := parseSource(t, `fn main() { for i in 0..n + 1 { } for j in 1..=10 { } }`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	// `..` связывает слабее `+`: 0..(n + 1)
+	r, ok := stmts[0].(*ast.ExprStmt).Expr.(*ast.ForExpr).Iter.(*ast.RangeExpr)
+	if !ok || r.Inclusive {
+		t.Fatalf("Expected exclusive range, got %v", stmts[0])
+	}
+	if _, ok := r.End.(*ast.BinaryExpr); !ok {
+		t.Errorf("Expected n + 1 as range end, got %s", r.End)
+	}
+	r, ok = stmts[1].(*ast.ExprStmt).Expr.(*ast.ForExpr).Iter.(*ast.RangeExpr)
+	if !ok || !r.Inclusive {
+		t.Errorf("Expected inclusive range, got %v", stmts[1])
+	}
+}
+
+func TestParseLabeledLoops(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn main() {
+    'outer: loop {
+        'inner: for x in xs {
+            if x { continue 'outer; }
+            break 'inner;
+        }
+        break 'outer 5;
+    }
+    while go { break; }
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	outer, ok := stmts[0].(*ast.ExprStmt).Expr.(*ast.LoopExpr)
+	if !ok || outer.Label != "'outer" {
+		t.Fatalf("Expected loop labeled 'outer, got %v", stmts[0])
+	}
+	inner, ok := outer.Body.Stmts[0].(*ast.ExprStmt).Expr.(*ast.ForExpr)
+	if !ok || inner.Label != "'inner" || inner.Var != "x" {
+		t.Fatalf("Expected for loop labeled 'inner, got %v", outer.Body.Stmts[0])
+	}
+
+	labels := []string{}
+	ast.Walk(crate, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BreakExpr:
+			labels = append(labels, "break "+n.Label)
+			if n.Label == "'outer" && n.Value == nil {
+				t.Errorf("Expected break 'outer to keep its value")
+			}
+		case *ast.ContinueExpr:
+			labels = append(labels, "continue "+n.Label)
+		}
+		return true
+	})
+	want := "continue 'outer, break 'inner, break 'outer, break "
+	if got := strings.Join(labels, ", "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestParseLetDestructuring(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn main() {
+    let (a, (b, _)) = pair;
+    let Point { x, y: py, .. } = p;
+    let mut n: i32 = 0;
+    let _ = f();
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+
+	// bindings собирает имена, связываемые образцом, в порядке записи
+	bindings := func(pat ast.Pattern) string {
+		names := []string{}
+		ast.Walk(pat, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.IdentPattern); ok {
+				names = append(names, ident.Name)
+			}
+			return true
+		})
+		return strings.Join(names, " ")
+	}
+
+	tuple := stmts[0].(*ast.LetStmt)
+	if _, ok := tuple.Pattern.(*ast.TuplePattern); !ok || tuple.Name != "" {
+		t.Fatalf("Expected tuple pattern, got %v (name %q)", tuple.Pattern, tuple.Name)
+	}
+	if got := bindings(tuple.Pattern); got != "a b" {
+		t.Errorf("Expected tuple bindings %q, got %q", "a b", got)
+	}
+
+	st, ok := stmts[1].(*ast.LetStmt).Pattern.(*ast.StructPattern)
+	if !ok || st.Name != "Point" || len(st.Fields) != 2 || !st.Rest {
+		t.Fatalf("Expected struct pattern Point { x, y: py, .. }, got %v", stmts[1].(*ast.LetStmt).Pattern)
+	}
+	if st.Fields[0].Name != "x" || st.Fields[1].Name != "y" {
+		t.Errorf("Expected fields x and y, got %s and %s", st.Fields[0].Name, st.Fields[1].Name)
+	}
+	if got := bindings(st); got != "x py" {
+		t.Errorf("Expected struct bindings %q, got %q", "x py", got)
+	}
+
+	simple := stmts[2].(*ast.LetStmt)
+	if simple.Name != "n" || simple.Pattern != nil || !simple.Mutable {
+		t.Errorf("Expected plain mutable binding n, got %v", simple)
+	}
+	if _, ok := stmts[3].(*ast.LetStmt).Pattern.(*ast.WildcardPattern); !ok {
+		t.Errorf("Expected wildcard pattern, got %v", stmts[3].(*ast.LetStmt).Pattern)
+	}
+}
+
+func TestParseNestedFunctions(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn main() {
+    let x = helper(2);
+    fn helper(n: i32) -> i32 {
+        fn twice(m: i32) -> i32 { m * 2 }
+        twice(n)
+    }
+    x
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	if len(stmts) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(stmts))
+	}
+	fs, ok := stmts[1].(*ast.FnStmt)
+	if !ok || fs.Func.Name != "helper" || len(fs.Func.Params) != 1 || fs.Func.ReturnType == nil {
+		t.Fatalf("Expected nested fn helper(n: i32) -> i32, got %v", stmts[1])
+	}
+	inner, ok := fs.Func.Body.Stmts[0].(*ast.FnStmt)
+	if !ok || inner.Func.Name != "twice" {
+		t.Fatalf("Expected nested fn twice inside helper, got %v", fs.Func.Body.Stmts[0])
+	}
+	if tail, ok := stmts[2].(*ast.ExprStmt); !ok || tail.Semi {
+		t.Errorf("Expected tail expression after nested fn, got %v", stmts[2])
+	}
+}
+
+func TestParseClosures(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn main() {
+    let inc = |x| x + 1;
+    let zero = || 0;
+    let add = move |a: i32, b: i32| -> i32 { a + b };
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	closures := []*ast.ClosureExpr{}
+	for _, stmt := range crate.Items[0].(*ast.Function).Body.Stmts {
+		closure, ok := stmt.(*ast.LetStmt).Init.(*ast.ClosureExpr)
+		if !ok {
+			t.Fatalf("Expected closure, got %v", stmt.(*ast.LetStmt).Init)
+		}
+		closures = append(closures, closure)
+	}
+
+	inc := closures[0]
+	if len(inc.Params) != 1 || inc.Params[0].Name != "x" || inc.Params[0].Type != nil || inc.ReturnType != nil {
+		t.Errorf("Expected untyped parameter x, got %+v", inc.Params)
+	}
+	if body, ok := inc.Body.(*ast.BinaryExpr); !ok || body.Op != "+" {
+		t.Errorf("Expected body x + 1, got %v", inc.Body)
+	}
+
+	zero := closures[1]
+	if len(zero.Params) != 0 {
+		t.Errorf("Expected no parameters, got %+v", zero.Params)
+	}
+	if body, ok := zero.Body.(*ast.Literal); !ok || body.Val != "0" {
+		t.Errorf("Expected body 0, got %v", zero.Body)
+	}
+
+	add := closures[2]
+	if !add.Move || len(add.Params) != 2 || add.Params[1].Name != "b" {
+		t.Fatalf("Expected move closure with parameters a and b, got %+v", add)
+	}
+	if typ, ok := add.Params[0].Type.(*ast.PathType); !ok || typ.Path != "i32" {
+		t.Errorf("Expected parameter type i32, got %v", add.Params[0].Type)
+	}
+	if typ, ok := add.ReturnType.(*ast.PathType); !ok || typ.Path != "i32" {
+		t.Errorf("Expected return type i32, got %v", add.ReturnType)
+	}
+	if body, ok := add.Body.(*ast.BlockExpr); !ok || len(body.Block.Stmts) != 1 {
+		t.Errorf("Expected block body with one statement, got %v", add.Body)
+	}
+}
+
+func TestParseUnclosedBlock(t *testing.T) {
+	_, errs := runTestFile(t, "negative/unclosed_block.rs")
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errs), errs)
+	}
+	if want := "unclosed block opened at line 1:11"; errs[0].Msg != want {
+		t.Errorf("Expected %q, got %q", want, errs[0].Msg)
+	}
+	if errs[0].Pos.Line != 1 || errs[0].Pos.Col != 11 {
+		t.Errorf("Expected error at the opening brace 1:11, got %d:%d", errs[0].Pos.Line, errs[0].Pos.Col)
+	}
+}
+
+func TestParseMalformedInputTerminates(t *testing.T) {
+	inputs := []string{
+		"fn main() { foo(, , ,); }",
+		"fn main() { foo(1 2 3); }",
+		"fn main() { foo(1,; }",
+		"fn main() { foo(",
+		"fn main() { x.m(,,); v[,]; }",
+		"fn main() { let a = [1 2, ,]; }",
+		"fn main() { let p = P { x: , y }; }",
+		"fn main() { match x { , => 1 } }",
+		"fn f(, a: i32 b) {}",
+		"struct S { x i32 y: }",
+		"impl S { x }",
+		") ) fn main() {}",
+	}
+
+	for _, code := range inputs {
+		done := make(chan []parser.ParseError, 1)
+		go func() {
+			toks, err := lexer.NewLexer().Lex(code)
+			if err != nil {
+				done <- []parser.ParseError{{Msg: err.Error()}}
+				return
+			}
+			_, errs := parser.NewParser(toks).ParseFile()
+			done <- errs
+		}()
+
+		select {
+		case errs := <-done:
+			if len(errs) == 0 {
+				t.Errorf("Expected parse errors for %q", code)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Parser did not terminate on %q", code)
+		}
+	}
+}
+
+func TestParseTrailingCommas(t *testing.T) {
+	crate, errs := parseSource(t, `
+struct S {
+    x: i32,
+    y: i32,
+}
+
+fn f(a: i32, b: i32,) {
+    g(1, 2,);
+    s.m(1,);
+    let arr = [1, 2,];
+    let t = (1, 2,);
+}
+`)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 errors, got %v", errs)
+	}
+
+	if st := crate.Items[0].(*ast.Struct); len(st.Fields) != 2 {
+		t.Errorf("Expected 2 struct fields, got %d", len(st.Fields))
+	}
+	fn := crate.Items[1].(*ast.Function)
+	if len(fn.Params) != 2 {
+		t.Errorf("Expected 2 params, got %d", len(fn.Params))
+	}
+	stmts := fn.Body.Stmts
+	if call := stmts[0].(*ast.ExprStmt).Expr.(*ast.CallExpr); len(call.Args) != 2 {
+		t.Errorf("Expected 2 call arguments, got %d", len(call.Args))
+	}
+	if call := stmts[1].(*ast.ExprStmt).Expr.(*ast.MethodCall); len(call.Args) != 1 {
+		t.Errorf("Expected 1 method argument, got %d", len(call.Args))
+	}
+	if arr := stmts[2].(*ast.LetStmt).Init.(*ast.ArrayExpr); len(arr.Elems) != 2 {
+		t.Errorf("Expected 2 array elements, got %d", len(arr.Elems))
+	}
+	if tuple := stmts[3].(*ast.LetStmt).Init.(*ast.TupleExpr); len(tuple.Elems) != 2 {
+		t.Errorf("Expected 2 tuple elements, got %d", len(tuple.Elems))
+	}
+
+	// Допускается только одна завершающая запятая
+	for _, code := range []string{"fn f(a: i32,,) {}", "fn main() { g(1,,); }", "fn main() { let a = [1,,]; }"} {
+		if _, errs := parseSource(t, code); len(errs) == 0 {
+			t.Errorf("Expected errors for %q", code)
+		}
+	}
+}
+
+func TestParseRecoversAtStatementBoundaries(t *testing.T) {
+	crate, errs := parseSource(t, `
+fn main() {
+    let a: = 1;
+    let b = 2;
+    let c = 3 +;
+    let d = if b > 0 { 1 } else { 2 };
+}
+`)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Pos.Line != 3 || errs[1].Pos.Line != 5 {
+		t.Errorf("Expected errors on lines 3 and 5, got %d and %d", errs[0].Pos.Line, errs[1].Pos.Line)
+	}
+
+	// Операторы после ошибок не теряются
+	names := []string{}
+	for _, stmt := range crate.Items[0].(*ast.Function).Body.Stmts {
+		if let, ok := stmt.(*ast.LetStmt); ok {
+			names = append(names, let.Name)
+		}
+	}
+	if strings.Join(names, " ") != "a b d" {
+		t.Errorf("Expected let statements a, b and d to survive, got %v", names)
+	}
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	nested := func(n int) string {
+		return "fn main() { let x = " + strings.Repeat("(", n) + "1" + strings.Repeat(")", n) + "; let y = 2; }"
+	}
+
+	crate, errs := parseSource(t, nested(10000))
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errs), errs)
+	}
+	if want := "maximum expression depth exceeded"; errs[0].Msg != want {
+		t.Errorf("Expected %q, got %q", want, errs[0].Msg)
+	}
+	// Разбор продолжается после слишком глубокого выражения
+	stmts := crate.Items[0].(*ast.Function).Body.Stmts
+	if let, ok := stmts[len(stmts)-1].(*ast.LetStmt); !ok || let.Name != "y" {
+		t.Errorf("Expected let y to survive, got %v", stmts)
+	}
+
+	if _, errs := parseSource(t, nested(parser.DefaultMaxDepth-1)); len(errs) != 0 {
+		t.Errorf("Expected nesting below the default limit to parse, got %v", errs)
+	}
+
+	toks, err := lexer.NewLexer().Lex(nested(20))
+	if err != nil {
+		t.Fatalf("Lex failed: %v", err)
+	}
+	p := parser.NewParser(toks)
+	p.SetMaxDepth(10)
+	if _, errs := p.ParseFile(); len(errs) != 1 {
+		t.Errorf("Expected 1 error with a custom limit, got %d: %v", len(errs), errs)
+	}
+}
@@ -18,12 +18,20 @@ type TokenStream interface {
 	// При достижении конца возвращается токен типа token.EOF.
 	Peek() token.Token
 
+	// PeekN возвращает токен, находящийся на n позиций впереди курсора (PeekN(0) эквивалентен Peek).
+	// При выходе за конец потока возвращается токен типа token.EOF.
+	PeekN(n int) token.Token
+
 	// IsEOF возвращает true, если следующий токен — это конец файла (EOF).
 	IsEOF() bool
 
 	// Pos возвращает позицию следующего токена в исходном коде.
 	// Если достигнут конец потока, возвращается позиция токена EOF.
 	Pos() token.Position
+
+	// Offset возвращает индекс курсора в потоке. Позволяет циклам разбора
+	// проверить, что очередная итерация потребила хотя бы один токен.
+	Offset() int
 }
 
 // tokenStreamImpl — конкретная реализация интерфейса TokenStream,
@@ -60,6 +68,15 @@ func (ts *tokenStreamImpl) Peek() token.Token {
 	return ts.tokens[ts.pos]
 }
 
+// PeekN возвращает токен на n позиций впереди курсора без его изменения.
+// Если позиция выходит за пределы среза, возвращается токен EOF.
+func (ts *tokenStreamImpl) PeekN(n int) token.Token {
+	if ts.pos+n >= len(ts.tokens) {
+		return token.Token{Type: token.EOF}
+	}
+	return ts.tokens[ts.pos+n]
+}
+
 // IsEOF проверяет, достиг ли курсор конца потока токенов.
 // Возвращает true, если следующий токен — EOF.
 func (ts *tokenStreamImpl) IsEOF() bool {
@@ -71,3 +88,8 @@ func (ts *tokenStreamImpl) IsEOF() bool {
 func (ts *tokenStreamImpl) Pos() token.Position {
 	return ts.Peek().Pos()
 }
+
+// Offset возвращает текущую позицию курсора в срезе токенов.
+func (ts *tokenStreamImpl) Offset() int {
+	return ts.pos
+}
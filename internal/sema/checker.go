@@ -4,6 +4,8 @@ package sema
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/semetekare/rust2go/internal/ast"
 	"github.com/semetekare/rust2go/internal/token"
@@ -12,22 +14,63 @@ import (
 // Checker представляет семантический анализатор.
 // Содержит таблицы символов, информацию о типах и накопленные ошибки.
 type Checker struct {
+	// Binary — крейт собирается в исполняемую программу (пакет main),
+	// и в нём обязана быть функция main.
+	Binary bool
+
 	// Диагностические сообщения о семантических ошибках
 	errors []SemanticError
 
 	// Таблица символов: карта имён -> символы
 	symbols map[string]*Symbol
 
-	// Текущий контекст для отладки
+	// Методы типов из блоков impl: имя типа -> имя метода -> определение
+	methods map[string]map[string]*ast.Function
+
+	// Тип, которому соответствует Self внутри текущего блока impl
+	selfType string
+
+	// Обобщённые параметры типа проверяемой функции
+	typeParams map[string]bool
+
+	// Возвращаемый тип проверяемой функции (для оператора ?)
+	returnType TypeInfo
+
+	// Стек объемлющих циклов: глубина вложенности для break/continue
+	loops []*loopFrame
+
+	// Глубина вложенности блоков в теле текущей функции (0 — тело функции)
+	blockDepth int
+
+	// Проверяемый элемент верхнего уровня: по нему ошибки соотносятся с файлами
+	item ast.Item
+
+	// Текущий контекст для отладки: имя функции или метода (`Type::method`)
 	currentFunction string
+
+	// Объявленные символы для снимка Symbols
+	defs []SymbolInfo
+}
+
+// loopFrame описывает объемлющий цикл при проверке его тела.
+type loopFrame struct {
+	kind      string    // Ключевое слово цикла: "while", "loop" или "for"
+	label     string    // Метка цикла (`'outer`) или ""
+	breakType *TypeInfo // Тип значения `break value` (только для loop)
 }
 
 // SemanticError представляет семантическую ошибку (например, неопределённая переменная, несовпадение типов).
+// Code, Severity и Suggestion позволяют редакторам обрабатывать диагностики без разбора текста.
 type SemanticError struct {
-	Msg string         // Описание ошибки
-	Pos token.Position // Позиция в исходном коде
+	Msg        string         // Описание ошибки
+	Pos        token.Position // Позиция в исходном коде
+	Code       string         // Стабильный код диагностики, например E0425 (см. diagnostic.go)
+	Severity   Severity       // Важность: ошибка или предупреждение
+	Suggestion string         // Предлагаемое исправление; пусто, если его нет
+	Item       ast.Item       // Элемент верхнего уровня, в котором найдена ошибка; nil для ошибок крейта
 }
 
+// Error возвращает сообщение в прежнем формате, без кода и важности.
 func (e SemanticError) Error() string {
 	return fmt.Sprintf("Semantic error at %d:%d: %s", e.Pos.Line, e.Pos.Col, e.Msg)
 }
@@ -39,6 +82,7 @@ const (
 	SymbolVariable SymbolKind = iota
 	SymbolFunction
 	SymbolStruct
+	SymbolEnum
 )
 
 // Symbol представляет символ в таблице символов (переменная, функция, тип).
@@ -48,7 +92,12 @@ type Symbol struct {
 	Type     TypeInfo
 	Pos      token.Position
 	Defined  bool
+	Mutable  bool          // Для переменных: объявлена ли как `mut`
+	Depth    int           // Для переменных: глубина блока, в котором объявлена переменная
+	MutRef   bool          // Для переменных: значение — изменяемая ссылка (&mut T), через неё можно менять данные
 	Function *ast.Function // Для функций: указатель на определение
+	Struct   *ast.Struct   // Для структур: указатель на определение
+	Enum     *ast.Enum     // Для перечислений: указатель на определение
 }
 
 // TypeInfo представляет информацию о типе.
@@ -60,6 +109,17 @@ type TypeInfo struct {
 	IsArray bool
 	// IsReference — является ли тип ссылкой (&T)
 	IsReference bool
+	// Elems — типы элементов для кортежей (nil для остальных типов)
+	Elems []TypeInfo
+	// Args — аргументы обобщённого типа (например, i32 для Option<i32>)
+	Args []TypeInfo
+	// Elem — тип, на который указывает ссылка (для IsReference), или возвращаемый тип (для IsFunc)
+	Elem *TypeInfo
+	// IsFunc — является ли тип типом замыкания; типы параметров хранятся в Args
+	IsFunc bool
+	// IsTypeParam — является ли тип обобщённым параметром функции (T);
+	// внутри её тела такой тип совместим только с самим собой
+	IsTypeParam bool
 }
 
 // NewChecker создаёт новый семантический анализатор.
@@ -67,14 +127,16 @@ func NewChecker() *Checker {
 	return &Checker{
 		errors:  make([]SemanticError, 0),
 		symbols: make(map[string]*Symbol),
+		methods: make(map[string]map[string]*ast.Function),
 	}
 }
 
 // Check выполняет семантический анализ над AST.
 // Возвращает список обнаруженных семантических ошибок.
 func (c *Checker) Check(crate *ast.Crate) []SemanticError {
-	// Шаг 1: регистрируем все функции и структуры (декларации)
+	// Шаг 1: регистрируем все функции, структуры и перечисления (декларации)
 	c.checkCrateDeclarations(crate)
+	c.checkMain(crate)
 
 	// Шаг 2: проверяем тела функций (определения)
 	c.checkCrateDefinitions(crate)
@@ -82,72 +144,212 @@ func (c *Checker) Check(crate *ast.Crate) []SemanticError {
 	return c.errors
 }
 
-// checkCrateDeclarations регистрирует все top-level декларации (функции, структуры).
+// checkCrateDeclarations регистрирует все top-level декларации (функции, структуры, перечисления).
 func (c *Checker) checkCrateDeclarations(crate *ast.Crate) {
 	for _, item := range crate.Items {
+		c.item = item
 		switch it := item.(type) {
 		case *ast.Function:
 			c.registerFunction(it)
 		case *ast.Struct:
 			c.registerStruct(it)
+		case *ast.Enum:
+			c.registerEnum(it)
+		case *ast.Impl:
+			c.registerImpl(it)
 		}
 	}
+	c.item = nil
+}
+
+// checkMain проверяет точку входа программы: в Go main не принимает аргументов
+// и ничего не возвращает, поэтому допустимы только `fn main()` и, как в Rust,
+// `fn main() -> Result<...>`. Отсутствие main — ошибка только в режиме Binary:
+// крейт без main иначе транслируется в библиотечный пакет.
+func (c *Checker) checkMain(crate *ast.Crate) {
+	var main *ast.Function
+	for _, item := range crate.Items {
+		if fn, ok := item.(*ast.Function); ok && fn.Name == "main" {
+			main = fn
+			break
+		}
+	}
+	if main == nil {
+		if c.Binary {
+			c.error(CodeMainMissing, "`main` function not found in crate", crate.Pos())
+		}
+		return
+	}
+
+	c.item = main
+	if len(main.Params) > 0 {
+		c.error(CodeMainSignature, fmt.Sprintf("`main` function must take no arguments, got %d", len(main.Params)), main.Pos())
+	}
+	if ret := c.extractType(main.ReturnType); ret.Name != "()" && !strings.HasPrefix(ret.Name, "Result<") {
+		c.error(CodeMainSignature, fmt.Sprintf("`main` function must return () or Result, got %s", ret.Name), main.Pos())
+	}
+	c.item = nil
 }
 
 // registerFunction регистрирует функцию в таблице символов.
 func (c *Checker) registerFunction(fn *ast.Function) {
 	// Проверяем, не объявлена ли функция уже
 	if _, exists := c.symbols[fn.Name]; exists {
-		c.error(fmt.Sprintf("duplicate function declaration: %s", fn.Name), fn.Pos())
+		c.error(CodeDuplicateDefinition, fmt.Sprintf("duplicate function declaration: %s", fn.Name), fn.Pos())
 		return
 	}
 
 	// Определяем тип возвращаемого значения
-	retType := c.extractType(fn.ReturnType)
+	retType := c.genericResult(fn)
 
 	// Создаём символ функции
-	c.symbols[fn.Name] = &Symbol{
+	c.define(c.symbols, &Symbol{
 		Kind:     SymbolFunction,
 		Name:     fn.Name,
 		Type:     retType,
 		Pos:      fn.Pos(),
 		Defined:  true,
 		Function: fn,
-	}
+	})
 }
 
 // registerStruct регистрирует структуру в таблице символов.
 func (c *Checker) registerStruct(st *ast.Struct) {
 	if _, exists := c.symbols[st.Name]; exists {
-		c.error(fmt.Sprintf("duplicate struct declaration: %s", st.Name), st.Pos())
+		c.error(CodeDuplicateDefinition, fmt.Sprintf("duplicate struct declaration: %s", st.Name), st.Pos())
 		return
 	}
 
-	c.symbols[st.Name] = &Symbol{
+	c.checkDerives(st.Name, st.Attrs, st.Pos())
+	c.define(c.symbols, &Symbol{
 		Kind:    SymbolStruct,
 		Name:    st.Name,
 		Type:    TypeInfo{Name: st.Name},
 		Pos:     st.Pos(),
 		Defined: true,
+		Struct:  st,
+	})
+}
+
+// derivableTraits — трейты, для которых транслятор умеет генерировать реализацию в Go.
+// Copy и Eq не требуют кода: структуры Go копируются по значению.
+var derivableTraits = map[string]bool{
+	"Debug": true, "Clone": true, "Copy": true, "PartialEq": true, "Eq": true, "Default": true,
+}
+
+// checkDerives проверяет, что `#[derive(...)]` типа name перечисляет только поддерживаемые трейты.
+func (c *Checker) checkDerives(name string, attrs []string, pos token.Position) {
+	for _, trait := range ast.Derives(attrs) {
+		if !derivableTraits[trait] {
+			c.error(CodeUnknownDerive, fmt.Sprintf("cannot derive `%s` for `%s`: unsupported derive", trait, name), pos)
+		}
+	}
+}
+
+// registerEnum регистрирует перечисление в таблице символов.
+// Имена вариантов внутри перечисления должны быть уникальны.
+func (c *Checker) registerEnum(en *ast.Enum) {
+	if _, exists := c.symbols[en.Name]; exists {
+		c.error(CodeDuplicateDefinition, fmt.Sprintf("duplicate enum declaration: %s", en.Name), en.Pos())
+		return
+	}
+
+	seen := make(map[string]bool, len(en.Variants))
+	for _, v := range en.Variants {
+		if seen[v.Name] {
+			c.error(CodeDuplicateDefinition, fmt.Sprintf("duplicate variant declaration: %s::%s", en.Name, v.Name), v.Pos())
+		}
+		seen[v.Name] = true
+		// Транслятор понижает только C-подобные перечисления в константы Go
+		if len(v.Types) > 0 || len(v.Fields) > 0 {
+			c.error(CodeUnsupported, fmt.Sprintf("enum variants with data are not supported: %s::%s", en.Name, v.Name), v.Pos())
+		}
+	}
+
+	c.checkDerives(en.Name, en.Attrs, en.Pos())
+	c.define(c.symbols, &Symbol{
+		Kind:    SymbolEnum,
+		Name:    en.Name,
+		Type:    TypeInfo{Name: en.Name},
+		Pos:     en.Pos(),
+		Defined: true,
+		Enum:    en,
+	})
+}
+
+// registerImpl регистрирует методы блока impl для типа.
+func (c *Checker) registerImpl(impl *ast.Impl) {
+	if c.methods[impl.Type] == nil {
+		c.methods[impl.Type] = make(map[string]*ast.Function)
+	}
+	savedSelf := c.selfType
+	c.selfType = impl.Type
+	defer func() { c.selfType = savedSelf }()
+
+	for _, method := range impl.Methods {
+		if _, exists := c.methods[impl.Type][method.Name]; exists {
+			c.error(CodeDuplicateMethod, fmt.Sprintf("duplicate method declaration: %s::%s", impl.Type, method.Name), method.Pos())
+			continue
+		}
+		c.methods[impl.Type][method.Name] = method
+		c.record(&Symbol{
+			Kind: SymbolFunction,
+			Name: method.Name,
+			Type: c.extractType(method.ReturnType),
+			Pos:  method.Pos(),
+		}, impl.Type)
 	}
 }
 
 // checkCrateDefinitions проверяет тела функций на корректность.
 func (c *Checker) checkCrateDefinitions(crate *ast.Crate) {
 	for _, item := range crate.Items {
+		c.item = item
 		switch it := item.(type) {
 		case *ast.Function:
 			c.checkFunction(it)
+		case *ast.Impl:
+			c.selfType = it.Type
+			for _, method := range it.Methods {
+				c.checkFunction(method)
+			}
+			c.selfType = ""
 		}
 	}
+	c.item = nil
 }
 
 // checkFunction выполняет семантическую проверку функции.
 func (c *Checker) checkFunction(fn *ast.Function) {
+	c.checkFunctionIn(fn, make(map[string]*Symbol))
+}
+
+// checkFunctionIn проверяет функцию, начиная область видимости её тела с scope.
+func (c *Checker) checkFunctionIn(fn *ast.Function, scope map[string]*Symbol) {
 	c.currentFunction = fn.Name
+	if c.selfType != "" {
+		c.currentFunction = c.selfType + "::" + fn.Name
+	}
+	c.typeParams = typeParamSet(fn)
+	c.loops = nil
+	defer func() { c.typeParams = nil }()
+	c.returnType = c.extractType(fn.ReturnType)
 
 	// Создаём локальную область видимости для параметров
-	localScope := make(map[string]*Symbol)
+	localScope := scope
+
+	// Для методов регистрируем self
+	if fn.Receiver != nil {
+		c.define(localScope, &Symbol{
+			Kind:    SymbolVariable,
+			Name:    "self",
+			Type:    c.extractType(fn.Receiver.Type),
+			Pos:     fn.Receiver.Pos(),
+			Defined: true,
+			Mutable: fn.Receiver.Mutable || isMutRef(fn.Receiver.Type),
+			MutRef:  isMutRef(fn.Receiver.Type),
+		})
+	}
 
 	// Регистрируем параметры как локальные переменные
 	for _, param := range fn.Params {
@@ -156,13 +358,15 @@ func (c *Checker) checkFunction(fn *ast.Function) {
 		if paramType.Name == "str" {
 			paramType.Name = "String"
 		}
-		localScope[param.Name] = &Symbol{
+		c.define(localScope, &Symbol{
 			Kind:    SymbolVariable,
 			Name:    param.Name,
 			Type:    paramType,
 			Pos:     param.Pos(),
 			Defined: true,
-		}
+			Mutable: param.Mutable || isMutRef(param.Type),
+			MutRef:  isMutRef(param.Type),
+		})
 	}
 
 	// Проверяем тело функции с учётом локальной области
@@ -171,8 +375,22 @@ func (c *Checker) checkFunction(fn *ast.Function) {
 	c.currentFunction = ""
 }
 
+// isMutRef сообщает, является ли тип изменяемой ссылкой (&mut T):
+// через такую ссылку можно присваивать, даже если сама переменная не `mut`.
+func isMutRef(t ast.Type) bool {
+	ref, ok := t.(*ast.RefType)
+	return ok && ref.Mutable
+}
+
+// isMutBorrow сообщает, является ли выражение изменяемым заимствованием `&mut x`.
+func isMutBorrow(expr ast.Expr) bool {
+	ue, ok := expr.(*ast.UnaryExpr)
+	return ok && ue.Op == "&mut"
+}
+
 // checkBlock проверяет блок операторов.
 func (c *Checker) checkBlock(block *ast.Block, scope map[string]*Symbol) {
+	c.declareNestedFunctions(block, scope)
 	for _, stmt := range block.Stmts {
 		c.checkStmt(stmt, scope)
 	}
@@ -185,19 +403,167 @@ func (c *Checker) checkStmt(stmt ast.Stmt, scope map[string]*Symbol) {
 		c.checkLetStmt(s, scope)
 	case *ast.ExprStmt:
 		c.checkExpr(s.Expr, scope)
+	case *ast.AssignStmt:
+		c.checkAssignStmt(s, scope)
+	case *ast.FnStmt:
+		c.checkNestedFunction(s.Func, scope)
+	}
+}
+
+// declareNestedFunctions объявляет функции, вложенные в блок: как и в Rust,
+// они видны во всём блоке, в том числе до места объявления.
+func (c *Checker) declareNestedFunctions(block *ast.Block, scope map[string]*Symbol) {
+	for _, stmt := range block.Stmts {
+		fs, ok := stmt.(*ast.FnStmt)
+		if !ok {
+			continue
+		}
+		fn := fs.Func
+		if sym, exists := scope[fn.Name]; exists && sym.Depth == c.blockDepth {
+			c.error(CodeDuplicateDefinition, fmt.Sprintf("duplicate function declaration: %s", fn.Name), fn.Pos())
+			continue
+		}
+		params := make([]TypeInfo, 0, len(fn.Params))
+		for _, param := range fn.Params {
+			params = append(params, substitute(c.extractFnType(fn, param.Type), nil))
+		}
+		c.define(scope, &Symbol{
+			Kind:     SymbolFunction,
+			Name:     fn.Name,
+			Type:     funcType(params, c.genericResult(fn)),
+			Pos:      fn.Pos(),
+			Defined:  true,
+			Function: fn,
+			Depth:    c.blockDepth,
+		})
+	}
+}
+
+// checkNestedFunction проверяет тело вложенной функции. Локальные переменные
+// объемлющей функции в нём недоступны; видны только вложенные функции внешних блоков.
+func (c *Checker) checkNestedFunction(fn *ast.Function, scope map[string]*Symbol) {
+	savedFunction, savedParams, savedLoops := c.currentFunction, c.typeParams, c.loops
+	savedReturn, savedDepth := c.returnType, c.blockDepth
+	defer func() {
+		c.currentFunction, c.typeParams, c.loops = savedFunction, savedParams, savedLoops
+		c.returnType, c.blockDepth = savedReturn, savedDepth
+	}()
+
+	items := make(map[string]*Symbol)
+	for name, sym := range scope {
+		if sym.Kind == SymbolFunction {
+			items[name] = sym
+		}
+	}
+	c.blockDepth = 0
+	c.checkFunctionIn(fn, items)
+}
+
+// checkAssignStmt проверяет оператор присваивания.
+// Присваивать можно только через изменяемую (`mut`) переменную,
+// а тип значения должен совпадать с типом левой части.
+func (c *Checker) checkAssignStmt(as *ast.AssignStmt, scope map[string]*Symbol) {
+	targetType := c.checkExpr(as.Target, scope)
+	// Литерал без суффикса принимает тип цели: `k = 5` при k: i64
+	valueType := c.unifyLiteral(as.Value, c.checkExpr(as.Value, scope), targetType)
+
+	if root := assignmentRoot(as.Target); root != nil {
+		sym, exists := scope[root.Val]
+		switch {
+		case !exists:
+		case isDeref(as.Target):
+			// `*r = v` меняет не переменную r, а значение, на которое она ссылается
+			if !sym.MutRef {
+				c.error(CodeAssignThroughRef, fmt.Sprintf("cannot assign to `*%s`, which is behind a `&` reference", root.Val), as.Pos())
+			}
+		case !sym.Mutable:
+			c.errorWithFix(CodeAssignTwice, fmt.Sprintf("cannot assign twice to immutable variable `%s`", root.Val),
+				fmt.Sprintf("consider making this binding mutable: `mut %s`", root.Val), as.Pos())
+		}
+	}
+
+	if !c.typesCompatible(targetType, valueType) {
+		c.error(CodeMismatchedTypes, fmt.Sprintf("type mismatch in assignment: expected %s, got %s", targetType.Name, valueType.Name), as.Value.Pos())
 	}
 }
 
+// checkLetPattern проверяет деструктурирующее объявление `let (a, b) = pair;`:
+// образец должен подходить к типу инициализатора (или к явно указанному типу),
+// а каждая переменная образца объявляется с типом соответствующей части значения.
+func (c *Checker) checkLetPattern(ls *ast.LetStmt, scope map[string]*Symbol) {
+	initType := c.checkExpr(ls.Init, scope)
+	if ls.Type != nil {
+		if declType := c.extractType(ls.Type); declType.Name != "infer" {
+			if !c.typesCompatible(declType, initType) {
+				c.error(CodeMismatchedTypes, fmt.Sprintf("type mismatch: expected %s, got %s", declType.Name, initType.Name), ls.Pos())
+			}
+			initType = declType
+		}
+	}
+
+	// Переменные образца объявляются одним оператором Go `a, b := ...`,
+	// поэтому ни одна из них не может повторно объявлять переменную того же блока
+	ast.Walk(ls.Pattern, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.IdentPattern); ok {
+			if sym, exists := scope[ident.Name]; exists && sym.Depth == c.blockDepth {
+				c.error(CodeDuplicateDefinition, fmt.Sprintf("variable %s already declared in this scope", ident.Name), ident.Pos())
+			}
+		}
+		return true
+	})
+	c.checkPattern(ls.Pattern, initType, scope)
+}
+
+// assignmentRoot возвращает переменную, через которую выполняется присваивание:
+// для `p.x`, `v[i]` и `*r` это `p`, `v` и `r` соответственно.
+func assignmentRoot(expr ast.Expr) *ast.Literal {
+	switch e := expr.(type) {
+	case *ast.Literal:
+		if e.Kind == "IDENT" {
+			return e
+		}
+	case *ast.FieldExpr:
+		return assignmentRoot(e.Receiver)
+	case *ast.IndexExpr:
+		return assignmentRoot(e.Expr)
+	case *ast.UnaryExpr:
+		if e.Op == "*" {
+			return assignmentRoot(e.Expr)
+		}
+	}
+	return nil
+}
+
+// isDeref сообщает, является ли выражение разыменованием `*r`.
+func isDeref(expr ast.Expr) bool {
+	ue, ok := expr.(*ast.UnaryExpr)
+	return ok && ue.Op == "*"
+}
+
 // checkLetStmt проверяет оператор объявления переменной.
 func (c *Checker) checkLetStmt(ls *ast.LetStmt, scope map[string]*Symbol) {
-	// Проверяем, не объявлена ли переменная уже
-	if _, exists := scope[ls.Name]; exists {
-		c.error(fmt.Sprintf("variable %s already declared in this scope", ls.Name), ls.Pos())
+	if ls.Pattern != nil {
+		c.checkLetPattern(ls, scope)
+		return
+	}
+
+	// Переменная вложенного блока может затенять внешнюю, но не переменную
+	// (или параметр) того же блока: в Go такое повторное объявление недопустимо
+	if sym, exists := scope[ls.Name]; exists && sym.Depth == c.blockDepth {
+		c.error(CodeDuplicateDefinition, fmt.Sprintf("variable %s already declared in this scope", ls.Name), ls.Pos())
 		return
 	}
 
 	// Тип инициализирующего выражения
 	initType := c.checkExpr(ls.Init, scope)
+	mutRef := isMutBorrow(ls.Init) || ls.Type != nil && isMutRef(ls.Type)
+
+	// Result понижается в пару (значение, error), которую нельзя хранить в одной переменной Go
+	if strings.HasPrefix(initType.Name, "Result<") {
+		c.errorWithFix(CodeUnsupported, fmt.Sprintf("binding a value of type %s to a variable is not supported", initType.Name),
+			"propagate the error with `?` or unwrap the value", ls.Init.Pos())
+		initType = TypeInfo{Name: "infer"}
+	}
 
 	// Если тип объявлен явно
 	if ls.Type != nil {
@@ -205,43 +571,63 @@ func (c *Checker) checkLetStmt(ls *ast.LetStmt, scope map[string]*Symbol) {
 
 		// Если явный тип — "infer", значит тип должен выводиться из инициализатора
 		if declType.Name == "infer" {
-			scope[ls.Name] = &Symbol{
+			c.define(scope, &Symbol{
 				Kind:    SymbolVariable,
 				Name:    ls.Name,
 				Type:    initType,
 				Pos:     ls.Pos(),
 				Defined: true,
-			}
+				Mutable: ls.Mutable,
+				Depth:   c.blockDepth,
+				MutRef:  mutRef,
+			})
 			return
 		}
 
+		// Литерал без суффикса принимает объявленный тип: целый — если помещается в него,
+		// дробный — f32 или f64. Литерал с суффиксом проверяется по типу суффикса
+		if untypedLiteral(ls.Init) == "" {
+			c.checkIntLiteral(ls.Init, declType)
+		}
+		initType = c.unifyLiteral(ls.Init, initType, declType)
+		// Так же и литералы-элементы `vec![0; n]` или `[1, 2]` при объявленном типе элементов
+		if c.elemLiteralsFit(ls.Init, declType) {
+			initType = withElem(initType, declType.Args[0])
+		}
+
 		// Проверяем совпадение типов
 		if !c.typesCompatible(declType, initType) {
-			c.error(fmt.Sprintf("type mismatch: expected %s, got %s", declType.Name, initType.Name), ls.Pos())
+			c.error(CodeMismatchedTypes, fmt.Sprintf("type mismatch: expected %s, got %s", declType.Name, initType.Name), ls.Pos())
 		}
 
 		// Регистрируем переменную в текущей области
-		scope[ls.Name] = &Symbol{
+		c.define(scope, &Symbol{
 			Kind:    SymbolVariable,
 			Name:    ls.Name,
 			Type:    declType,
 			Pos:     ls.Pos(),
 			Defined: true,
-		}
+			Mutable: ls.Mutable,
+			Depth:   c.blockDepth,
+			MutRef:  mutRef,
+		})
 	} else {
 		// Тип выводится из инициализатора
 		if initType.Name == "infer" {
-			c.error("cannot infer type for variable without explicit type", ls.Pos())
+			c.error(CodeTypeAnnotations, "cannot infer type for variable without explicit type", ls.Pos())
 			return
 		}
 
-		scope[ls.Name] = &Symbol{
+		c.define(scope, &Symbol{
 			Kind:    SymbolVariable,
 			Name:    ls.Name,
 			Type:    initType,
 			Pos:     ls.Pos(),
 			Defined: true,
-		}
+			Mutable: ls.Mutable,
+			Depth:   c.blockDepth,
+			MutRef:  mutRef,
+		})
 	}
 }
 
@@ -258,8 +644,56 @@ func (c *Checker) checkExpr(expr ast.Expr, scope map[string]*Symbol) TypeInfo {
 		return c.checkCallExpr(e, scope)
 	case *ast.BlockExpr:
 		return c.checkBlockExpr(e, scope)
+	case *ast.MethodCall:
+		return c.checkMethodCall(e, scope)
+	case *ast.TupleExpr:
+		return c.checkTupleExpr(e, scope)
+	case *ast.StructLit:
+		return c.checkStructLit(e, scope)
+	case *ast.ArrayExpr:
+		return c.checkArrayExpr(e, scope)
+	case *ast.ArrayRepeatExpr:
+		return c.checkArrayRepeatExpr(e, scope)
+	case *ast.MatchExpr:
+		return c.checkMatchExpr(e, scope)
+	case *ast.IfExpr:
+		return c.checkIfExpr(e, scope)
+	case *ast.FieldExpr:
+		return c.checkFieldExpr(e, scope)
+	case *ast.IndexExpr:
+		return c.checkIndexExpr(e, scope)
+	case *ast.TryExpr:
+		return c.checkTryExpr(e, scope)
+	case *ast.PathExpr:
+		return c.checkPathExpr(e)
+	case *ast.ClosureExpr:
+		return c.checkClosure(e, scope)
+	case *ast.CastExpr:
+		return c.checkCastExpr(e, scope)
+	case *ast.RangeExpr:
+		// Диапазон транслируется только как заголовок счётного цикла for
+		c.error(CodeUnsupported, "ranges are supported only as `for` loop iterators", e.Pos())
+		return c.checkRangeExpr(e, scope)
+	case *ast.WhileExpr:
+		if condType := c.checkExpr(e.Cond, scope); !c.typesCompatible(TypeInfo{Name: "bool"}, condType) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in while condition: expected bool, got %s", condType.Name), e.Cond.Pos())
+		}
+		c.checkLoopBody("while", e.Label, e.Body, scope)
+		return TypeInfo{Name: "()"}
+	case *ast.LoopExpr:
+		if breakType := c.checkLoopBody("loop", e.Label, e.Body, scope); breakType != nil {
+			return *breakType
+		}
+		return TypeInfo{Name: "()"}
+	case *ast.ForExpr:
+		return c.checkForExpr(e, scope)
+	case *ast.BreakExpr:
+		return c.checkBreakExpr(e, scope)
+	case *ast.ContinueExpr:
+		c.targetLoop("continue", e.Label, e.Pos())
+		return TypeInfo{Name: "infer"}
 	default:
-		c.error("unsupported expression type", expr.Pos())
+		c.error(CodeUnsupported, "unsupported expression type", expr.Pos())
 		return TypeInfo{Name: "()"}
 	}
 }
@@ -268,11 +702,20 @@ func (c *Checker) checkExpr(expr ast.Expr, scope map[string]*Symbol) TypeInfo {
 func (c *Checker) checkLiteral(lit *ast.Literal, scope map[string]*Symbol) TypeInfo {
 	switch lit.Kind {
 	case "INT":
+		// Литерал с суффиксом (`5u8`, `1f32`) имеет тип суффикса
+		if _, suffix := ast.NumberSuffix(lit.Val); suffix != "" {
+			return TypeInfo{Name: suffix}
+		}
 		return TypeInfo{Name: "i32"}
 	case "FLOAT":
+		if _, suffix := ast.NumberSuffix(lit.Val); suffix == "f32" {
+			return TypeInfo{Name: "f32"}
+		}
 		return TypeInfo{Name: "f64"}
 	case "STRING":
 		return TypeInfo{Name: "String"}
+	case "CHAR":
+		return TypeInfo{Name: "char"}
 	case "BOOL":
 		return TypeInfo{Name: "bool"}
 	case "IDENT":
@@ -295,6 +738,11 @@ func (c *Checker) resolveIdentifier(lit *ast.Literal, scope map[string]*Symbol)
 		return TypeInfo{Name: "()"}
 	}
 
+	// None — пустое значение Option, его тип выводится из контекста
+	if name == "None" {
+		return TypeInfo{Name: "infer"}
+	}
+
 	// Сначала проверяем локальную область видимости (параметры, локальные переменные)
 	if scope != nil {
 		if sym, exists := scope[name]; exists {
@@ -308,8 +756,12 @@ func (c *Checker) resolveIdentifier(lit *ast.Literal, scope map[string]*Symbol)
 		return sym.Type
 	}
 
-	c.error(fmt.Sprintf("undefined identifier: %s", name), lit.Pos())
-	return TypeInfo{Name: "()"}
+	// Область видимости заполняется в порядке операторов, поэтому переменная,
+	// объявленная ниже по блоку (или в самом инициализаторе `let x = x + 1`), здесь
+	// ещё не видна. Ошибка указывает на место использования; тип выводится, чтобы
+	// не порождать каскад ошибок несовпадения типов.
+	c.error(CodeUnresolvedName, fmt.Sprintf("cannot find value `%s` in this scope", name), lit.Pos())
+	return TypeInfo{Name: "infer"}
 }
 
 // checkBinaryExpr проверяет бинарное выражение.
@@ -319,17 +771,38 @@ func (c *Checker) checkBinaryExpr(be *ast.BinaryExpr, scope map[string]*Symbol)
 
 	// Проверка арифметических операций
 	if c.isArithmeticOp(be.Op) {
+		// Тип одного из операндов ещё не выведен — результат имеет тип другого
+		if leftType.Name == "infer" {
+			return rightType
+		}
+		if rightType.Name == "infer" {
+			return leftType
+		}
+		// Конкатенация `String + &str`: левый операнд — владеющая строка, а не
+		// ссылка и не строковый литерал (литерал в Rust имеет тип &str)
+		if be.Op == "+" && isString(deref(leftType)) {
+			if leftType.IsReference || isStringLiteral(be.Left) || !isString(deref(rightType)) {
+				c.error(CodeBinaryOp, "cannot add strings: expected String + &str", be.Pos())
+			}
+			return TypeInfo{Name: "String"}
+		}
 		if !c.isNumeric(leftType) || !c.isNumeric(rightType) {
-			c.error(fmt.Sprintf("operands of %s must be numeric", be.Op), be.Pos())
+			c.error(CodeBinaryOp, fmt.Sprintf("operands of %s must be numeric", be.Op), be.Pos())
 			return TypeInfo{Name: "()"}
 		}
+		// Rust не приводит числовые типы неявно: `i32 + f64` — ошибка
+		leftType, rightType = c.unifyLiterals(be.Left, be.Right, leftType, rightType)
+		if leftType.Name != rightType.Name {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types: cannot apply %s to %s and %s", be.Op, leftType.Name, rightType.Name), be.Pos())
+		}
 		return leftType // Результат арифметической операции имеет тот же тип
 	}
 
 	// Проверка операций сравнения
 	if c.isComparisonOp(be.Op) {
+		leftType, rightType = c.unifyLiterals(be.Left, be.Right, leftType, rightType)
 		if !c.typesCompatible(leftType, rightType) {
-			c.error(fmt.Sprintf("cannot compare %s with %s", leftType.Name, rightType.Name), be.Pos())
+			c.error(CodeBinaryOp, fmt.Sprintf("cannot compare %s with %s", leftType.Name, rightType.Name), be.Pos())
 		}
 		return TypeInfo{Name: "bool"}
 	}
@@ -337,7 +810,7 @@ func (c *Checker) checkBinaryExpr(be *ast.BinaryExpr, scope map[string]*Symbol)
 	// Проверка логических операций
 	if c.isLogicalOp(be.Op) {
 		if !c.isBool(leftType) || !c.isBool(rightType) {
-			c.error(fmt.Sprintf("operands of %s must be boolean", be.Op), be.Pos())
+			c.error(CodeBinaryOp, fmt.Sprintf("operands of %s must be boolean", be.Op), be.Pos())
 		}
 		return TypeInfo{Name: "bool"}
 	}
@@ -345,6 +818,79 @@ func (c *Checker) checkBinaryExpr(be *ast.BinaryExpr, scope map[string]*Symbol)
 	return TypeInfo{Name: "()"}
 }
 
+// unifyLiterals приводит операнд из числовых литералов без суффикса к типу другого
+// операнда: в `x + 1` при x: i64 литерал 1 имеет тип i64. Целочисленный литерал
+// принимает только целый тип (и проверяется на переполнение), дробный — только f32 или f64.
+// leftExpr и rightExpr — операнды, left и right — их типы.
+func (c *Checker) unifyLiterals(leftExpr, rightExpr ast.Expr, left, right TypeInfo) (TypeInfo, TypeInfo) {
+	return c.unifyLiteral(leftExpr, left, right), c.unifyLiteral(rightExpr, right, left)
+}
+
+// unifyLiteral возвращает тип other для выражения expr из литералов без суффикса
+// того же вида (целые — для целого other, дробные — для f32 и f64), иначе own.
+// Целый литерал при этом проверяется на переполнение типа other.
+func (c *Checker) unifyLiteral(expr ast.Expr, own, other TypeInfo) TypeInfo {
+	switch untypedLiteral(expr) {
+	case "INT":
+		if c.isInteger(other) {
+			c.checkIntLiteral(expr, other)
+			return other
+		}
+	case "FLOAT":
+		if other.Name == "f32" || other.Name == "f64" {
+			return other
+		}
+	}
+	return own
+}
+
+// untypedLiteral возвращает "INT" или "FLOAT", если выражение составлено только из
+// числовых литералов без суффикса этого вида (`1`, `-2.5`, `(1 + 2) * 3`), иначе "".
+func untypedLiteral(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Literal:
+		if _, suffix := ast.NumberSuffix(e.Val); suffix == "" && (e.Kind == "INT" || e.Kind == "FLOAT") {
+			return e.Kind
+		}
+	case *ast.UnaryExpr:
+		if e.Op == "-" {
+			return untypedLiteral(e.Expr)
+		}
+	case *ast.BinaryExpr:
+		if kind := untypedLiteral(e.Left); kind == untypedLiteral(e.Right) {
+			switch e.Op {
+			case "+", "-", "*", "/", "%":
+				return kind
+			}
+		}
+	}
+	return ""
+}
+
+// checkCastExpr проверяет приведение `expr as T` и возвращает целевой тип.
+// Допустимы приведения между числовыми типами, bool и char в целое и u8 в char —
+// то есть те, для которых в Go есть явное преобразование.
+func (c *Checker) checkCastExpr(ce *ast.CastExpr, scope map[string]*Symbol) TypeInfo {
+	from := c.checkExpr(ce.Expr, scope)
+	to := c.extractType(ce.Type)
+	if from.Name == "infer" || from.Name == to.Name {
+		return to
+	}
+
+	allowed := false
+	switch {
+	case c.isNumeric(to) || c.isInteger(to):
+		allowed = c.isNumeric(from) || c.isInteger(from) ||
+			(c.isInteger(to) && (from.Name == "bool" || from.Name == "char"))
+	case to.Name == "char":
+		allowed = from.Name == "u8"
+	}
+	if !allowed {
+		c.error(CodeInvalidCast, fmt.Sprintf("non-primitive cast: `%s` as `%s`", from.Name, to.Name), ce.Pos())
+	}
+	return to
+}
+
 // checkUnaryExpr проверяет унарное выражение.
 func (c *Checker) checkUnaryExpr(ue *ast.UnaryExpr, scope map[string]*Symbol) TypeInfo {
 	exprType := c.checkExpr(ue.Expr, scope)
@@ -352,14 +898,28 @@ func (c *Checker) checkUnaryExpr(ue *ast.UnaryExpr, scope map[string]*Symbol) Ty
 	switch ue.Op {
 	case "-":
 		if !c.isNumeric(exprType) {
-			c.error("operand of unary - must be numeric", ue.Pos())
+			c.error(CodeUnaryOp, "operand of unary - must be numeric", ue.Pos())
 		}
 		return exprType
 	case "!":
 		if !c.isBool(exprType) {
-			c.error("operand of unary ! must be boolean", ue.Pos())
+			c.error(CodeUnaryOp, "operand of unary ! must be boolean", ue.Pos())
 		}
 		return TypeInfo{Name: "bool"}
+	case "&":
+		return refType(exprType)
+	case "&mut":
+		c.checkMutBorrow(ue.Expr, scope)
+		return refType(exprType)
+	case "*":
+		if exprType.Name == "infer" {
+			return exprType
+		}
+		if !exprType.IsReference || exprType.Elem == nil {
+			c.error(CodeDeref, fmt.Sprintf("type `%s` cannot be dereferenced", exprType.Name), ue.Pos())
+			return TypeInfo{Name: "infer"}
+		}
+		return *exprType.Elem
 	default:
 		return TypeInfo{Name: "()"}
 	}
@@ -374,29 +934,48 @@ func (c *Checker) checkCallExpr(ce *ast.CallExpr, scope map[string]*Symbol) Type
 		if f.Kind == "IDENT" {
 			fnName = f.Val
 		}
+	case *ast.PathExpr:
+		return c.checkPathCall(f, ce, scope)
 	default:
-		c.error("expected function name in call", ce.Pos())
+		c.error(CodeUnsupported, "expected function name in call", ce.Pos())
 		return TypeInfo{Name: "()"}
 	}
 
-	// Проверяем на встроенные макросы (заканчиваются на !)
-	if len(fnName) > 0 && fnName[len(fnName)-1] == '!' {
-		// Встроенные макросы принимают произвольные аргументы и возвращают ()
+	// Конструкторы Option и Result: тип параметров пока не выводится
+	if isVariantConstructor(fnName) {
+		if len(ce.Args) != 1 {
+			c.error(CodeArgCount, fmt.Sprintf("%s expects 1 argument, got %d", fnName, len(ce.Args)), ce.Pos())
+		}
 		for _, arg := range ce.Args {
 			c.checkExpr(arg, scope)
 		}
-		return TypeInfo{Name: "()"}
+		return TypeInfo{Name: "infer"}
+	}
+
+	// Проверяем на встроенные макросы (заканчиваются на !)
+	if len(fnName) > 0 && fnName[len(fnName)-1] == '!' {
+		return c.checkMacroCall(fnName, ce, scope)
+	}
+
+	// Локальная переменная с замыканием затеняет функцию с тем же именем
+	if local, ok := scope[fnName]; ok {
+		return c.checkClosureCall(fnName, local, ce, scope)
 	}
 
 	// Ищем функцию в таблице символов
 	sym, exists := c.symbols[fnName]
 	if !exists {
-		c.error(fmt.Sprintf("undefined function: %s", fnName), ce.Pos())
+		c.error(CodeUnresolvedName, fmt.Sprintf("undefined function: %s", fnName), ce.Pos())
 		return TypeInfo{Name: "()"}
 	}
 
+	// Кортежная структура вызывается как конструктор: `Wrapper(5)`
+	if sym.Kind == SymbolStruct && sym.Struct.Kind == ast.StructTuple {
+		return c.checkTupleStructCall(sym.Struct, ce, scope)
+	}
+
 	if sym.Kind != SymbolFunction || sym.Function == nil {
-		c.error(fmt.Sprintf("%s is not a function", fnName), ce.Pos())
+		c.error(CodeNotAFunction, fmt.Sprintf("%s is not a function", fnName), ce.Pos())
 		return TypeInfo{Name: "()"}
 	}
 
@@ -404,31 +983,954 @@ func (c *Checker) checkCallExpr(ce *ast.CallExpr, scope map[string]*Symbol) Type
 
 	// Проверяем количество аргументов
 	if len(ce.Args) != len(fn.Params) {
-		c.error(fmt.Sprintf("function %s expects %d arguments, got %d", fnName, len(fn.Params), len(ce.Args)), ce.Pos())
+		c.error(CodeArgCount, fmt.Sprintf("function %s expects %d arguments, got %d", fnName, len(fn.Params), len(ce.Args)), ce.Pos())
 		return TypeInfo{Name: "()"}
 	}
 
-	// Проверяем типы аргументов
+	// Проверяем типы аргументов и возвращаем тип результата
+	argTypes := make([]TypeInfo, 0, len(ce.Args))
+	for _, arg := range ce.Args {
+		argTypes = append(argTypes, c.checkExpr(arg, scope))
+	}
+	return c.checkCallArgs(fn, fnName, argTypes, ce.Pos())
+}
+
+// lookupVariant находит вариант перечисления по пути `Enum::Variant`.
+// Если первый сегмент — перечисление, а вариант не найден, регистрирует ошибку;
+// ok == false означает, что путь не относится к пользовательскому перечислению.
+func (c *Checker) lookupVariant(path *ast.PathExpr) (en *ast.Enum, variant *ast.Variant, ok bool) {
+	if len(path.Segments) != 2 {
+		return nil, nil, false
+	}
+	sym := c.symbols[path.Segments[0]]
+	if sym == nil || sym.Enum == nil {
+		return nil, nil, false
+	}
+	for i := range sym.Enum.Variants {
+		if sym.Enum.Variants[i].Name == path.Segments[1] {
+			return sym.Enum, &sym.Enum.Variants[i], true
+		}
+	}
+	c.error(CodeNoItem, fmt.Sprintf("no variant named `%s` in enum `%s`", path.Segments[1], sym.Enum.Name), path.Pos())
+	return sym.Enum, nil, true
+}
+
+// checkPathExpr проверяет путь, используемый как значение: `Color::Red` имеет тип Color.
+// Варианты с данными без аргументов и неизвестные пути — ошибка.
+func (c *Checker) checkPathExpr(path *ast.PathExpr) TypeInfo {
+	name := strings.Join(path.Segments, "::")
+	if len(path.Segments) == 2 && path.Segments[0] == "Option" && path.Segments[1] == "None" {
+		return TypeInfo{Name: "infer"}
+	}
+	en, variant, ok := c.lookupVariant(path)
+	if !ok {
+		c.error(CodeUnresolvedPath, fmt.Sprintf("unresolved path: %s", name), path.Pos())
+		return TypeInfo{Name: "infer"}
+	}
+	if variant != nil && len(variant.Types) > 0 {
+		c.error(CodeArgCount, fmt.Sprintf("variant %s expects %d arguments, got 0", name, len(variant.Types)), path.Pos())
+	}
+	return TypeInfo{Name: en.Name}
+}
+
+// checkPathCall проверяет вызов по пути: конструктор кортежного варианта (`Shape::Circle(1.0)`)
+// или конструктор Option/Result, записанный с именем типа (`Option::Some(5)`).
+// Число и типы аргументов сверяются с данными варианта.
+func (c *Checker) checkPathCall(path *ast.PathExpr, ce *ast.CallExpr, scope map[string]*Symbol) TypeInfo {
+	name := strings.Join(path.Segments, "::")
+	if len(path.Segments) == 2 && (path.Segments[0] == "Option" || path.Segments[0] == "Result") &&
+		isVariantConstructor(path.Segments[1]) {
+		return c.checkCallExpr(ast.NewCallExpr(ce.Pos(), ast.NewLiteral(path.Pos(), "IDENT", path.Segments[1]), ce.Args), scope)
+	}
+
+	if typeName, fn, ok := c.lookupAssociated(path); ok {
+		return c.checkAssociatedCall(typeName, fn, ce, scope)
+	}
+	en, variant, ok := c.lookupVariant(path)
+	if !ok {
+		if sym := c.symbols[path.Segments[0]]; len(path.Segments) == 2 && sym != nil && sym.Struct != nil {
+			c.error(CodeNoItem, fmt.Sprintf("no function or associated item named `%s` found for `%s`", path.Segments[1], path.Segments[0]), path.Pos())
+		} else {
+			c.error(CodeUnresolvedPath, fmt.Sprintf("unresolved path: %s", name), path.Pos())
+		}
+	}
+	if variant == nil {
+		for _, arg := range ce.Args {
+			c.checkExpr(arg, scope)
+		}
+		// Тип неразрешённого пути неизвестен: infer не порождает каскад ошибок несовпадения
+		if en == nil {
+			return TypeInfo{Name: "infer"}
+		}
+		return TypeInfo{Name: en.Name}
+	}
+
+	if len(ce.Args) != len(variant.Types) {
+		c.error(CodeArgCount, fmt.Sprintf("variant %s expects %d arguments, got %d", name, len(variant.Types), len(ce.Args)), ce.Pos())
+		return TypeInfo{Name: en.Name}
+	}
 	for i, arg := range ce.Args {
 		argType := c.checkExpr(arg, scope)
-		paramType := c.extractType(fn.Params[i].Type)
+		fieldType := c.extractType(variant.Types[i])
+		if !c.typesCompatible(fieldType, argType) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("argument %d of %s: expected %s, got %s", i+1, name, fieldType.Name, argType.Name), ce.Pos())
+		}
+	}
+	return TypeInfo{Name: en.Name}
+}
+
+// lookupAssociated находит функцию из блока impl по пути `Type::func`.
+func (c *Checker) lookupAssociated(path *ast.PathExpr) (typeName string, fn *ast.Function, ok bool) {
+	if len(path.Segments) != 2 {
+		return "", nil, false
+	}
+	typeName = path.Segments[0]
+	fn, ok = c.methods[typeName][path.Segments[1]]
+	return typeName, fn, ok
+}
+
+// checkAssociatedCall проверяет вызов ассоциированной функции `Type::func(args)`.
+// Методы с self через путь не вызываются: транслятор генерирует их как методы Go.
+func (c *Checker) checkAssociatedCall(typeName string, fn *ast.Function, ce *ast.CallExpr, scope map[string]*Symbol) TypeInfo {
+	savedSelf := c.selfType
+	c.selfType = typeName
+	defer func() { c.selfType = savedSelf }()
+
+	name := typeName + "::" + fn.Name
+	argTypes := make([]TypeInfo, 0, len(ce.Args))
+	for _, arg := range ce.Args {
+		argTypes = append(argTypes, c.checkExpr(arg, scope))
+	}
+	if fn.Receiver != nil {
+		c.error(CodeUnsupported, fmt.Sprintf("%s is a method; call it as `value.%s(...)`", name, fn.Name), ce.Pos())
+		return c.genericResult(fn)
+	}
+	if len(argTypes) != len(fn.Params) {
+		c.error(CodeArgCount, fmt.Sprintf("function %s expects %d arguments, got %d", name, len(fn.Params), len(argTypes)), ce.Pos())
+		return c.genericResult(fn)
+	}
+	return c.checkCallArgs(fn, name, argTypes, ce.Pos())
+}
+
+// checkMethodCall проверяет вызов метода.
+// `unwrap`/`expect` на Option<T> и Result<T, E> возвращают T.
+// Для остальных методов тип результата пока выводится (infer).
+func (c *Checker) checkMethodCall(mc *ast.MethodCall, scope map[string]*Symbol) TypeInfo {
+	recvType := c.checkExpr(mc.Receiver, scope)
+	argTypes := make([]TypeInfo, 0, len(mc.Args))
+	for _, arg := range mc.Args {
+		argTypes = append(argTypes, c.checkExpr(arg, scope))
+	}
+
+	// Методы из блоков impl ищутся на типе, на который указывает ссылка (auto-deref):
+	// `p.len()` и `(&p).len()` разрешаются одинаково.
+	typeName := deref(recvType).Name
+	if method, ok := c.methods[typeName][mc.Method]; ok {
+		if method.Receiver != nil && isMutRef(method.Receiver.Type) {
+			c.checkMutBorrow(mc.Receiver, scope)
+		}
+		return c.checkMethodSignature(mc, typeName, method, argTypes)
+	}
+	if sym := c.symbols[typeName]; sym != nil && (sym.Struct != nil || sym.Enum != nil) {
+		c.error(CodeNoItem, fmt.Sprintf("no method named `%s` found for `%s`", mc.Method, typeName), mc.Pos())
+		return TypeInfo{Name: "infer"}
+	}
+
+	if mc.Method == "unwrap" || mc.Method == "expect" {
+		wantArgs := 0
+		if mc.Method == "expect" {
+			wantArgs = 1
+		}
+		if len(mc.Args) != wantArgs {
+			c.error(CodeArgCount, fmt.Sprintf("method %s expects %d arguments, got %d", mc.Method, wantArgs, len(mc.Args)), mc.Pos())
+		}
+		if isOptionOrResult(recvType) && len(recvType.Args) > 0 {
+			return recvType.Args[0]
+		}
+	}
+	return TypeInfo{Name: "infer"}
+}
+
+// checkClosure проверяет замыкание и возвращает его тип `fn(T1, T2) -> R`.
+// Параметры видны только в теле; переменные объемлющей области доступны (захват).
+// Параметр без аннотации имеет тип infer; возвращаемый тип без аннотации —
+// тип тела. break/continue и `?` в теле относятся к самому замыканию.
+func (c *Checker) checkClosure(ce *ast.ClosureExpr, scope map[string]*Symbol) TypeInfo {
+	savedLoops, savedReturn := c.loops, c.returnType
+	c.loops = nil
+	c.blockDepth++
+	defer func() {
+		c.loops, c.returnType = savedLoops, savedReturn
+		c.blockDepth--
+	}()
+
+	inner := make(map[string]*Symbol, len(scope)+len(ce.Params))
+	for name, sym := range scope {
+		inner[name] = sym
+	}
+	params := make([]TypeInfo, 0, len(ce.Params))
+	for _, param := range ce.Params {
+		paramType := TypeInfo{Name: "infer"}
+		if param.Type != nil {
+			paramType = c.extractType(param.Type)
+		}
+		if _, exists := inner[param.Name]; exists && inner[param.Name].Depth == c.blockDepth {
+			c.error(CodeDuplicateDefinition, fmt.Sprintf("identifier `%s` is bound more than once in this parameter list", param.Name), param.Pos())
+		}
+		c.define(inner, &Symbol{
+			Kind:    SymbolVariable,
+			Name:    param.Name,
+			Type:    paramType,
+			Pos:     param.Pos(),
+			Defined: true,
+			Mutable: param.Mutable,
+			Depth:   c.blockDepth,
+		})
+		params = append(params, paramType)
+	}
+
+	result := TypeInfo{Name: "infer"}
+	if ce.ReturnType != nil {
+		result = c.extractType(ce.ReturnType)
+	}
+	c.returnType = result
+	if ce.Body != nil {
+		bodyType := c.checkExpr(ce.Body, inner)
+		switch {
+		case ce.ReturnType == nil:
+			result = bodyType
+		case !c.typesCompatible(result, bodyType):
+			c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in closure body: expected %s, got %s", result.Name, bodyType.Name), ce.Body.Pos())
+		}
+	}
+	return funcType(params, result)
+}
+
+// funcType строит тип замыкания; имя записывается как в Rust: `fn(i32) -> i32`.
+func funcType(params []TypeInfo, result TypeInfo) TypeInfo {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	name := "fn(" + strings.Join(names, ", ") + ")"
+	if result.Name != "()" {
+		name += " -> " + result.Name
+	}
+	return TypeInfo{Name: name, IsFunc: true, Args: params, Elem: &result}
+}
 
-		if !c.typesCompatible(paramType, argType) {
-			c.error(fmt.Sprintf("argument %d of %s: expected %s, got %s", i+1, fnName, paramType.Name, argType.Name), ce.Pos())
+// checkClosureCall проверяет вызов локальной переменной local как замыкания.
+// Переменная неизвестного (infer) типа допускает любой вызов.
+func (c *Checker) checkClosureCall(name string, local *Symbol, ce *ast.CallExpr, scope map[string]*Symbol) TypeInfo {
+	argTypes := make([]TypeInfo, 0, len(ce.Args))
+	for _, arg := range ce.Args {
+		argTypes = append(argTypes, c.checkExpr(arg, scope))
+	}
+
+	typ := deref(local.Type)
+	kind := "closure"
+	if local.Kind == SymbolFunction {
+		kind = "function" // вложенная функция
+	}
+	switch {
+	case typ.Name == "infer":
+		return TypeInfo{Name: "infer"}
+	case !typ.IsFunc:
+		c.error(CodeNotAFunction, fmt.Sprintf("%s is not a function", name), ce.Pos())
+		return TypeInfo{Name: "infer"}
+	case len(argTypes) != len(typ.Args):
+		c.error(CodeArgCount, fmt.Sprintf("%s %s expects %d arguments, got %d", kind, name, len(typ.Args), len(argTypes)), ce.Pos())
+	default:
+		for i, argType := range argTypes {
+			if !c.typesCompatible(typ.Args[i], argType) {
+				c.error(CodeMismatchedTypes, fmt.Sprintf("argument %d of %s: expected %s, got %s", i+1, name, typ.Args[i].Name, argType.Name), ce.Args[i].Pos())
+			}
 		}
 	}
+	return *typ.Elem
+}
 
-	// Возвращаем тип возвращаемого значения функции
-	return c.extractType(fn.ReturnType)
+// checkFieldExpr проверяет доступ к полю структуры и возвращает тип поля.
+func (c *Checker) checkFieldExpr(fe *ast.FieldExpr, scope map[string]*Symbol) TypeInfo {
+	// Доступ к полю через ссылку выполняет auto-deref
+	recvType := deref(c.checkExpr(fe.Receiver, scope))
+	if len(recvType.Elems) > 0 {
+		// Элемент кортежа `t.0`
+		if i, err := strconv.Atoi(fe.Field); err == nil && i < len(recvType.Elems) {
+			return recvType.Elems[i]
+		}
+		c.error(CodeNoField, fmt.Sprintf("no field `%s` on type `%s`", fe.Field, recvType.Name), fe.Pos())
+		return TypeInfo{Name: "infer"}
+	}
+	sym := c.symbols[recvType.Name]
+	if sym == nil || sym.Struct == nil {
+		// Тип получателя неизвестен — тип поля выводится позже
+		return TypeInfo{Name: "infer"}
+	}
+	for _, field := range sym.Struct.Fields {
+		if field.Name == fe.Field {
+			return c.extractType(field.Type)
+		}
+	}
+	c.error(CodeNoField, fmt.Sprintf("no field `%s` on type `%s`", fe.Field, recvType.Name), fe.Pos())
+	return TypeInfo{Name: "infer"}
 }
 
-// checkBlockExpr проверяет блочное выражение.
+// checkTupleStructCall проверяет вызов конструктора кортежной структуры st:
+// число аргументов и их типы должны совпадать с позиционными полями.
+func (c *Checker) checkTupleStructCall(st *ast.Struct, ce *ast.CallExpr, scope map[string]*Symbol) TypeInfo {
+	if len(ce.Args) != len(st.Fields) {
+		c.error(CodeArgCount, fmt.Sprintf("struct %s expects %d fields, got %d", st.Name, len(st.Fields), len(ce.Args)), ce.Pos())
+	}
+	for i, arg := range ce.Args {
+		argType := c.checkExpr(arg, scope)
+		if i >= len(st.Fields) {
+			continue
+		}
+		fieldType := c.extractType(st.Fields[i].Type)
+		if !c.typesCompatible(fieldType, argType) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("field %d of %s: expected %s, got %s", i, st.Name, fieldType.Name, argType.Name), arg.Pos())
+		}
+	}
+	return TypeInfo{Name: st.Name}
+}
+
+// checkIndexExpr проверяет индексирование Vec<T> или HashMap<K, V> и возвращает тип элемента.
+func (c *Checker) checkIndexExpr(ie *ast.IndexExpr, scope map[string]*Symbol) TypeInfo {
+	baseType := c.checkExpr(ie.Expr, scope)
+	c.checkExpr(ie.Index, scope)
+
+	switch {
+	case baseType.IsArray && len(baseType.Args) == 1:
+		return baseType.Args[0]
+	case strings.HasPrefix(baseType.Name, "HashMap<") && len(baseType.Args) == 2:
+		return baseType.Args[1]
+	}
+	return TypeInfo{Name: "infer"}
+}
+
+// checkMutBorrow проверяет, что получатель метода с `&mut self` можно заимствовать
+// изменяемо: переменная, через которую он достижим, объявлена как `mut` или сама
+// является изменяемой ссылкой. Временные значения (`Counter::new().reset()`) не проверяются.
+func (c *Checker) checkMutBorrow(recv ast.Expr, scope map[string]*Symbol) {
+	root := assignmentRoot(recv)
+	if root == nil {
+		return
+	}
+	if sym, exists := scope[root.Val]; exists && sym.Kind == SymbolVariable && !sym.Mutable && !sym.MutRef {
+		c.errorWithFix(CodeBorrowMut, fmt.Sprintf("cannot borrow `%s` as mutable, as it is not declared as mutable", root.Val),
+			fmt.Sprintf("consider changing this to be mutable: `mut %s`", root.Val), recv.Pos())
+	}
+}
+
+// checkMethodSignature сверяет аргументы вызова метода с его сигнатурой и возвращает тип результата.
+// Self в сигнатуре разрешается в тип получателя.
+func (c *Checker) checkMethodSignature(mc *ast.MethodCall, typeName string, method *ast.Function, argTypes []TypeInfo) TypeInfo {
+	savedSelf := c.selfType
+	c.selfType = typeName
+	defer func() { c.selfType = savedSelf }()
+
+	if method.Receiver == nil {
+		c.error(CodeNoItem, fmt.Sprintf("%s::%s is an associated function, not a method", typeName, mc.Method), mc.Pos())
+		return c.genericResult(method)
+	}
+	if len(argTypes) != len(method.Params) {
+		c.error(CodeArgCount, fmt.Sprintf("method %s expects %d arguments, got %d", mc.Method, len(method.Params), len(argTypes)), mc.Pos())
+		return c.genericResult(method)
+	}
+	return c.checkCallArgs(method, mc.Method, argTypes, mc.Pos())
+}
+
+// checkTryExpr проверяет оператор `expr?`: выражение должно иметь тип Result или Option,
+// а сама функция — возвращать Result или Option, чтобы ошибку было куда распространить.
+// Результат — тип успешного значения.
+func (c *Checker) checkTryExpr(te *ast.TryExpr, scope map[string]*Symbol) TypeInfo {
+	exprType := c.checkExpr(te.Expr, scope)
+
+	if !isOptionOrResult(c.returnType) {
+		c.error(CodeTryOperator, fmt.Sprintf("the `?` operator can only be used in a function that returns Result or Option, not %s", c.returnType.Name), te.Pos())
+	}
+	if exprType.Name == "infer" {
+		return exprType
+	}
+	if !isOptionOrResult(exprType) || len(exprType.Args) == 0 {
+		c.error(CodeTryOperator, fmt.Sprintf("the `?` operator can only be applied to Result or Option, not %s", exprType.Name), te.Pos())
+		return TypeInfo{Name: "infer"}
+	}
+	return exprType.Args[0]
+}
+
+// isVariantConstructor проверяет, является ли имя конструктором Option или Result.
+func isVariantConstructor(name string) bool {
+	return name == "Ok" || name == "Err" || name == "Some"
+}
+
+// isOptionOrResult проверяет, является ли тип Option<T> или Result<T, E>.
+func isOptionOrResult(t TypeInfo) bool {
+	return strings.HasPrefix(t.Name, "Option<") || strings.HasPrefix(t.Name, "Result<")
+}
+
+// checkBlockExpr проверяет блочное выражение `{ ... }` и возвращает тип его значения.
 func (c *Checker) checkBlockExpr(be *ast.BlockExpr, scope map[string]*Symbol) TypeInfo {
-	// Для простоты возвращаем unit тип
-	// В полной реализации нужно анализировать последнее выражение блока
+	return c.checkBlockValue(be.Block, scope)
+}
+
+// checkBlockValue проверяет блок в собственной области видимости и возвращает его тип:
+// тип хвостового выражения (без ';') или (), если блок им не заканчивается.
+func (c *Checker) checkBlockValue(block *ast.Block, scope map[string]*Symbol) TypeInfo {
+	inner := make(map[string]*Symbol, len(scope))
+	for name, sym := range scope {
+		inner[name] = sym
+	}
+	c.blockDepth++
+	defer func() { c.blockDepth-- }()
+
+	c.declareNestedFunctions(block, inner)
+	result := TypeInfo{Name: "()"}
+	for i, stmt := range block.Stmts {
+		if es, ok := stmt.(*ast.ExprStmt); ok && !es.Semi && i == len(block.Stmts)-1 {
+			result = c.checkExpr(es.Expr, inner)
+			continue
+		}
+		c.checkStmt(stmt, inner)
+	}
+	return result
+}
+
+// checkIfExpr проверяет условное выражение: условие должно быть bool, а ветви
+// if и else — иметь совместимые типы. Без else выражение имеет тип ().
+func (c *Checker) checkIfExpr(ie *ast.IfExpr, scope map[string]*Symbol) TypeInfo {
+	if condType := c.checkExpr(ie.Cond, scope); !c.typesCompatible(TypeInfo{Name: "bool"}, condType) {
+		c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in if condition: expected bool, got %s", condType.Name), ie.Cond.Pos())
+	}
+
+	thenType := c.checkBlockValue(ie.Then, scope)
+	if ie.Else == nil {
+		return TypeInfo{Name: "()"}
+	}
+	elseType := c.checkExpr(ie.Else, scope)
+	if !c.typesCompatible(thenType, elseType) {
+		c.error(CodeMismatchedTypes, fmt.Sprintf("if and else have incompatible types: expected %s, got %s", thenType.Name, elseType.Name), ie.Else.Pos())
+	}
+	if thenType.Name == "infer" {
+		return elseType
+	}
+	return thenType
+}
+
+// checkLoopBody проверяет тело цикла kind и возвращает тип значения `break value`
+// (nil, если тело не выходит из цикла со значением).
+func (c *Checker) checkLoopBody(kind, label string, body *ast.Block, scope map[string]*Symbol) *TypeInfo {
+	frame := &loopFrame{kind: kind, label: label}
+	c.loops = append(c.loops, frame)
+	c.checkBlockValue(body, scope)
+	c.loops = c.loops[:len(c.loops)-1]
+	return frame.breakType
+}
+
+// checkRangeExpr проверяет диапазон `start..end`: границы должны быть целыми числами
+// одного типа. Диапазон имеет тип Range<T> и обходится циклом for.
+func (c *Checker) checkRangeExpr(re *ast.RangeExpr, scope map[string]*Symbol) TypeInfo {
+	startType, endType := c.checkExpr(re.Start, scope), c.checkExpr(re.End, scope)
+	startType, endType = c.unifyLiterals(re.Start, re.End, startType, endType)
+	elemType := startType
+	if elemType.Name == "infer" {
+		elemType = endType
+	}
+	for _, t := range []TypeInfo{startType, endType} {
+		if t.Name != "infer" && !c.isInteger(t) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("range bounds must be integers, got %s", t.Name), re.Pos())
+			return TypeInfo{Name: "infer"}
+		}
+	}
+	if startType.Name != "infer" && endType.Name != "infer" && startType.Name != endType.Name {
+		c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched range bounds: %s and %s", startType.Name, endType.Name), re.Pos())
+	}
+	if elemType.Name == "infer" {
+		return elemType
+	}
+	return TypeInfo{Name: "Range<" + elemType.Name + ">", Args: []TypeInfo{elemType}}
+}
+
+// checkForExpr проверяет цикл `for x in iter`: переменная цикла получает тип
+// элемента коллекции или диапазона и видна только в теле цикла.
+func (c *Checker) checkForExpr(fe *ast.ForExpr, scope map[string]*Symbol) TypeInfo {
+	var iterType TypeInfo
+	if re, ok := fe.Iter.(*ast.RangeExpr); ok {
+		iterType = c.checkRangeExpr(re, scope)
+	} else {
+		iterType = deref(c.checkExpr(fe.Iter, scope))
+	}
+	elemType := TypeInfo{Name: "infer"}
+	if (iterType.IsArray || genericBase(iterType) == "Range") && len(iterType.Args) == 1 {
+		elemType = iterType.Args[0]
+	}
+
+	inner := make(map[string]*Symbol, len(scope)+1)
+	for name, sym := range scope {
+		inner[name] = sym
+	}
+	c.define(inner, &Symbol{
+		Kind:    SymbolVariable,
+		Name:    fe.Var,
+		Type:    elemType,
+		Pos:     fe.Pos(),
+		Defined: true,
+		Depth:   c.blockDepth,
+	})
+	c.checkLoopBody("for", fe.Label, fe.Body, inner)
 	return TypeInfo{Name: "()"}
 }
 
+// checkBreakExpr проверяет, что break стоит внутри цикла, а значение передаётся
+// только из loop; значения всех break одного loop должны иметь один тип.
+func (c *Checker) checkBreakExpr(be *ast.BreakExpr, scope map[string]*Symbol) TypeInfo {
+	frame := c.targetLoop("break", be.Label, be.Pos())
+	if be.Value != nil {
+		valueType := c.checkExpr(be.Value, scope)
+		if frame != nil {
+			switch {
+			case frame.kind != "loop":
+				c.errorWithFix(CodeBreakWithValue, fmt.Sprintf("`break` with value from a `%s` loop", frame.kind),
+					"use `loop` to break with a value", be.Pos())
+			case frame.breakType == nil || frame.breakType.Name == "infer":
+				frame.breakType = &valueType
+			case !c.typesCompatible(*frame.breakType, valueType):
+				c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in break: expected %s, got %s", frame.breakType.Name, valueType.Name), be.Value.Pos())
+			}
+		}
+	}
+	return TypeInfo{Name: "infer"}
+}
+
+// targetLoop находит цикл, к которому относится break или continue (keyword):
+// цикл с меткой label или ближайший цикл, если метки нет. Если цикла нет, сообщает
+// об ошибке и возвращает nil.
+func (c *Checker) targetLoop(keyword, label string, pos token.Position) *loopFrame {
+	if len(c.loops) == 0 {
+		c.error(CodeOutsideLoop, fmt.Sprintf("`%s` outside of a loop", keyword), pos)
+		return nil
+	}
+	if label == "" {
+		return c.loops[len(c.loops)-1]
+	}
+	for i := len(c.loops) - 1; i >= 0; i-- {
+		if c.loops[i].label == label {
+			return c.loops[i]
+		}
+	}
+	c.error(CodeUndeclaredLabel, fmt.Sprintf("use of undeclared label `%s`", label), pos)
+	return nil
+}
+
+// checkTupleExpr проверяет кортежное выражение и возвращает кортежный тип.
+func (c *Checker) checkTupleExpr(te *ast.TupleExpr, scope map[string]*Symbol) TypeInfo {
+	elems := make([]TypeInfo, 0, len(te.Elems))
+	for _, elem := range te.Elems {
+		elems = append(elems, c.checkExpr(elem, scope))
+	}
+	return tupleType(elems)
+}
+
+// checkMacroCall проверяет вызов макроса и возвращает тип результата.
+// Остальные макросы принимают произвольные аргументы и возвращают ().
+func (c *Checker) checkMacroCall(name string, ce *ast.CallExpr, scope map[string]*Symbol) TypeInfo {
+	argTypes := make([]TypeInfo, 0, len(ce.Args))
+	for _, arg := range ce.Args {
+		argTypes = append(argTypes, c.checkExpr(arg, scope))
+	}
+
+	switch name {
+	case "vec!":
+		// vec![...] имеет тип Vec<T>, где T — тип элементов литерала массива
+		if len(argTypes) == 1 && isArrayLiteralType(argTypes[0]) && argTypes[0].Args[0].Name != "infer" {
+			elem := argTypes[0].Args[0]
+			return TypeInfo{Name: "Vec<" + elem.Name + ">", IsArray: true, Args: []TypeInfo{elem}}
+		}
+		return TypeInfo{Name: "infer"}
+	case "format!":
+		return TypeInfo{Name: "String"}
+	case "panic!", "todo!", "unimplemented!", "unreachable!":
+		// Макрос не возвращает управление, поэтому совместим с любым ожидаемым типом
+		return TypeInfo{Name: "infer"}
+	case "assert!":
+		if len(argTypes) == 0 {
+			c.error(CodeArgCount, "assert! expects a condition", ce.Pos())
+		} else if !c.typesCompatible(TypeInfo{Name: "bool"}, argTypes[0]) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("assert! condition must be bool, got %s", argTypes[0].Name), ce.Args[0].Pos())
+		}
+	case "assert_eq!", "assert_ne!":
+		if len(argTypes) < 2 {
+			c.error(CodeArgCount, fmt.Sprintf("%s expects 2 arguments, got %d", name, len(argTypes)), ce.Pos())
+		} else if !c.typesCompatible(argTypes[0], argTypes[1]) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("%s: mismatched types %s and %s", name, argTypes[0].Name, argTypes[1].Name), ce.Pos())
+		}
+	}
+	return TypeInfo{Name: "()"}
+}
+
+// elemLiteralsFit сообщает, что элементы литерала массива (или `vec![...]`) —
+// целочисленные литералы, помещающиеся в целый тип элементов typ.
+func (c *Checker) elemLiteralsFit(expr ast.Expr, typ TypeInfo) bool {
+	if !typ.IsArray || len(typ.Args) != 1 || !c.isInteger(typ.Args[0]) {
+		return false
+	}
+	if ce, ok := expr.(*ast.CallExpr); ok {
+		if lit, ok := ce.Func.(*ast.Literal); !ok || lit.Val != "vec!" || len(ce.Args) != 1 {
+			return false
+		}
+		expr = ce.Args[0]
+	}
+	switch e := expr.(type) {
+	case *ast.ArrayRepeatExpr:
+		return c.checkIntLiteral(e.Value, typ.Args[0])
+	case *ast.ArrayExpr:
+		for _, elem := range e.Elems {
+			if !c.checkIntLiteral(elem, typ.Args[0]) {
+				return false
+			}
+		}
+		return len(e.Elems) > 0
+	}
+	return false
+}
+
+// withElem возвращает тип массива или Vec t с типом элементов elem; длина массива сохраняется.
+func withElem(t, elem TypeInfo) TypeInfo {
+	if strings.HasPrefix(t.Name, "Vec<") {
+		return TypeInfo{Name: "Vec<" + elem.Name + ">", IsArray: true, Args: []TypeInfo{elem}}
+	}
+	return arrayType(elem, arrayTypeLen(t))
+}
+
+// checkArrayExpr проверяет литерал массива: все элементы должны иметь тип первого элемента.
+func (c *Checker) checkArrayExpr(ae *ast.ArrayExpr, scope map[string]*Symbol) TypeInfo {
+	elemType := TypeInfo{Name: "infer"}
+	// Ведущие элементы из литералов без суффикса: их тип уточняет первый элемент
+	// с собственным типом, как в `[1, 2u8]`
+	var untyped []ast.Expr
+	for i, elem := range ae.Elems {
+		t := c.checkExpr(elem, scope)
+		leading := len(untyped) == i
+		switch {
+		case i == 0:
+			elemType = t
+		case leading && untypedLiteral(elem) == "":
+			for _, lit := range untyped {
+				elemType = c.unifyLiteral(lit, elemType, t)
+			}
+		default:
+			t = c.unifyLiteral(elem, t, elemType)
+		}
+		if leading && untypedLiteral(elem) != "" {
+			untyped = append(untyped, elem)
+		}
+		if !c.typesCompatible(elemType, t) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in array: expected %s, got %s", elemType.Name, t.Name), elem.Pos())
+		}
+	}
+	return arrayType(elemType, strconv.Itoa(len(ae.Elems)))
+}
+
+// checkArrayRepeatExpr проверяет выражение `[value; len]`; длина должна быть целым числом.
+func (c *Checker) checkArrayRepeatExpr(ar *ast.ArrayRepeatExpr, scope map[string]*Symbol) TypeInfo {
+	elemType := c.checkExpr(ar.Value, scope)
+	if lenType := c.checkExpr(ar.Len, scope); !c.isInteger(lenType) && lenType.Name != "infer" {
+		c.error(CodeMismatchedTypes, fmt.Sprintf("array length must be an integer, got %s", lenType.Name), ar.Len.Pos())
+	}
+	return arrayType(elemType, arrayLen(ar.Len))
+}
+
+// checkStructLit проверяет литерал структуры: структура должна существовать,
+// каждое её поле — быть инициализировано ровно один раз значением подходящего типа.
+func (c *Checker) checkStructLit(sl *ast.StructLit, scope map[string]*Symbol) TypeInfo {
+	sym := c.symbols[sl.Name]
+	if sym == nil || sym.Struct == nil {
+		c.error(CodeUnknownStruct, fmt.Sprintf("cannot find struct `%s`", sl.Name), sl.Pos())
+		for _, field := range sl.Fields {
+			c.checkExpr(field.Value, scope)
+		}
+		return TypeInfo{Name: "infer"}
+	}
+
+	fieldTypes := make(map[string]TypeInfo, len(sym.Struct.Fields))
+	for _, field := range sym.Struct.Fields {
+		fieldTypes[field.Name] = c.extractType(field.Type)
+	}
+	seen := make(map[string]bool, len(sl.Fields))
+	for _, field := range sl.Fields {
+		valueType := c.checkExpr(field.Value, scope)
+		fieldType, ok := fieldTypes[field.Name]
+		switch {
+		case !ok:
+			c.error(CodeUnknownField, fmt.Sprintf("struct `%s` has no field named `%s`", sl.Name, field.Name), field.Value.Pos())
+		case seen[field.Name]:
+			c.error(CodeFieldTwice, fmt.Sprintf("field `%s` specified more than once", field.Name), field.Value.Pos())
+		case !c.typesCompatible(fieldType, valueType):
+			c.error(CodeMismatchedTypes, fmt.Sprintf("field %s of %s: expected %s, got %s", field.Name, sl.Name, fieldType.Name, valueType.Name), field.Value.Pos())
+		}
+		seen[field.Name] = true
+	}
+	for _, field := range sym.Struct.Fields {
+		if !seen[field.Name] {
+			c.error(CodeMissingField, fmt.Sprintf("missing field `%s` in initializer of `%s`", field.Name, sl.Name), sl.Pos())
+		}
+	}
+	return TypeInfo{Name: sl.Name}
+}
+
+// checkMatchExpr проверяет выражение match.
+// Каждая ветвь получает собственную область видимости с переменными, связанными образцом.
+// Тип выражения определяется первой ветвью; остальные ветви должны быть с ним совместимы.
+func (c *Checker) checkMatchExpr(me *ast.MatchExpr, scope map[string]*Symbol) TypeInfo {
+	scrutineeType := c.checkExpr(me.Scrutinee, scope)
+
+	var resultType *TypeInfo
+	for _, arm := range me.Arms {
+		armScope := make(map[string]*Symbol, len(scope))
+		for name, sym := range scope {
+			armScope[name] = sym
+		}
+		c.checkPattern(arm.Pattern, scrutineeType, armScope)
+
+		armType := c.checkExpr(arm.Body, armScope)
+		if resultType == nil {
+			resultType = &armType
+			continue
+		}
+		if !c.typesCompatible(*resultType, armType) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("match arms have incompatible types: expected %s, got %s", resultType.Name, armType.Name), arm.Pos())
+		}
+	}
+
+	c.checkExhaustive(me, scrutineeType)
+
+	if resultType == nil {
+		return TypeInfo{Name: "()"}
+	}
+	return *resultType
+}
+
+// checkPattern проверяет, что образец применим к значению заданного типа,
+// и регистрирует связанные образцом переменные в области видимости.
+func (c *Checker) checkPattern(pat ast.Pattern, typ TypeInfo, scope map[string]*Symbol) {
+	switch p := pat.(type) {
+	case *ast.WildcardPattern:
+		// `_` совпадает с любым значением и ничего не связывает
+	case *ast.IdentPattern:
+		c.define(scope, &Symbol{
+			Kind:    SymbolVariable,
+			Name:    p.Name,
+			Type:    typ,
+			Pos:     p.Pos(),
+			Defined: true,
+			Depth:   c.blockDepth,
+		})
+	case *ast.LiteralPattern:
+		litType := c.checkLiteral(ast.NewLiteral(p.Pos(), p.Kind, p.Val), scope)
+		if !c.typesCompatible(typ, litType) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in pattern: expected %s, got %s", typ.Name, litType.Name), p.Pos())
+		}
+	case *ast.TuplePattern:
+		if typ.Name == "infer" {
+			for _, elem := range p.Elems {
+				c.checkPattern(elem, typ, scope)
+			}
+			return
+		}
+		if typ.Elems == nil {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in pattern: expected %s, got tuple", typ.Name), p.Pos())
+			c.checkPattern(p, TypeInfo{Name: "infer"}, scope)
+			return
+		}
+		if len(p.Elems) != len(typ.Elems) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("tuple pattern has %d elements, expected %d", len(p.Elems), len(typ.Elems)), p.Pos())
+			c.checkPattern(p, TypeInfo{Name: "infer"}, scope)
+			return
+		}
+		for i, elem := range p.Elems {
+			c.checkPattern(elem, typ.Elems[i], scope)
+		}
+	case *ast.VariantPattern:
+		c.checkVariantPattern(p, typ, scope)
+	case *ast.StructPattern:
+		c.checkStructPattern(p, deref(typ), scope)
+	}
+}
+
+// checkStructPattern проверяет образец структуры: структура должна совпадать с типом
+// значения, упомянутые поля — существовать, а без `..` должны быть упомянуты все поля.
+func (c *Checker) checkStructPattern(p *ast.StructPattern, typ TypeInfo, scope map[string]*Symbol) {
+	sym := c.symbols[p.Name]
+	if sym == nil || sym.Struct == nil {
+		c.error(CodeUnknownStruct, fmt.Sprintf("cannot find struct `%s`", p.Name), p.Pos())
+		for _, f := range p.Fields {
+			c.checkPattern(f.Pattern, TypeInfo{Name: "infer"}, scope)
+		}
+		return
+	}
+	if typ.Name != "infer" && typ.Name != p.Name {
+		c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in pattern: expected %s, got %s", typ.Name, p.Name), p.Pos())
+	}
+
+	mentioned := make(map[string]bool, len(p.Fields))
+	for _, f := range p.Fields {
+		fieldType := TypeInfo{Name: "infer"}
+		found := false
+		for _, decl := range sym.Struct.Fields {
+			if decl.Name == f.Name {
+				fieldType, found = c.extractType(decl.Type), true
+				break
+			}
+		}
+		if !found {
+			c.error(CodeNoPatternField, fmt.Sprintf("struct `%s` does not have a field named `%s`", p.Name, f.Name), f.Pattern.Pos())
+		}
+		mentioned[f.Name] = true
+		c.checkPattern(f.Pattern, fieldType, scope)
+	}
+	if p.Rest {
+		return
+	}
+	for _, decl := range sym.Struct.Fields {
+		if !mentioned[decl.Name] {
+			c.errorWithFix(CodeMissingPatternField, fmt.Sprintf("pattern does not mention field `%s`", decl.Name),
+				"add the missing fields or ignore them with `..`", p.Pos())
+		}
+	}
+}
+
+// checkVariantPattern проверяет образец варианта перечисления: вариант должен
+// принадлежать типу сопоставляемого значения, а число образцов полей — совпадать
+// с числом полей кортежного варианта.
+func (c *Checker) checkVariantPattern(p *ast.VariantPattern, typ TypeInfo, scope map[string]*Symbol) {
+	name := strings.Join(p.Segments, "::")
+	en, variant, ok := c.lookupVariant(ast.NewPathExpr(p.Pos(), p.Segments))
+	if !ok {
+		c.error(CodeUnresolvedPath, fmt.Sprintf("unresolved path: %s", name), p.Pos())
+	} else if typ.Name != "infer" && typ.Name != en.Name {
+		c.error(CodeMismatchedTypes, fmt.Sprintf("mismatched types in pattern: expected %s, got %s", typ.Name, en.Name), p.Pos())
+	}
+	if variant != nil && len(p.Elems) != len(variant.Types) {
+		c.error(CodePatternFields, fmt.Sprintf("pattern %s has %d fields, but the variant has %d", name, len(p.Elems), len(variant.Types)), p.Pos())
+		variant = nil
+	}
+	if variant == nil {
+		// Привязки образца всё равно объявляются, чтобы не порождать лишних ошибок в теле ветви
+		for _, elem := range p.Elems {
+			c.checkPattern(elem, TypeInfo{Name: "infer"}, scope)
+		}
+		return
+	}
+	for i, elem := range p.Elems {
+		c.checkPattern(elem, c.extractType(variant.Types[i]), scope)
+	}
+}
+
+// checkExhaustive проверяет, что ветви match покрывают все значения перечисления
+// или bool. Ветвь покрывает вариант, если её образец полей неопровержим; `_` или
+// образец-привязка покрывает всё. Для остальных типов проверка не выполняется.
+func (c *Checker) checkExhaustive(me *ast.MatchExpr, typ TypeInfo) {
+	var all []string
+	switch sym := c.symbols[typ.Name]; {
+	case typ.Name == "bool":
+		all = []string{"true", "false"}
+	case sym != nil && sym.Enum != nil:
+		for _, variant := range sym.Enum.Variants {
+			all = append(all, sym.Enum.Name+"::"+variant.Name)
+		}
+	default:
+		return
+	}
+
+	covered := make(map[string]bool)
+	for _, arm := range me.Arms {
+		switch p := arm.Pattern.(type) {
+		case *ast.WildcardPattern, *ast.IdentPattern:
+			return
+		case *ast.LiteralPattern:
+			if p.Kind == "BOOL" {
+				covered[p.Val] = true
+			}
+		case *ast.VariantPattern:
+			if allIrrefutable(p.Elems) {
+				covered[strings.Join(p.Segments, "::")] = true
+			}
+		}
+	}
+
+	var missing []string
+	for _, name := range all {
+		if !covered[name] {
+			missing = append(missing, name)
+		}
+	}
+	switch len(missing) {
+	case 0:
+	case 1:
+		c.error(CodeNonExhaustive, fmt.Sprintf("non-exhaustive match: missing variant %s", missing[0]), me.Pos())
+	default:
+		c.error(CodeNonExhaustive, fmt.Sprintf("non-exhaustive match: missing variants %s", strings.Join(missing, ", ")), me.Pos())
+	}
+}
+
+// allIrrefutable сообщает, совпадают ли образцы с любыми значениями:
+// это `_`, привязки и кортежи из них.
+func allIrrefutable(pats []ast.Pattern) bool {
+	for _, pat := range pats {
+		switch p := pat.(type) {
+		case *ast.WildcardPattern, *ast.IdentPattern:
+		case *ast.TuplePattern:
+			if !allIrrefutable(p.Elems) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// tupleType строит кортежный тип из типов элементов: (i32, bool).
+func tupleType(elems []TypeInfo) TypeInfo {
+	names := make([]string, 0, len(elems))
+	for _, elem := range elems {
+		names = append(names, elem.Name)
+	}
+	return TypeInfo{Name: "(" + strings.Join(names, ", ") + ")", Elems: elems}
+}
+
+// arrayType строит тип массива `[T; N]`; при пустой длине — тип среза `[T]`.
+func arrayType(elem TypeInfo, length string) TypeInfo {
+	name := "[" + elem.Name + "]"
+	if length != "" {
+		name = "[" + elem.Name + "; " + length + "]"
+	}
+	return TypeInfo{Name: name, IsArray: true, Args: []TypeInfo{elem}}
+}
+
+// arrayLen возвращает длину массива для имени типа: значение литерала или `_`, если длина не константа.
+func arrayLen(length ast.Expr) string {
+	if lit, ok := length.(*ast.Literal); ok && lit.Kind == "INT" {
+		return lit.Val
+	}
+	return "_"
+}
+
+// isArrayLiteralType сообщает, является ли тип массивом `[T; N]` или срезом `[T]` (но не Vec).
+func isArrayLiteralType(t TypeInfo) bool {
+	return t.IsArray && strings.HasPrefix(t.Name, "[") && len(t.Args) == 1
+}
+
+// arrayTypeLen возвращает длину из имени типа массива (пустую строку для среза).
+func arrayTypeLen(t TypeInfo) string {
+	prefix := "[" + t.Args[0].Name + "; "
+	if !strings.HasPrefix(t.Name, prefix) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(t.Name, prefix), "]")
+}
+
 // extractType извлекает информацию о типе из AST типа.
 func (c *Checker) extractType(t ast.Type) TypeInfo {
 	if t == nil {
@@ -437,14 +1939,76 @@ func (c *Checker) extractType(t ast.Type) TypeInfo {
 
 	switch typ := t.(type) {
 	case *ast.PathType:
+		if typ.Path == "Self" && c.selfType != "" {
+			return TypeInfo{Name: c.selfType}
+		}
+		if c.typeParams[typ.Path] {
+			return TypeInfo{Name: typ.Path, IsTypeParam: true}
+		}
 		return TypeInfo{Name: typ.Path}
+	case *ast.RefType:
+		return refType(c.extractType(typ.Elem))
+	case *ast.GenericType:
+		// Имя обобщённого типа собирается из аргументов: Vec<i32>, HashMap<String, i32>
+		args := make([]TypeInfo, 0, len(typ.Args))
+		names := make([]string, 0, len(typ.Args))
+		for _, arg := range typ.Args {
+			argType := c.extractType(arg)
+			args = append(args, argType)
+			names = append(names, argType.Name)
+		}
+		return TypeInfo{Name: typ.Path + "<" + strings.Join(names, ", ") + ">", IsArray: typ.Path == "Vec", Args: args}
+	case *ast.TupleType:
+		elems := make([]TypeInfo, 0, len(typ.Elems))
+		for _, elem := range typ.Elems {
+			elems = append(elems, c.extractType(elem))
+		}
+		return tupleType(elems)
+	case *ast.ArrayType:
+		if typ.Len == nil {
+			return arrayType(c.extractType(typ.Elem), "")
+		}
+		return arrayType(c.extractType(typ.Elem), arrayLen(typ.Len))
 	default:
 		return TypeInfo{Name: "()"}
 	}
 }
 
+// extractFnType извлекает тип из сигнатуры функции fn с учётом её обобщённых параметров.
+func (c *Checker) extractFnType(fn *ast.Function, t ast.Type) TypeInfo {
+	saved := c.typeParams
+	c.typeParams = typeParamSet(fn)
+	defer func() { c.typeParams = saved }()
+	return c.extractType(t)
+}
+
+// typeParamSet возвращает множество имён обобщённых параметров функции.
+func typeParamSet(fn *ast.Function) map[string]bool {
+	set := make(map[string]bool, len(fn.TypeParams))
+	for _, name := range fn.TypeParams {
+		set[name] = true
+	}
+	return set
+}
+
+// refType строит ссылочный тип &T.
+func refType(elem TypeInfo) TypeInfo {
+	return TypeInfo{Name: "&" + elem.Name, IsReference: true, Elem: &elem}
+}
+
+// deref снимает все уровни ссылок с типа: &&T -> T.
+func deref(t TypeInfo) TypeInfo {
+	for t.IsReference && t.Elem != nil {
+		t = *t.Elem
+	}
+	return t
+}
+
 // typesCompatible проверяет совместимость типов.
+// Ссылки пока сравниваются по типу, на который они указывают.
 func (c *Checker) typesCompatible(t1, t2 TypeInfo) bool {
+	t1, t2 = deref(t1), deref(t2)
+
 	// Тип "infer" совместим с любым типом (вывод типа)
 	if t1.Name == "infer" || t2.Name == "infer" {
 		return true
@@ -455,15 +2019,104 @@ func (c *Checker) typesCompatible(t1, t2 TypeInfo) bool {
 		return true
 	}
 
+	// Массив [T; N] приводится к срезу [T], а длина `_` совместима с любой
+	if isArrayLiteralType(t1) && isArrayLiteralType(t2) {
+		if !c.typesCompatible(t1.Args[0], t2.Args[0]) {
+			return false
+		}
+		len1, len2 := arrayTypeLen(t1), arrayTypeLen(t2)
+		return len1 == len2 || len1 == "" || len2 == "" || len1 == "_" || len2 == "_"
+	}
+
 	// В упрощённой реализации считаем, что типы совместимы только если они идентичны
 	return t1.Name == t2.Name
 }
 
+// isInteger проверяет, является ли тип целочисленным.
+func (c *Checker) isInteger(t TypeInfo) bool {
+	switch t.Name {
+	case "i8", "i16", "i32", "i64", "isize", "u8", "u16", "u32", "u64", "usize":
+		return true
+	}
+	return false
+}
+
+// intTypeBits — разрядность целочисленных типов; isize и usize считаются 64-битными.
+var intTypeBits = map[string]int{
+	"i8": 8, "i16": 16, "i32": 32, "i64": 64, "isize": 64,
+	"u8": 8, "u16": 16, "u32": 32, "u64": 64, "usize": 64,
+}
+
+// checkIntLiteral проверяет, что целочисленный литерал expr (возможно, с унарным минусом)
+// помещается в тип typ, и сообщает об ошибке переполнения. Значение разбирается
+// с учётом префиксов 0x, 0o, 0b и разделителей `_`. Литерал с суффиксом проверяется
+// по своему суффиксу. Возвращает false, если expr — не целочисленный литерал без суффикса.
+func (c *Checker) checkIntLiteral(expr ast.Expr, typ TypeInfo) bool {
+	negative := false
+	if ue, ok := expr.(*ast.UnaryExpr); ok && ue.Op == "-" {
+		negative, expr = true, ue.Expr
+	}
+	lit, ok := expr.(*ast.Literal)
+	if !ok || lit.Kind != "INT" {
+		return false
+	}
+	digits, suffix := ast.NumberSuffix(lit.Val)
+	if _, ok := intTypeBits[suffix]; ok {
+		typ = TypeInfo{Name: suffix}
+	} else if suffix != "" {
+		// `1f32` — дробный литерал, диапазон целых к нему не применяется
+		return false
+	}
+
+	bits := intTypeBits[typ.Name]
+	limit := uint64(1)<<(bits-1) - 1 // максимум знакового типа
+	switch {
+	case typ.Name[0] == 'u' && negative:
+		limit = 0
+	case typ.Name[0] == 'u':
+		limit = 1<<(bits-1) - 1 + 1<<(bits-1)
+	case negative:
+		limit++ // |min| знакового типа на единицу больше max
+	}
+
+	digits, base := strings.ReplaceAll(digits, "_", ""), 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x':
+			digits, base = digits[2:], 16
+		case 'o':
+			digits, base = digits[2:], 8
+		case 'b':
+			digits, base = digits[2:], 2
+		}
+	}
+	value, err := strconv.ParseUint(digits, base, 64)
+	if err != nil || value > limit {
+		text := lit.Val
+		if negative {
+			text = "-" + text
+		}
+		c.error(CodeLiteralOutOfRange, fmt.Sprintf("literal out of range for `%s`: %s", typ.Name, text), lit.Pos())
+	}
+	return suffix == ""
+}
+
 // isNumeric проверяет, является ли тип числовым.
 func (c *Checker) isNumeric(t TypeInfo) bool {
 	return t.Name == "i32" || t.Name == "i64" || t.Name == "f32" || t.Name == "f64" || t.Name == "i8" || t.Name == "i16" || t.Name == "u8" || t.Name == "u16" || t.Name == "u32" || t.Name == "u64"
 }
 
+// isString проверяет, является ли тип строковым: String или str.
+func isString(t TypeInfo) bool {
+	return t.Name == "String" || t.Name == "str"
+}
+
+// isStringLiteral проверяет, является ли выражение строковым литералом.
+func isStringLiteral(expr ast.Expr) bool {
+	lit, ok := expr.(*ast.Literal)
+	return ok && lit.Kind == "STRING"
+}
+
 // isBool проверяет, является ли тип булевым.
 func (c *Checker) isBool(t TypeInfo) bool {
 	return t.Name == "bool"
@@ -487,7 +2140,13 @@ func (c *Checker) isLogicalOp(op string) bool {
 	return ops[op]
 }
 
-// error добавляет новую семантическую ошибку.
-func (c *Checker) error(msg string, pos token.Position) {
-	c.errors = append(c.errors, SemanticError{Msg: msg, Pos: pos})
+// error добавляет новую семантическую ошибку с кодом code.
+func (c *Checker) error(code, msg string, pos token.Position) {
+	c.errors = append(c.errors, SemanticError{Msg: msg, Pos: pos, Code: code, Severity: SeverityError, Item: c.item})
+}
+
+// errorWithFix добавляет семантическую ошибку с предлагаемым исправлением fix.
+func (c *Checker) errorWithFix(code, msg, fix string, pos token.Position) {
+	c.error(code, msg, pos)
+	c.errors[len(c.errors)-1].Suggestion = fix
 }
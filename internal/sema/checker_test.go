@@ -1,6 +1,8 @@
 package sema_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/semetekare/rust2go/internal/ast"
@@ -27,6 +29,18 @@ func parseCode(code string, t *testing.T) *ast.Crate {
 	return crate
 }
 
+// supportedOnly отбрасывает диагностики о конструкциях, которые не поддерживает транслятор,
+// чтобы проверять анализ таких конструкций отдельно от ограничений генерации.
+func supportedOnly(errors []sema.SemanticError) []sema.SemanticError {
+	var kept []sema.SemanticError
+	for _, err := range errors {
+		if err.Code != sema.CodeUnsupported {
+			kept = append(kept, err)
+		}
+	}
+	return kept
+}
+
 func TestCheckerFunctionDeclaration(t *testing.T) {
 	code := `
 fn add(a: i32, b: i32) -> i32 {
@@ -136,6 +150,74 @@ fn main() {
 	}
 }
 
+func TestCheckerArithmeticNumericTypes(t *testing.T) {
+	code := `
+fn main() {
+    let x: i32 = 1;
+    let y: f64 = 2.0;
+    let w: i64 = 3;
+    let a = x + x;
+    let b: i64 = w + 1;
+    let c: i64 = (2 + 3) * w - 1;
+    let d: f64 = y * 0.5;
+    let e: bool = w < 10;
+    let f = x + y;
+    let g = w * 1.5;
+    let h = y - 1;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"mismatched types: cannot apply + to i32 and f64",
+		"mismatched types: cannot apply * to i64 and f64",
+		"mismatched types: cannot apply - to f64 and i32",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerCasts(t *testing.T) {
+	code := `
+fn run(s: String) {
+    let x: i32 = 1;
+    let b: u8 = 65;
+    let f: f64 = x as f64;
+    let i: i32 = b as i32;
+    let c: char = b as char;
+    let n: i64 = true as i64 + 'a' as i64;
+    let bad: i32 = s as i32;
+    let flag = x as bool;
+    let ch = x as char;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"non-primitive cast: `String` as `i32`",
+		"non-primitive cast: `i32` as `bool`",
+		"non-primitive cast: `i32` as `char`",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want || errors[i].Code != sema.CodeInvalidCast {
+			t.Errorf("Expected error %q (%s), got %q (%s)", want, sema.CodeInvalidCast, errors[i].Msg, errors[i].Code)
+		}
+	}
+}
+
 func TestCheckerComparisonTypeCheck(t *testing.T) {
 	code := `
 fn main() {
@@ -340,3 +422,1315 @@ fn main() {}
 		}
 	}
 }
+
+func TestCheckerMatchTuplePatterns(t *testing.T) {
+	code := `
+fn classify(point: (i32, i32)) -> i32 {
+    match point {
+        (0, 0) => 0,
+        (x, _) => x,
+    }
+}
+
+fn main() {
+    let c = classify((1, 2));
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	if len(errors) > 0 {
+		t.Errorf("Expected no errors, got %d:\n", len(errors))
+		for _, err := range errors {
+			t.Logf("  %s", err)
+		}
+	}
+}
+
+func TestCheckerMatchTuplePatternArity(t *testing.T) {
+	code := `
+fn classify(point: (i32, i32)) -> i32 {
+    match point {
+        (a, b, c) => a,
+        _ => 0,
+    }
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	if len(errors) == 0 {
+		t.Error("Expected tuple pattern arity error, got none")
+	}
+}
+
+func TestCheckerTupleStructs(t *testing.T) {
+	code := `
+struct Wrapper(i32);
+struct Unit;
+
+fn main() {
+    let w = Wrapper(5);
+    let x: i32 = w.0;
+    let u: Unit = Unit;
+    let p = (1, true);
+    let y: bool = p.1;
+}
+`
+	checker := sema.NewChecker()
+	if errors := checker.Check(parseCode(code, t)); len(errors) > 0 {
+		t.Errorf("Expected no errors, got %v", errors)
+	}
+
+	bad := `
+struct Wrapper(i32);
+
+fn main() {
+    let a = Wrapper(1, 2);
+    let b = Wrapper(true);
+    let c = Wrapper(1).1;
+}
+`
+	checker = sema.NewChecker()
+	errors := checker.Check(parseCode(bad, t))
+	if len(errors) != 3 {
+		t.Errorf("Expected 3 errors, got %v", errors)
+	}
+}
+
+func TestCheckerMatchExhaustiveness(t *testing.T) {
+	tests := []struct {
+		name string
+		arms string
+		want []string
+	}{
+		{"missing variant", "Shape::Circle(r) => 1, Shape::Empty => 0,",
+			[]string{"non-exhaustive match: missing variant Shape::Rect"}},
+		{"all variants", "Shape::Circle(_) => 1, Shape::Rect(w, h) => w + h, Shape::Empty => 0,", nil},
+		{"wildcard", "Shape::Empty => 0, _ => 1,", nil},
+		{"refutable field", "Shape::Circle(0) => 0, Shape::Rect(w, h) => w, Shape::Empty => 0,",
+			[]string{"non-exhaustive match: missing variant Shape::Circle"}},
+		{"several missing", "Shape::Empty => 0,",
+			[]string{"non-exhaustive match: missing variants Shape::Circle, Shape::Rect"}},
+		{"wrong arity", "Shape::Circle(a, b) => a, _ => 0,",
+			[]string{"pattern Shape::Circle has 2 fields, but the variant has 1"}},
+		{"unknown variant", "Shape::Square => 0, _ => 1,",
+			[]string{"no variant named `Square` in enum `Shape`"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := `
+enum Shape { Circle(i32), Rect(i32, i32), Empty }
+
+fn size(s: Shape) -> i32 {
+    match s { ` + tt.arms + ` }
+}
+`
+			errors := supportedOnly(sema.NewChecker().Check(parseCode(code, t)))
+			if len(errors) != len(tt.want) {
+				t.Fatalf("Expected %d errors, got %d: %v", len(tt.want), len(errors), errors)
+			}
+			for i, want := range tt.want {
+				if errors[i].Msg != want {
+					t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckerMatchBoolExhaustiveness(t *testing.T) {
+	code := `
+fn main() {
+    let flag: bool = true;
+    let a: i32 = match flag { true => 1, false => 0 };
+    let b: i32 = match flag { true => 1 };
+    let c: i32 = match flag { false => 0, other => 1 };
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+	if len(errors) != 1 || errors[0].Msg != "non-exhaustive match: missing variant false" || errors[0].Code != sema.CodeNonExhaustive {
+		t.Errorf("Expected one non-exhaustive error for false, got %v", errors)
+	}
+}
+
+func TestCheckerLetDestructuring(t *testing.T) {
+	code := `
+struct Point { x: i32, y: i32 }
+
+fn main() {
+    let pair: (i32, bool) = (1, true);
+    let (n, flag) = pair;
+    let sum: i32 = n + 1;
+    let ok: bool = flag;
+    let p = Point { x: 1, y: 2 };
+    let Point { x, y: py } = p;
+    let total: i32 = x + py;
+    let Point { x: only, .. } = p;
+    let (_, (inner, _)) = (1, (2, 3));
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+	if len(errors) > 0 {
+		t.Errorf("Expected no errors, got %v", errors)
+	}
+}
+
+func TestCheckerLetDestructuringErrors(t *testing.T) {
+	code := `
+struct Point { x: i32, y: i32 }
+
+fn main() {
+    let triple = (1, 2, 3);
+    let (a, b) = triple;
+    let p = Point { x: 1, y: 2 };
+    let Point { x, z } = p;
+    let Point { x: first } = p;
+    let (c, d) = p;
+    let sum = a + b + c + d;
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+	expected := []struct{ code, msg string }{
+		{sema.CodeMismatchedTypes, "tuple pattern has 2 elements, expected 3"},
+		{sema.CodeNoPatternField, "struct `Point` does not have a field named `z`"},
+		{sema.CodeMissingPatternField, "pattern does not mention field `y`"},
+		{sema.CodeMissingPatternField, "pattern does not mention field `y`"},
+		{sema.CodeMismatchedTypes, "mismatched types in pattern: expected Point, got tuple"},
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Code != want.code || errors[i].Msg != want.msg {
+			t.Errorf("Expected error %s %q, got %s %q", want.code, want.msg, errors[i].Code, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerAssignImmutable(t *testing.T) {
+	code := `
+fn main() {
+    let x: i32 = 1;
+    x = 2;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+	if !strings.Contains(errors[0].Msg, "immutable variable `x`") {
+		t.Errorf("Expected immutability error, got %q", errors[0].Msg)
+	}
+}
+
+func TestCheckerAssignMutable(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+fn update(p: Point) {
+    let mut x: i32 = 1;
+    x = 2;
+    let mut q: Point = p;
+    q.x = x;
+    let mut k: i64 = 0;
+    k = 5;
+    k = -(2 + 3) * 4;
+    let mut b: u8 = 0;
+    b = 255;
+    let mut f: f32 = 1.5f32;
+    f = 2.5;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	if len(errors) > 0 {
+		t.Errorf("Expected no errors, got: %v", errors)
+	}
+}
+
+func TestCheckerAssignLiteralOutOfRange(t *testing.T) {
+	code := `
+fn main() {
+    let mut b: u8 = 0;
+    b = 256;
+    let mut k: i64 = 0;
+    k = 1.5;
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+
+	expected := []string{
+		"literal out of range for `u8`: 256",
+		"type mismatch in assignment: expected i64, got f64",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerGenericFunctions(t *testing.T) {
+	code := `
+fn id<T>(x: T) -> T {
+    x
+}
+
+fn pick<T: PartialOrd>(a: T, b: T) -> T {
+    if a > b { a } else { b }
+}
+
+fn first<T>(v: Vec<T>) -> Option<T> {
+    None
+}
+
+fn main() {
+    let a: i32 = id(1);
+    let s: String = id("x");
+    let b: bool = pick(true, false);
+    let f: Option<f64> = first(vec![1.5, 2.5]);
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	if errors := checker.Check(ast); len(errors) > 0 {
+		t.Errorf("Expected no errors, got: %v", errors)
+	}
+}
+
+func TestCheckerGenericFunctionErrors(t *testing.T) {
+	code := `
+fn id<T>(x: T) -> T {
+    x
+}
+
+fn pick<T>(a: T, b: T) -> T {
+    a
+}
+
+fn wrong<T>(x: T) {
+    let y: i32 = x;
+}
+
+fn main() {
+    let a: i32 = id(1);
+    let b: String = id(true);
+    let c = pick(1, "x");
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"type mismatch: expected i32, got T",
+		"type mismatch: expected String, got bool",
+		"argument 2 of pick: expected i32, got String",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerMutableBorrowReceiver(t *testing.T) {
+	code := `
+struct Counter {
+    n: i32,
+}
+
+impl Counter {
+    fn bump(&mut self) {
+        self.n = self.n + 1;
+    }
+
+    fn get(&self) -> i32 {
+        self.n
+    }
+
+    fn peek(&self) -> i32 {
+        self.bump();
+        self.n
+    }
+}
+
+fn reset(c: &mut Counter) {
+    c.bump();
+}
+
+fn main() {
+    let mut a = Counter { n: 0 };
+    a.bump();
+    let x: i32 = a.get();
+    let b = Counter { n: 1 };
+    let y: i32 = b.get();
+    b.bump();
+    let r = &mut a;
+    r.bump();
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"cannot borrow `self` as mutable",
+		"cannot borrow `b` as mutable",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+		if errors[i].Code != sema.CodeBorrowMut {
+			t.Errorf("Expected code %s, got %s", sema.CodeBorrowMut, errors[i].Code)
+		}
+	}
+}
+
+func TestCheckerMutableReferences(t *testing.T) {
+	code := `
+fn inc(x: &mut i32) {
+    *x = *x + 1;
+}
+
+fn read(x: &i32) -> i32 {
+    *x = 0;
+    *x
+}
+
+fn main() {
+    let mut a: i32 = 1;
+    inc(&mut a);
+    let r = &mut a;
+    inc(r);
+    *r = 5;
+    inc(a);
+    let n: i32 = *a;
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+
+	expected := []struct{ code, msg string }{
+		{sema.CodeAssignThroughRef, "cannot assign to `*x`, which is behind a `&` reference"},
+		{sema.CodeMismatchedTypes, "argument 1 of inc: expected &mut i32, got i32"},
+		{sema.CodeDeref, "type `i32` cannot be dereferenced"},
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Code != want.code || !strings.Contains(errors[i].Msg, want.msg) {
+			t.Errorf("Expected %s %q, got %s %q", want.code, want.msg, errors[i].Code, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerMethodCallOnReference(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+impl Point {
+    fn sum(&self) -> i32 {
+        self.x + self.y
+    }
+}
+
+fn total(p: &Point, q: Point) -> i32 {
+    let a: i32 = p.sum();
+    let b: i32 = (&q).sum();
+    let c: bool = p.sum();
+    a + b
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	// Единственная ошибка — несовпадение типа результата метода, значит метод разрешён
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+	if !strings.Contains(errors[0].Msg, "expected bool, got i32") {
+		t.Errorf("Expected method result type mismatch, got %q", errors[0].Msg)
+	}
+}
+
+func TestCheckerMethodResolution(t *testing.T) {
+	code := `
+struct Counter {
+    n: i32,
+}
+
+impl Counter {
+    fn add(&mut self, by: i32, times: i32) -> i32 {
+        self.n + by * times
+    }
+
+    fn reset(&mut self) {
+        self.n = 0;
+    }
+}
+
+enum Light {
+    On,
+    Off,
+}
+
+impl Light {
+    fn is_on(&self) -> bool {
+        true
+    }
+}
+
+fn main() {
+    let mut c = Counter { n: 0 };
+    let total: i32 = c.add(1, 2);
+    c.reset();
+    let l = Light::On;
+    let on: bool = l.is_on();
+    c.add(1);
+    c.add(1, true);
+    c.missing();
+    l.toggle();
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"method add expects 2 arguments, got 1",
+		"argument 2 of add: expected i32, got bool",
+		"no method named `missing` found for `Counter`",
+		"no method named `toggle` found for `Light`",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerUnresolvedPathType(t *testing.T) {
+	code := `
+fn main() {
+    let a: i32 = Missing::make(1);
+    let b: i32 = Missing::VALUE;
+    let c = Missing::make(2) + 1;
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+
+	expected := []string{
+		"unresolved path: Missing::make",
+		"unresolved path: Missing::VALUE",
+		"unresolved path: Missing::make",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want || errors[i].Code != sema.CodeUnresolvedPath {
+			t.Errorf("Expected %s %q, got %s %q", sema.CodeUnresolvedPath, want, errors[i].Code, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerAssociatedFunctions(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+impl Point {
+    fn new(x: i32, y: i32) -> Point {
+        Point { x, y }
+    }
+
+    fn origin() -> Point {
+        Point::new(0, 0)
+    }
+
+    fn sum(&self) -> i32 {
+        self.x + self.y
+    }
+}
+
+fn main() {
+    let p: Point = Point::new(1, 2);
+    let o = Point::origin();
+    let s: i32 = o.sum();
+    Point::new(1);
+    Point::new(1, true);
+    Point::missing();
+    Point::sum(p);
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"function Point::new expects 2 arguments, got 1",
+		"argument 2 of Point::new: expected i32, got bool",
+		"no function or associated item named `missing` found for `Point`",
+		"Point::sum is a method",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerStructLiteral(t *testing.T) {
+	code := `
+struct Point {
+    x: i32,
+    y: i32,
+}
+
+fn main() {
+    let ok = Point { x: 1, y: 2 };
+    let bad = Point { x: 1, z: 3 };
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"struct `Point` has no field named `z`",
+		"missing field `y` in initializer of `Point`",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerArrayLiteral(t *testing.T) {
+	code := `
+fn sum(xs: &[i32]) -> i32 {
+    xs[0]
+}
+
+fn main() {
+    let ok: [i32; 3] = [1, 2, 3];
+    let total = sum(&ok);
+    let bytes: [u8; 2] = [1, 255];
+    let big: Vec<i64> = vec![0; 4];
+    let bad = [1, true];
+    let suffixed: [u8; 3] = [1u8, 2, 255];
+    let trailing: [u8; 3] = [1, 2, 3u8];
+    let floats: [f32; 2] = [1.5, 2.5f32];
+    let mixed = [1u8, 2.5];
+    let wide = [1u8, 256];
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"mismatched types in array: expected i32, got bool",
+		"mismatched types in array: expected u8, got f64",
+		"literal out of range for `u8`: 256",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerMacros(t *testing.T) {
+	code := `
+fn check(n: i32) -> i32 {
+    match n {
+        0 => panic!("zero"),
+        _ => n,
+    }
+}
+
+fn main() {
+    let v: Vec<i32> = vec![1, 2, 3];
+    let s: String = format!("{}", 1);
+    assert!(1);
+    assert_eq!(1, true);
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"assert! condition must be bool",
+		"assert_eq!: mismatched types",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerIfExpr(t *testing.T) {
+	code := `
+fn pick(c: bool) -> i32 {
+    if c { 1 } else { 2 }
+}
+
+fn main() {
+    let a: i32 = if true { 1 } else { "one" };
+    if 1 { }
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"if and else have incompatible types",
+		"mismatched types in if condition",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerEnumVariants(t *testing.T) {
+	code := `
+enum Shape {
+    Empty,
+    Circle(f64),
+    Rect(i32, i32),
+}
+
+fn main() {
+    let a: Shape = Shape::Empty;
+    let b: Shape = Shape::Rect(2, 3);
+    let c = Option::Some(5);
+    let d = Shape::Triangle;
+    let e = Shape::Rect(1);
+    let f = Shape::Circle(true);
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := supportedOnly(checker.Check(ast))
+
+	expected := []string{
+		"no variant named `Triangle` in enum `Shape`",
+		"variant Shape::Rect expects 2 arguments, got 1",
+		"argument 1 of Shape::Circle: expected f64, got bool",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerEnumVariantsWithData(t *testing.T) {
+	code := `
+enum Shape {
+    Empty,
+    Circle(f64),
+    Rect { w: i32, h: i32 },
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+
+	expected := []string{
+		"enum variants with data are not supported: Shape::Circle",
+		"enum variants with data are not supported: Shape::Rect",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want || errors[i].Code != sema.CodeUnsupported {
+			t.Errorf("Expected %s %q, got %s %q", sema.CodeUnsupported, want, errors[i].Code, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerBreakContinue(t *testing.T) {
+	code := `
+fn main() {
+    let mut i = 0;
+    while i < 10 {
+        i = i + 1;
+        if i == 3 { continue; }
+        break;
+    }
+    let n: i32 = loop { break 5; };
+    let v: Vec<i32> = vec![1, 2];
+    for x in v {
+        let y: i32 = x;
+        break 5;
+    }
+    break;
+    continue;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"`break` with value from a `for` loop",
+		"`break` outside of a loop",
+		"`continue` outside of a loop",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerRangeLoops(t *testing.T) {
+	code := `
+fn f(n: i64) {
+    for i in 0..n {
+        let x: i64 = i;
+    }
+    for j in 1..=10 {
+        let y: bool = j;
+    }
+    for k in 0..true {
+    }
+    let r = 0..n;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"type mismatch: expected bool, got i32",
+		"range bounds must be integers, got bool",
+		"ranges are supported only as `for` loop iterators",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerLoopLabels(t *testing.T) {
+	code := `
+fn main() {
+    let n: i32 = 'outer: loop {
+        for x in [1, 2] {
+            if x == 1 { continue 'outer; }
+            break 'outer 5;
+        }
+    };
+    'a: while true {
+        break 'b;
+    }
+    continue 'a;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"use of undeclared label `'b`",
+		"`continue` outside of a loop",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerNestedFunctions(t *testing.T) {
+	code := `
+fn main() {
+    let base: i32 = 10;
+    let a: i32 = helper(1);
+    let b: bool = helper(2);
+    fn helper(n: i32) -> i32 {
+        twice(n) + base
+    }
+    fn twice(m: i32) -> i32 { m * 2 }
+    helper(1, 2);
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"type mismatch: expected bool, got i32",
+		"cannot find value `base` in this scope",
+		"function helper expects 1 arguments, got 2",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerIntLiteralOverflow(t *testing.T) {
+	code := `
+fn main() {
+    let a: u8 = 255;
+    let b: i8 = -128;
+    let c: u16 = 0xffff;
+    let d: u32 = 1_000_000;
+    let e: i64 = 9_223_372_036_854_775_807;
+    let f: u8 = 256;
+    let g: i8 = -129;
+    let h: u8 = 0b1_0000_0000;
+    let i: u8 = -1;
+    let j: i32 = 300u8;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"literal out of range for `u8`: 256",
+		"literal out of range for `i8`: -129",
+		"literal out of range for `u8`: 0b1_0000_0000",
+		"literal out of range for `u8`: -1",
+		"literal out of range for `u8`: 300u8",
+		"type mismatch: expected i32, got u8",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerFloatLiteralDeclarations(t *testing.T) {
+	code := `
+fn main() {
+    let a: f32 = 1.5;
+    let b: f32 = -(0.5 + 1.0) * 2.0;
+    let c: f64 = 2.5;
+    let d: f32 = 1;
+    let e: i32 = 1.5;
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+
+	expected := []string{
+		"type mismatch: expected f32, got i32",
+		"type mismatch: expected i32, got f64",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerLiteralSuffixes(t *testing.T) {
+	code := `
+fn main() {
+    let a: u8 = 5u8;
+    let b: i64 = 1_000i64;
+    let c: f32 = 2.5f32;
+    let d: f32 = 1f32;
+    let e: usize = 7usize;
+    let f: i32 = 255u8 as i32;
+    let g: f64 = 2.5f32;
+    let h: u8 = 256u8;
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+
+	expected := []string{
+		"type mismatch: expected f64, got f32",
+		"literal out of range for `u8`: 256u8",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerUseBeforeDeclaration(t *testing.T) {
+	code := `
+fn main() {
+    let y: i32 = x + 1;
+    let x: i32 = 5;
+    let z = z;
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	if len(errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errors), errors)
+	}
+	if errors[0].Msg != "cannot find value `x` in this scope" {
+		t.Errorf("Expected unresolved x, got %q", errors[0].Msg)
+	}
+	// Ошибка указывает на использование x, а не на его объявление
+	if errors[0].Pos.Line != 3 || errors[0].Pos.Col != 18 {
+		t.Errorf("Expected error at use site 3:18, got %d:%d", errors[0].Pos.Line, errors[0].Pos.Col)
+	}
+	if errors[1].Msg != "cannot find value `z` in this scope" || errors[1].Pos.Line != 5 {
+		t.Errorf("Expected unresolved z at line 5, got %q at %d:%d", errors[1].Msg, errors[1].Pos.Line, errors[1].Pos.Col)
+	}
+}
+
+func TestCheckerShadowingInNestedBlock(t *testing.T) {
+	code := `
+fn f(n: i32) -> i32 {
+    let x: i32 = 1;
+    {
+        let x: bool = true;
+        let n: bool = x;
+    }
+    let x: i32 = 2;
+    let n: i32 = 3;
+    x
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"variable x already declared in this scope",
+		"variable n already declared in this scope",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerBlockExprValue(t *testing.T) {
+	code := `
+fn main() {
+    let x = { let y = 1; y + 1 };
+    let z: i32 = x + 1;
+    let w = y;
+    let s: bool = { 2 };
+    let u: () = { z; };
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	// x получает числовой тип хвоста; y не виден за пределами блока
+	expected := []string{
+		"cannot find value `y` in this scope",
+		"type mismatch: expected bool, got i32",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if !strings.Contains(errors[i].Msg, want) {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerResultBinding(t *testing.T) {
+	code := `
+fn check(n: i32) -> Result<i32, String> {
+    Ok(n)
+}
+
+fn run() -> Result<i32, String> {
+    let v = check(1)?;
+    let w: i32 = check(2).unwrap();
+    let r = check(3);
+    Ok(v + w)
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d: %v", len(errors), errors)
+	}
+	if errors[0].Code != sema.CodeUnsupported || errors[0].Msg != "binding a value of type Result<i32, String> to a variable is not supported" {
+		t.Errorf("Expected unsupported Result binding, got %s %q", errors[0].Code, errors[0].Msg)
+	}
+	if errors[0].Pos.Line != 9 {
+		t.Errorf("Expected error at line 9, got %d", errors[0].Pos.Line)
+	}
+}
+
+func TestCheckerMainFunction(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		binary bool
+		want   []string
+	}{
+		{"missing in binary", "fn helper() {}", true, []string{"`main` function not found in crate"}},
+		{"missing in library", "fn helper() {}", false, nil},
+		{"arguments", "fn main(x: i32) {}", false, []string{"`main` function must take no arguments, got 1"}},
+		{"return type", "fn main() -> i32 { 0 }", true, []string{"`main` function must return () or Result, got i32"}},
+		{"unit", "fn main() {}", true, nil},
+		{"result", "fn main() -> Result<i32, String> { Ok(0) }", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := sema.NewChecker()
+			checker.Binary = tt.binary
+			errors := checker.Check(parseCode(tt.code, t))
+			if len(errors) != len(tt.want) {
+				t.Fatalf("Expected %d errors, got %d: %v", len(tt.want), len(errors), errors)
+			}
+			for i, want := range tt.want {
+				if errors[i].Msg != want {
+					t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckerStringConcatenation(t *testing.T) {
+	code := `
+fn greet(name: String, suffix: &str) -> String {
+    let s: String = name + suffix;
+    s + "!"
+}
+
+fn bad(a: &str, n: i32) {
+    let x = a + "x";
+    let y = n + "x";
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	errors := checker.Check(ast)
+
+	expected := []string{
+		"cannot add strings: expected String + &str",
+		"operands of + must be numeric",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, want := range expected {
+		if errors[i].Msg != want {
+			t.Errorf("Expected error %q, got %q", want, errors[i].Msg)
+		}
+	}
+}
+
+func TestCheckerDiagnosticCodes(t *testing.T) {
+	code := `
+fn main() {
+    let a = 1;
+    a = 2;
+    let b = missing;
+}
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+	if len(errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errors), errors)
+	}
+
+	assign, undefined := errors[0], errors[1]
+	if undefined.Code != "E0425" {
+		t.Errorf("Expected code E0425 for an undefined identifier, got %q", undefined.Code)
+	}
+	if undefined.Severity != sema.SeverityError || undefined.Severity.String() != "error" {
+		t.Errorf("Expected severity error, got %v", undefined.Severity)
+	}
+	if undefined.Suggestion != "" {
+		t.Errorf("Expected no suggestion for an undefined identifier, got %q", undefined.Suggestion)
+	}
+	// Формат Error() не меняется
+	if want := "Semantic error at 5:13: cannot find value `missing` in this scope"; undefined.Error() != want {
+		t.Errorf("Expected %q, got %q", want, undefined.Error())
+	}
+
+	if assign.Code != "E0384" || assign.Suggestion != "consider making this binding mutable: `mut a`" {
+		t.Errorf("Expected E0384 with a mut suggestion, got %q, %q", assign.Code, assign.Suggestion)
+	}
+}
+
+func TestCheckerClosures(t *testing.T) {
+	ok := `
+fn main() {
+    let base: i32 = 10;
+    let add = |x: i32| x + base;
+    let twice = |x| { let y = add(x); y * 2 };
+    let r: i32 = twice(1);
+}
+`
+	if errors := sema.NewChecker().Check(parseCode(ok, t)); len(errors) != 0 {
+		t.Errorf("Expected closures capturing outer variables to type-check, got %v", errors)
+	}
+
+	bad := `
+fn main() {
+    let f = |x: i32| -> bool { x + 1 };
+    let g = |s: String| s;
+    let n = g(1);
+}
+`
+	errors := sema.NewChecker().Check(parseCode(bad, t))
+	if len(errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errors), errors)
+	}
+	if want := "mismatched types in closure body: expected bool, got i32"; errors[0].Msg != want || errors[0].Code != sema.CodeMismatchedTypes {
+		t.Errorf("Expected %q, got %q (%s)", want, errors[0].Msg, errors[0].Code)
+	}
+	if want := "argument 1 of g: expected String, got i32"; errors[1].Msg != want {
+		t.Errorf("Expected %q, got %q", want, errors[1].Msg)
+	}
+}
+
+func TestCheckerDerives(t *testing.T) {
+	code := `
+#[derive(Debug, Clone, PartialEq)]
+#[allow(dead_code)]
+struct Point { x: i32, y: i32 }
+
+#[derive(Default)]
+struct Config { verbose: bool }
+
+#[derive(Bogus)]
+struct Broken { x: i32 }
+
+#[derive(Debug, Hash)]
+enum Color { Red, Green }
+`
+	errors := sema.NewChecker().Check(parseCode(code, t))
+	if len(errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errors), errors)
+	}
+	if want := "cannot derive `Bogus` for `Broken`: unsupported derive"; errors[0].Msg != want || errors[0].Code != sema.CodeUnknownDerive {
+		t.Errorf("Expected %q, got %q (%s)", want, errors[0].Msg, errors[0].Code)
+	}
+	if errors[0].Pos.Line != 10 {
+		t.Errorf("Expected error at struct Broken (line 10), got line %d", errors[0].Pos.Line)
+	}
+	if want := "cannot derive `Hash` for `Color`: unsupported derive"; errors[1].Msg != want {
+		t.Errorf("Expected %q, got %q", want, errors[1].Msg)
+	}
+}
+
+func TestCheckerSymbols(t *testing.T) {
+	code := `
+fn add(a: i32, b: i32) -> i32 {
+    let sum = a + b;
+    sum
+}
+
+fn main() {
+    let total = add(1, 2);
+}
+`
+	ast := parseCode(code, t)
+	checker := sema.NewChecker()
+	if errors := checker.Check(ast); len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %v", errors)
+	}
+
+	got := []string{}
+	for _, sym := range checker.Symbols() {
+		got = append(got, fmt.Sprintf("%d:%d %s %s %s in %q", sym.Pos.Line, sym.Pos.Col, sym.Kind, sym.Name, sym.Type.Name, sym.Scope))
+	}
+	want := []string{
+		`2:1 function add i32 in ""`,
+		`2:8 variable a i32 in "add"`,
+		`2:16 variable b i32 in "add"`,
+		`3:5 variable sum i32 in "add"`,
+		`7:1 function main () in ""`,
+		`8:5 variable total i32 in "main"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected symbols:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,56 @@
+package sema
+
+// Severity — важность диагностического сообщения.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// String возвращает имя важности: "error" или "warning".
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Коды диагностик. Там, где ошибка соответствует ошибке rustc, используется её код
+// (см. https://doc.rust-lang.org/error_codes/); коды R — ограничения транслятора.
+const (
+	CodeMismatchedTypes     = "E0308" // Несовпадение типов
+	CodeUnresolvedName      = "E0425" // Неизвестная переменная или функция
+	CodeUnresolvedPath      = "E0433" // Неразрешённый путь `A::B`
+	CodeUnknownStruct       = "E0422" // Неизвестная структура в литерале
+	CodeDuplicateDefinition = "E0428" // Повторное объявление имени
+	CodeDuplicateMethod     = "E0201" // Повторное объявление метода в impl
+	CodeAssignTwice         = "E0384" // Повторное присваивание неизменяемой переменной
+	CodeBorrowMut           = "E0596" // Изменяемое заимствование неизменяемой переменной
+	CodeAssignThroughRef    = "E0594" // Присваивание через неизменяемую ссылку
+	CodeDeref               = "E0614" // Разыменование значения, не являющегося ссылкой
+	CodeTypeAnnotations     = "E0282" // Тип не выводится, нужна аннотация
+	CodeOutsideLoop         = "E0268" // break или continue вне цикла
+	CodeBreakWithValue      = "E0571" // break со значением не из loop
+	CodeUndeclaredLabel     = "E0426" // Метка break или continue не объявлена
+	CodeBinaryOp            = "E0369" // Бинарный оператор неприменим к операндам
+	CodeUnaryOp             = "E0600" // Унарный оператор неприменим к операнду
+	CodeArgCount            = "E0061" // Неверное число аргументов
+	CodeNotAFunction        = "E0618" // Вызов значения, не являющегося функцией
+	CodeNoItem              = "E0599" // Нет метода или варианта с таким именем
+	CodeNoField             = "E0609" // Нет поля у типа
+	CodeUnknownField        = "E0560" // Неизвестное поле в литерале структуры
+	CodeFieldTwice          = "E0062" // Поле указано в литерале дважды
+	CodeMissingField        = "E0063" // Поле не указано в литерале
+	CodeTryOperator         = "E0277" // `?` неприменим к выражению или функции
+	CodeInvalidCast         = "E0605" // Недопустимое приведение `as`
+	CodeNonExhaustive       = "E0004" // Ветви match покрывают не все значения
+	CodePatternFields       = "E0023" // Неверное число полей в образце варианта
+	CodeNoPatternField      = "E0026" // Образец структуры упоминает несуществующее поле
+	CodeMissingPatternField = "E0027" // Образец структуры без `..` упоминает не все поля
+	CodeMainMissing         = "E0601" // В исполняемом крейте нет функции main
+	CodeMainSignature       = "E0580" // Функция main принимает аргументы или возвращает не () и не Result
+	CodeLiteralOutOfRange   = "R0002" // Литерал вне диапазона типа (lint overflowing_literals)
+	CodeUnsupported         = "R0001" // Конструкция не поддерживается транслятором
+	CodeUnknownDerive       = "R0003" // derive трейта, который транслятор не умеет генерировать
+)
@@ -0,0 +1,146 @@
+package sema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/semetekare/rust2go/internal/ast"
+	"github.com/semetekare/rust2go/internal/token"
+)
+
+// checkCallArgs сверяет типы аргументов вызова функции или метода fn с её параметрами
+// и возвращает тип результата. Обобщённые параметры выводятся из аргументов: первый
+// аргумент, в типе которого встречается T, задаёт T, остальные должны с ним согласовываться.
+// В типе результата T заменяется выведенным типом (infer, если T не выведен).
+func (c *Checker) checkCallArgs(fn *ast.Function, name string, argTypes []TypeInfo, pos token.Position) TypeInfo {
+	subst := map[string]TypeInfo{}
+	for i, argType := range argTypes {
+		paramType := c.extractFnType(fn, fn.Params[i].Type)
+		if !c.unify(paramType, argType, subst) {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("argument %d of %s: expected %s, got %s", i+1, name, substitute(paramType, subst).Name, argType.Name), pos)
+			continue
+		}
+		// Изменяемая ссылка передаётся явно (`f(&mut x)`): в Go параметр — указатель
+		if isMutRef(fn.Params[i].Type) && !argType.IsReference && argType.Name != "infer" {
+			c.error(CodeMismatchedTypes, fmt.Sprintf("argument %d of %s: expected &mut %s, got %s", i+1, name, paramType.Elem.Name, argType.Name), pos)
+		}
+	}
+	return substitute(c.extractFnType(fn, fn.ReturnType), subst)
+}
+
+// genericResult возвращает тип результата fn вне её тела: обобщённые параметры,
+// которые не из чего вывести, становятся infer.
+func (c *Checker) genericResult(fn *ast.Function) TypeInfo {
+	return substitute(c.extractFnType(fn, fn.ReturnType), nil)
+}
+
+// unify сопоставляет тип параметра param с типом аргумента arg, дополняя subst
+// выведенными значениями обобщённых параметров. Ссылки, как и в typesCompatible,
+// сравниваются по типу, на который указывают.
+func (c *Checker) unify(param, arg TypeInfo, subst map[string]TypeInfo) bool {
+	param, arg = deref(param), deref(arg)
+	if !hasTypeParam(param) || arg.Name == "infer" {
+		return c.typesCompatible(param, arg)
+	}
+	if param.IsTypeParam {
+		if bound, ok := subst[param.Name]; ok {
+			return c.typesCompatible(bound, arg)
+		}
+		subst[param.Name] = arg
+		return true
+	}
+
+	switch {
+	case len(param.Elems) > 0:
+		if len(param.Elems) != len(arg.Elems) {
+			return false
+		}
+		for i := range param.Elems {
+			if !c.unify(param.Elems[i], arg.Elems[i], subst) {
+				return false
+			}
+		}
+		return true
+	case isArrayLiteralType(param):
+		return isArrayLiteralType(arg) && c.unify(param.Args[0], arg.Args[0], subst)
+	case len(param.Args) > 0 && !param.IsFunc:
+		if genericBase(param) != genericBase(arg) || len(param.Args) != len(arg.Args) {
+			return false
+		}
+		for i := range param.Args {
+			if !c.unify(param.Args[i], arg.Args[i], subst) {
+				return false
+			}
+		}
+		return true
+	}
+	return c.typesCompatible(param, arg)
+}
+
+// substitute заменяет в типе t обобщённые параметры их значениями из subst.
+// Тип, в котором остался невыведенный параметр, становится infer.
+func substitute(t TypeInfo, subst map[string]TypeInfo) TypeInfo {
+	if !hasTypeParam(t) {
+		return t
+	}
+	if t.IsTypeParam {
+		if bound, ok := subst[t.Name]; ok {
+			return bound
+		}
+		return TypeInfo{Name: "infer"}
+	}
+
+	infer := false
+	sub := func(ts []TypeInfo) []TypeInfo {
+		out := make([]TypeInfo, 0, len(ts))
+		for _, elem := range ts {
+			elem = substitute(elem, subst)
+			infer = infer || elem.Name == "infer"
+			out = append(out, elem)
+		}
+		return out
+	}
+	var result TypeInfo
+	switch {
+	case t.IsReference && t.Elem != nil:
+		result = refType(sub([]TypeInfo{*t.Elem})[0])
+	case len(t.Elems) > 0:
+		result = tupleType(sub(t.Elems))
+	case isArrayLiteralType(t):
+		result = arrayType(sub(t.Args)[0], arrayTypeLen(t))
+	case len(t.Args) > 0 && !t.IsFunc:
+		args := sub(t.Args)
+		names := make([]string, 0, len(args))
+		for _, arg := range args {
+			names = append(names, arg.Name)
+		}
+		result = TypeInfo{Name: genericBase(t) + "<" + strings.Join(names, ", ") + ">", IsArray: t.IsArray, Args: args}
+	default:
+		infer = true
+	}
+	if infer {
+		return TypeInfo{Name: "infer"}
+	}
+	return result
+}
+
+// hasTypeParam сообщает, встречается ли в типе обобщённый параметр.
+func hasTypeParam(t TypeInfo) bool {
+	if t.IsTypeParam || t.Elem != nil && hasTypeParam(*t.Elem) {
+		return true
+	}
+	for _, ts := range [][]TypeInfo{t.Elems, t.Args} {
+		for _, elem := range ts {
+			if hasTypeParam(elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// genericBase возвращает имя обобщённого типа без аргументов: Vec для Vec<i32>.
+func genericBase(t TypeInfo) string {
+	base, _, _ := strings.Cut(t.Name, "<")
+	return base
+}
@@ -0,0 +1,63 @@
+package sema
+
+import (
+	"slices"
+
+	"github.com/semetekare/rust2go/internal/token"
+)
+
+// String возвращает имя категории символа: "variable", "function", "struct" или "enum".
+func (k SymbolKind) String() string {
+	switch k {
+	case SymbolFunction:
+		return "function"
+	case SymbolStruct:
+		return "struct"
+	case SymbolEnum:
+		return "enum"
+	}
+	return "variable"
+}
+
+// SymbolInfo — запись снимка таблицы символов для инструментов (переход к определению,
+// подсказки типов). Области видимости функций после проверки удаляются, поэтому
+// символы записываются в момент объявления.
+type SymbolInfo struct {
+	Name  string
+	Kind  SymbolKind
+	Type  TypeInfo
+	Pos   token.Position // Позиция определения
+	Scope string         // "" для элементов крейта, имя типа для методов, функция (`Type::method`) для локальных
+	Depth int            // Глубина блока внутри функции (0 — тело функции и параметры)
+}
+
+// Symbols возвращает снимок символов, объявленных при проверке: элементы крейта,
+// методы, параметры и локальные переменные. Записи упорядочены по позиции определения.
+func (c *Checker) Symbols() []SymbolInfo {
+	symbols := slices.Clone(c.defs)
+	slices.SortStableFunc(symbols, func(a, b SymbolInfo) int {
+		if a.Pos.Line != b.Pos.Line {
+			return a.Pos.Line - b.Pos.Line
+		}
+		return a.Pos.Col - b.Pos.Col
+	})
+	return symbols
+}
+
+// define добавляет символ в область видимости scope и записывает его в снимок Symbols.
+func (c *Checker) define(scope map[string]*Symbol, sym *Symbol) {
+	scope[sym.Name] = sym
+	c.record(sym, c.currentFunction)
+}
+
+// record записывает символ в снимок Symbols с областью видимости scope.
+func (c *Checker) record(sym *Symbol, scope string) {
+	c.defs = append(c.defs, SymbolInfo{
+		Name:  sym.Name,
+		Kind:  sym.Kind,
+		Type:  sym.Type,
+		Pos:   sym.Pos,
+		Scope: scope,
+		Depth: sym.Depth,
+	})
+}
@@ -30,7 +30,8 @@ const (
 	KEYWORD
 
 	// TYPE — литерал типа или имя типа.
-	// Примеры: i32, String, Vec<T>. Подтип уточняется в поле Subtype.
+	// Лексер этот тип не выдаёт: имена типов (i32, String) — это IDENT,
+	// а литералы имеют собственные типы INT, FLOAT, STRING и CHAR.
 	TYPE
 
 	// INT — целочисленный литерал.
@@ -81,7 +82,7 @@ type Position struct {
 // Token представляет один лексический токен, полученный в результате анализа исходного кода.
 type Token struct {
 	Type    TokenType // Основной тип токена (см. константы выше).
-	Subtype string    // Дополнительная информация о типе (например, "INT", "FLOAT" для TYPE).
+	Subtype string    // Уточнение типа: "MACRO" для IDENT встроенного макроса, "OUTER"/"INNER" для ATTRIBUTE.
 	Literal string    // Исходный текст токена, как он встречается в коде.
 	Line    int       // Номер строки, в которой находится токен (1-based).
 	Col     int       // Номер колонки начала токена (1-based).
@@ -92,6 +93,13 @@ func (t Token) Pos() Position {
 	return Position{Line: t.Line, Col: t.Col}
 }
 
+// IsWildcard сообщает, является ли токен образцом `_`. Лексер не выделяет `_`
+// в отдельный тип: одиночное подчёркивание — это IDENT("_"), а `_x`, `__` и `_1` —
+// обычные идентификаторы, поэтому парсер различает их по этому методу.
+func (t Token) IsWildcard() bool {
+	return t.Type == IDENT && t.Literal == "_"
+}
+
 // String возвращает человекочитаемое строковое представление токена,
 // включая его тип и, при необходимости, подтип.
 // Используется в основном для отладки и диагностических сообщений.
@@ -1,35 +1,31 @@
 package main
 
-import (
-	"fmt"
-	// Add more imports as needed
-)
+import "fmt"
 
 func main() {
 	fmt.Println("=== Начало программы ===")
-	result := add_numbers(5, 3)
-	fmt.Println("Результат сложения: {}", result)
-	greet_user("Алексей")
-	fmt.Println(hello_user("Данил"))
+	result := addNumbers(5, 3)
+	fmt.Printf("Результат сложения: %v\n", result)
+	greetUser("Алексей")
+	fmt.Println(helloUser("Данил"))
 	number := 7
-	is_even_result := is_even(number)
-	fmt.Println("Число {} чётное: {}", number, is_even_result)
+	is_even_result := isEven(number)
+	fmt.Printf("Число %v чётное: %v\n", number, is_even_result)
 	fmt.Println("=== Конец программы ===")
 }
 
-func add_numbers(a int, b int) int {
-	return (a + b)
+func addNumbers(a int, b int) int {
+	return a + b
 }
 
-func greet_user(name string) {
-	fmt.Println("Привет, {}! Добро пожаловать в Rust!", name)
+func greetUser(name string) {
+	fmt.Printf("Привет, %v! Добро пожаловать в Rust!\n", name)
 }
 
-func hello_user(name string) string {
-	return fmt.Sprintf("Привет {}!", name)
+func helloUser(name string) string {
+	return fmt.Sprintf("Привет %v!", name)
 }
 
-func is_even(num int) bool {
-	return ((num % 2) == 0)
+func isEven(num int) bool {
+	return num%2 == 0
 }
-